@@ -223,7 +223,7 @@ func main() {
 
 	consumer := signalwire.NewConsumer()
 	// set custom host
-	signalwire.GlobalOverwriteHost = Host
+	consumer.Host = Host
 	// setup the Client
 	consumer.Setup(PProjectID, PTokenID, Contexts)
 	// register callback
@@ -90,7 +90,7 @@ func main() {
 
 	Contexts = append(Contexts, PContext)
 	consumer := new(signalwire.Consumer)
-	signalwire.GlobalOverwriteHost = Host
+	consumer.Host = Host
 	// setup the Client
 	consumer.Setup(PProjectID, PTokenID, Contexts)
 	// register callback
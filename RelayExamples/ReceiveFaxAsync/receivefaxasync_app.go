@@ -124,7 +124,7 @@ func main() {
 
 	consumer := new(signalwire.Consumer)
 
-	signalwire.GlobalOverwriteHost = Host
+	consumer.Host = Host
 	// setup the Client
 	consumer.Setup(PProjectID, PTokenID, Contexts)
 	// register callback
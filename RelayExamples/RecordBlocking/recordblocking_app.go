@@ -189,7 +189,7 @@ func main() {
 
 	consumer := new(signalwire.Consumer)
 
-	signalwire.GlobalOverwriteHost = Host
+	consumer.Host = Host
 	// setup the Client
 	consumer.Setup(PProjectID, PTokenID, Contexts)
 	// register callback
@@ -220,7 +220,7 @@ func main() {
 
 	consumer := new(signalwire.Consumer)
 	// setup the Client
-	signalwire.GlobalOverwriteHost = Host
+	consumer.Host = Host
 
 	consumer.Setup(PProjectID, PTokenID, Contexts)
 	// register callback
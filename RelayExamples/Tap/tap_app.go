@@ -545,7 +545,7 @@ func main() {
 	if !justlisten {
 		consumer := new(signalwire.Consumer)
 
-		signalwire.GlobalOverwriteHost = Host
+		consumer.Host = Host
 		// setup the Client
 		consumer.Setup(PProjectID, PTokenID, Contexts)
 		// register callback
@@ -189,7 +189,7 @@ func main() {
 	signalwire.Log.Info("dialing...\n")
 
 	for j := 1; j <= testCalls; j++ {
-		call := &DemoSession.Calls[j]
+		call := new(signalwire.CallSession)
 
 		var Relay signalwire.RelaySession
 
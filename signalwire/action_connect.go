@@ -37,11 +37,18 @@ type ConnectAction struct {
 	State     CallConnectState
 	Payload   *json.RawMessage
 	err       error
+	events    eventStream
 	sync.RWMutex
 }
 
 // Connect TODO DESCRIPTION
 func (callobj *CallObj) Connect(ringback *[]RingbackStruct, devices *[][]DeviceStruct) (*ConnectResult, error) {
+	return callobj.ConnectContext(callobj.ctx(), ringback, devices)
+}
+
+// ConnectContext is Connect, it returns ctx.Err() when ctx is done before the connect
+// completed. There is no command to stop a connect: the peer legs are left to Relay.
+func (callobj *CallObj) ConnectContext(ctx context.Context, ringback *[]RingbackStruct, devices *[][]DeviceStruct) (*ConnectResult, error) {
 	a := new(ConnectAction)
 	res := &a.Result
 
@@ -55,13 +62,17 @@ func (callobj *CallObj) Connect(ringback *[]RingbackStruct, devices *[][]DeviceS
 		return res, errors.New("nil Relay object")
 	}
 
-	if err := callobj.Calling.Relay.RelayConnect(callobj.Calling.Ctx, callobj.call, ringback, devices, nil); err != nil {
+	if err := callobj.Calling.Relay.RelayConnect(ctx, callobj.call, ringback, devices, nil); err != nil {
 		return res, err
 	}
 
-	callobj.callbacksRunConnect(callobj.Calling.Ctx, a, true)
+	callobj.callbacksRunConnect(ctx, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), nil); err != nil {
+		return res, err
+	}
 
-	return res, nil
+	return res, a.err
 }
 
 // ConnectAsync TODO DESCRIPTION
@@ -95,6 +106,50 @@ func (callobj *CallObj) ConnectAsync(fromNumber, toNumber string) (*ConnectActio
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
+		}
+		done <- struct{}{}
+	}()
+
+	<-done
+
+	return res, res.err
+}
+
+// ConnectDevicesAsync is ConnectAsync to devices (phone or SIP), with ringback, as Connect
+func (callobj *CallObj) ConnectDevicesAsync(ringback *[]RingbackStruct, devices *[][]DeviceStruct) (*ConnectAction, error) {
+	res := new(ConnectAction)
+
+	if callobj.Calling == nil {
+		return res, errors.New("nil Calling object")
+	}
+
+	if callobj.Calling.Relay == nil {
+		return res, errors.New("nil Relay object")
+	}
+
+	res.Result.CallObj = callobj
+
+	done := make(chan struct{}, 1)
+
+	go func() {
+		go func() {
+			callobj.callbacksRunConnect(callobj.Calling.Ctx, res, false)
+		}()
+
+		err := callobj.Calling.Relay.RelayConnect(callobj.Calling.Ctx, callobj.call, ringback, devices, &res.Payload)
+
+		if err != nil {
+			res.Lock()
+
+			res.err = err
+
+			res.Completed = true
+
+			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -106,11 +161,21 @@ func (callobj *CallObj) ConnectAsync(fromNumber, toNumber string) (*ConnectActio
 
 // callbacksRunConnect TODO DESCRIPTION
 func (callobj *CallObj) callbacksRunConnect(ctx context.Context, res *ConnectAction, norunCB bool) {
+	defer callobj.trackAction("connect", "")()
+
+	if !norunCB {
+		defer callobj.call.callbacks.listen(callobj.call.CallConnectStateChan)()
+	}
+
 	var out bool
 
+	var cb *callbackSlot
+
 	for {
 		select {
 		case connectstate := <-callobj.call.CallConnectStateChan:
+			cb = callobj.call.callbacks.take(callobj.call.CallConnectStateChan)
+
 			res.RLock()
 
 			prevstate := res.State
@@ -129,8 +194,8 @@ func (callobj *CallObj) callbacksRunConnect(ctx context.Context, res *ConnectAct
 
 				out = true
 
-				if callobj.OnConnectDisconnected != nil && !norunCB {
-					callobj.OnConnectDisconnected(res)
+				if !norunCB {
+					callobj.emit(cb, EventConnectDisconnected, res)
 				}
 
 			case CallConnectConnecting:
@@ -140,8 +205,8 @@ func (callobj *CallObj) callbacksRunConnect(ctx context.Context, res *ConnectAct
 
 				res.Unlock()
 
-				if callobj.OnConnectConnecting != nil && !norunCB {
-					callobj.OnConnectConnecting(res)
+				if !norunCB {
+					callobj.emit(cb, EventConnectConnecting, res)
 				}
 			case CallConnectFailed:
 				res.Lock()
@@ -153,8 +218,8 @@ func (callobj *CallObj) callbacksRunConnect(ctx context.Context, res *ConnectAct
 
 				out = true
 
-				if callobj.OnConnectFailed != nil && !norunCB {
-					callobj.OnConnectFailed(res)
+				if !norunCB {
+					callobj.emit(cb, EventConnectFailed, res)
 				}
 			case CallConnectConnected:
 				res.Lock()
@@ -163,27 +228,50 @@ func (callobj *CallObj) callbacksRunConnect(ctx context.Context, res *ConnectAct
 
 				res.Unlock()
 
-				if callobj.OnConnectConnected != nil && !norunCB {
-					callobj.OnConnectConnected(res)
+				if !norunCB {
+					callobj.emit(cb, EventConnectConnected, res)
 				}
 			default:
 				out = true
 			}
 
-			if prevstate != connectstate && callobj.OnConnectStateChange != nil && !norunCB {
-				callobj.OnConnectStateChange(res)
+			if prevstate != connectstate && !norunCB {
+				callobj.emit(cb, EventConnectStateChange, res)
 			}
 		case rawEvent := <-callobj.call.CallConnectRawEventChan:
 			res.Lock()
 			res.Result.Event = *rawEvent
 			res.Unlock()
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallConnectStateChan) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+		cb = nil
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			break
 		}
 	}
@@ -200,6 +288,28 @@ func GetEvent(action *ConnectAction) *json.RawMessage {
 	return ret
 }
 
+// GetCompleted TODO DESCRIPTION
+func (action *ConnectAction) GetCompleted() bool {
+	action.RLock()
+
+	ret := action.Completed
+
+	action.RUnlock()
+
+	return ret
+}
+
+// GetError returns the error the action ended with, ErrCallEnded if stopped by the end of the call
+func (action *ConnectAction) GetError() error {
+	action.RLock()
+
+	ret := action.err
+
+	action.RUnlock()
+
+	return ret
+}
+
 // GetPayload TODO DESCRIPTION
 func (action *ConnectAction) GetPayload() *json.RawMessage {
 	action.RLock()
@@ -74,6 +74,7 @@ type DetectAction struct {
 	Payload      *json.RawMessage
 	err          error
 	done         chan bool
+	events       eventStream
 	sync.RWMutex
 	waitForBeep bool
 	Completed   bool
@@ -151,6 +152,11 @@ func (callobj *CallObj) AMD(det *DetectMachineParams) (*DetectResult, error) {
 	return callobj.DetectMachine(det)
 }
 
+// AMDContext is AMD, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) AMDContext(ctx context.Context, det *DetectMachineParams) (*DetectResult, error) {
+	return callobj.DetectMachineContext(ctx, det)
+}
+
 // AMDAsync TODO DESCRIPTION
 func (callobj *CallObj) AMDAsync(det *DetectMachineParams) (*DetectAction, error) {
 	return callobj.DetectMachineAsync(det)
@@ -158,6 +164,11 @@ func (callobj *CallObj) AMDAsync(det *DetectMachineParams) (*DetectAction, error
 
 // DetectMachine TODO DESCRIPTION
 func (callobj *CallObj) DetectMachine(det *DetectMachineParams) (*DetectResult, error) {
+	return callobj.DetectMachineContext(callobj.ctx(), det)
+}
+
+// DetectMachineContext is DetectMachine, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) DetectMachineContext(ctx context.Context, det *DetectMachineParams) (*DetectResult, error) {
 	a := new(DetectAction)
 
 	if callobj.Calling == nil {
@@ -176,20 +187,31 @@ func (callobj *CallObj) DetectMachine(det *DetectMachineParams) (*DetectResult,
 	detInternal.MachineVoiceThreshold = det.MachineVoiceThreshold
 	detInternal.MachineWordsThreshold = det.MachineWordsThreshold
 
-	err := callobj.Calling.Relay.RelayDetectMachine(callobj.Calling.Ctx, callobj.call, ctrlID, &detInternal, det.Timeout, nil)
+	err := callobj.Calling.Relay.RelayDetectMachine(ctx, callobj.call, ctrlID, &detInternal, det.Timeout, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
 	a.waitForBeep = det.WaitForBeep
-	callobj.callbacksRunDetectMachine(callobj.Calling.Ctx, ctrlID, a)
+	callobj.callbacksRunDetectMachine(ctx, ctrlID, a)
 
-	return &a.Result, nil
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayDetectStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
+
+	return &a.Result, a.err
 }
 
 // DetectFax TODO DESCRIPTION
 func (callobj *CallObj) DetectFax(det *DetectFaxParams) (*DetectResult, error) {
+	return callobj.DetectFaxContext(callobj.ctx(), det)
+}
+
+// DetectFaxContext is DetectFax, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) DetectFaxContext(ctx context.Context, det *DetectFaxParams) (*DetectResult, error) {
 	a := new(DetectAction)
 
 	if callobj.Calling == nil {
@@ -202,19 +224,30 @@ func (callobj *CallObj) DetectFax(det *DetectFaxParams) (*DetectResult, error) {
 
 	ctrlID, _ := GenUUIDv4()
 
-	err := callobj.Calling.Relay.RelayDetectFax(callobj.Calling.Ctx, callobj.call, ctrlID, det.Tone, det.Timeout, nil)
+	err := callobj.Calling.Relay.RelayDetectFax(ctx, callobj.call, ctrlID, det.Tone, det.Timeout, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunDetectFax(callobj.Calling.Ctx, ctrlID, a)
+	callobj.callbacksRunDetectFax(ctx, ctrlID, a)
 
-	return &a.Result, nil
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayDetectStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
+
+	return &a.Result, a.err
 }
 
 // DetectDigit TODO DESCRIPTION
 func (callobj *CallObj) DetectDigit(det *DetectDigitParams) (*DetectResult, error) {
+	return callobj.DetectDigitContext(callobj.ctx(), det)
+}
+
+// DetectDigitContext is DetectDigit, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) DetectDigitContext(ctx context.Context, det *DetectDigitParams) (*DetectResult, error) {
 	a := new(DetectAction)
 
 	if callobj.Calling == nil {
@@ -227,15 +260,21 @@ func (callobj *CallObj) DetectDigit(det *DetectDigitParams) (*DetectResult, erro
 
 	ctrlID, _ := GenUUIDv4()
 
-	err := callobj.Calling.Relay.RelayDetectDigit(callobj.Calling.Ctx, callobj.call, ctrlID, det.Digits, det.Timeout, nil)
+	err := callobj.Calling.Relay.RelayDetectDigit(ctx, callobj.call, ctrlID, det.Digits, det.Timeout, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunDetectDigit(callobj.Calling.Ctx, ctrlID, a)
+	callobj.callbacksRunDetectDigit(ctx, ctrlID, a)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayDetectStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // DetectStop TODO DESCRIPTION
@@ -253,12 +292,20 @@ func (callobj *CallObj) DetectStop(ctrlID *string) error {
 
 // callbacksRunDetectMachine TODO DESCRIPTION
 func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID string, res *DetectAction) {
+	defer callobj.trackAction("detect", ctrlID)()
+
+	defer callobj.call.callbacks.listen(callobj.call.CallDetectMachineChans[ctrlID])()
+
 	for {
 		var out bool
 
+		var cb *callbackSlot
+
 		select {
 		// get detect events
 		case detectevent := <-callobj.call.CallDetectMachineChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallDetectMachineChans[ctrlID])
+
 			if detectevent == DetectMachineFinished {
 				out = true
 			}
@@ -269,7 +316,7 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 
 			res.RUnlock()
 
-			Log.Debug("Got detectevent %s. ctrlID: %s\n", detectevent.String(), ctrlID)
+			callobj.logger().Debug("Got detectevent %s. ctrlID: %s\n", detectevent.String(), ctrlID)
 
 			switch detectevent {
 			case DetectMachineFinished:
@@ -280,11 +327,9 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 
 				res.Unlock()
 
-				Log.Debug("Detect finished. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Detect finished. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnDetectFinished != nil {
-					callobj.OnDetectFinished(res)
-				}
+				callobj.emit(cb, EventDetectFinished, res)
 			case DetectMachineMachine:
 				if !res.waitForBeep {
 					res.Lock()
@@ -302,9 +347,7 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 
 					out = true
 
-					if callobj.OnDetectFinished != nil {
-						callobj.OnDetectFinished(res)
-					}
+					callobj.emit(cb, EventDetectFinished, res)
 				}
 
 			case DetectMachineHuman:
@@ -323,9 +366,7 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 
 				out = true
 
-				if callobj.OnDetectFinished != nil {
-					callobj.OnDetectFinished(res)
-				}
+				callobj.emit(cb, EventDetectFinished, res)
 
 			case DetectMachineUnknown:
 				res.Lock()
@@ -343,9 +384,7 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 
 				out = true
 
-				if callobj.OnDetectFinished != nil {
-					callobj.OnDetectFinished(res)
-				}
+				callobj.emit(cb, EventDetectFinished, res)
 
 			case DetectMachineReady:
 				if res.waitForBeep {
@@ -364,9 +403,7 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 
 					out = true
 
-					if callobj.OnDetectFinished != nil {
-						callobj.OnDetectFinished(res)
-					}
+					callobj.emit(cb, EventDetectFinished, res)
 				}
 
 			case DetectMachineNotReady:
@@ -378,20 +415,42 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 				res.Unlock()
 			}
 
-			if prevevent != detectevent && callobj.OnDetectUpdate != nil {
-				callobj.OnDetectUpdate(res)
+			if prevevent != detectevent {
+				callobj.emit(cb, EventDetectUpdate, res)
 			}
 		case rawEvent := <-callobj.call.CallDetectRawEventChans[ctrlID]:
 			res.Lock()
 			res.Result.Event = *rawEvent
 			res.Unlock()
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallDetectMachineChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			res.done <- res.Result.Successful
 			break
 		}
@@ -400,12 +459,20 @@ func (callobj *CallObj) callbacksRunDetectMachine(ctx context.Context, ctrlID st
 
 // callbacksRunDetectFax TODO DESCRIPTION
 func (callobj *CallObj) callbacksRunDetectFax(ctx context.Context, ctrlID string, res *DetectAction) {
+	defer callobj.trackAction("detect", ctrlID)()
+
+	defer callobj.call.callbacks.listen(callobj.call.CallDetectFaxChans[ctrlID])()
+
 	for {
 		var out bool
 
+		var cb *callbackSlot
+
 		select {
 		// get detect events
 		case detectevent := <-callobj.call.CallDetectFaxChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallDetectFaxChans[ctrlID])
+
 			if detectevent == DetectFaxFinished {
 				out = true
 			}
@@ -416,7 +483,7 @@ func (callobj *CallObj) callbacksRunDetectFax(ctx context.Context, ctrlID string
 
 			res.RUnlock()
 
-			Log.Debug("Got detectevent %s. ctrlID: %s\n", detectevent.String(), ctrlID)
+			callobj.logger().Debug("Got detectevent %s. ctrlID: %s\n", detectevent.String(), ctrlID)
 
 			switch detectevent {
 			case DetectFaxFinished:
@@ -427,11 +494,9 @@ func (callobj *CallObj) callbacksRunDetectFax(ctx context.Context, ctrlID string
 
 				res.Unlock()
 
-				Log.Debug("Detect finished. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Detect finished. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnDetectFinished != nil {
-					callobj.OnDetectFinished(res)
-				}
+				callobj.emit(cb, EventDetectFinished, res)
 			case DetectFaxCED:
 				fallthrough
 			case DetectFaxCNG:
@@ -444,20 +509,42 @@ func (callobj *CallObj) callbacksRunDetectFax(ctx context.Context, ctrlID string
 				res.Unlock()
 			}
 
-			if prevevent != detectevent && callobj.OnDetectUpdate != nil {
-				callobj.OnDetectUpdate(res)
+			if prevevent != detectevent {
+				callobj.emit(cb, EventDetectUpdate, res)
 			}
 		case rawEvent := <-callobj.call.CallDetectRawEventChans[ctrlID]:
 			res.Lock()
 			res.Result.Event = *rawEvent
 			res.Unlock()
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallDetectFaxChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			res.done <- res.Result.Successful
 			break
 		}
@@ -466,12 +553,20 @@ func (callobj *CallObj) callbacksRunDetectFax(ctx context.Context, ctrlID string
 
 // callbacksRunDetectDigit TODO DESCRIPTION
 func (callobj *CallObj) callbacksRunDetectDigit(ctx context.Context, ctrlID string, res *DetectAction) {
+	defer callobj.trackAction("detect", ctrlID)()
+
+	defer callobj.call.callbacks.listen(callobj.call.CallDetectDigitChans[ctrlID])()
+
 	for {
 		var out bool
 
+		var cb *callbackSlot
+
 		select {
 		// get detect events
 		case detectevent := <-callobj.call.CallDetectDigitChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallDetectDigitChans[ctrlID])
+
 			if detectevent == DetectDigitFinished {
 				out = true
 			}
@@ -482,7 +577,7 @@ func (callobj *CallObj) callbacksRunDetectDigit(ctx context.Context, ctrlID stri
 
 			res.RUnlock()
 
-			Log.Debug("Got detectevent %s. ctrlID: %s\n", detectevent.String(), ctrlID)
+			callobj.logger().Debug("Got detectevent %s. ctrlID: %s\n", detectevent.String(), ctrlID)
 
 			switch detectevent {
 			case DetectDigitFinished:
@@ -493,11 +588,9 @@ func (callobj *CallObj) callbacksRunDetectDigit(ctx context.Context, ctrlID stri
 
 				res.Unlock()
 
-				Log.Debug("Detect finished. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Detect finished. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnDetectFinished != nil {
-					callobj.OnDetectFinished(res)
-				}
+				callobj.emit(cb, EventDetectFinished, res)
 			case DetectDigitZero:
 				fallthrough
 			case DetectDigitOne:
@@ -530,20 +623,42 @@ func (callobj *CallObj) callbacksRunDetectDigit(ctx context.Context, ctrlID stri
 				res.Unlock()
 			}
 
-			if prevevent != detectevent && callobj.OnDetectUpdate != nil {
-				callobj.OnDetectUpdate(res)
+			if prevevent != detectevent {
+				callobj.emit(cb, EventDetectUpdate, res)
 			}
 		case rawEvent := <-callobj.call.CallDetectRawEventChans[ctrlID]:
 			res.Lock()
 			res.Result.Event = *rawEvent
 			res.Unlock()
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallDetectDigitChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			res.done <- res.Result.Successful
 			break
 		}
@@ -595,6 +710,8 @@ func (callobj *CallObj) DetectMachineAsync(det *DetectMachineParams) (*DetectAct
 			res.err = err
 			res.Completed = true
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -644,6 +761,8 @@ func (callobj *CallObj) DetectDigitAsync(det *DetectDigitParams) (*DetectAction,
 			res.err = err
 			res.Completed = true
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -693,6 +812,8 @@ func (callobj *CallObj) DetectFaxAsync(det *DetectFaxParams) (*DetectAction, err
 			res.err = err
 			res.Completed = true
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -722,7 +843,7 @@ func detectInternalStop(v interface{}) error {
 
 		if len(m.ControlID) == 0 {
 			m.RUnlock()
-			Log.Error("no controlID\n")
+			m.CallObj.logger().Error("no controlID\n")
 
 			return errors.New("no controlID")
 		}
@@ -768,6 +889,17 @@ func (detectaction *DetectAction) GetCompleted() bool {
 	return ret
 }
 
+// GetError returns the error the action ended with, ErrCallEnded if stopped by the end of the call
+func (detectaction *DetectAction) GetError() error {
+	detectaction.RLock()
+
+	ret := detectaction.err
+
+	detectaction.RUnlock()
+
+	return ret
+}
+
 // GetResult TODO DESCRIPTION
 func (detectaction *DetectAction) GetResult() DetectResult {
 	detectaction.RLock()
@@ -791,17 +923,17 @@ func (detectaction *DetectAction) GetDetectorEvent() interface{} {
 	case MachineDetector:
 		ret, ok = detectaction.detEvent.(DetectMachineEvent)
 		if !ok {
-			Log.Error("type assertion failed")
+			detectaction.logger().Error("type assertion failed")
 		}
 	case FaxDetector:
 		ret, ok = detectaction.detEvent.(DetectFaxEvent)
 		if !ok {
-			Log.Error("type assertion failed")
+			detectaction.logger().Error("type assertion failed")
 		}
 	case DigitDetector:
 		ret, ok = detectaction.detEvent.(DetectDigitEvent)
 		if !ok {
-			Log.Error("type assertion failed")
+			detectaction.logger().Error("type assertion failed")
 		}
 	}
 
@@ -55,6 +55,7 @@ type FaxAction struct {
 	eventType FaxEventType
 	err       error
 	done      chan bool
+	events    eventStream
 	sync.RWMutex
 }
 
@@ -80,6 +81,11 @@ type FaxParamsInternal struct {
 
 // ReceiveFax TODO DESCRIPTION
 func (callobj *CallObj) ReceiveFax() (*FaxResult, error) {
+	return callobj.ReceiveFaxContext(callobj.ctx())
+}
+
+// ReceiveFaxContext is ReceiveFax, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) ReceiveFaxContext(ctx context.Context) (*FaxResult, error) {
 	a := new(FaxAction)
 
 	if callobj.Calling == nil {
@@ -91,19 +97,30 @@ func (callobj *CallObj) ReceiveFax() (*FaxResult, error) {
 	}
 
 	ctrlID, _ := GenUUIDv4()
-	err := callobj.Calling.Relay.RelayReceiveFax(callobj.Calling.Ctx, callobj.call, &ctrlID, nil)
+	err := callobj.Calling.Relay.RelayReceiveFax(ctx, callobj.call, &ctrlID, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunFax(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunFax(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayReceiveFaxStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // SendFax TODO DESCRIPTION
 func (callobj *CallObj) SendFax(doc, id, headerInfo string) (*FaxResult, error) {
+	return callobj.SendFaxContext(callobj.ctx(), doc, id, headerInfo)
+}
+
+// SendFaxContext is SendFax, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) SendFaxContext(ctx context.Context, doc, id, headerInfo string) (*FaxResult, error) {
 	a := new(FaxAction)
 
 	if callobj.Calling == nil {
@@ -122,15 +139,21 @@ func (callobj *CallObj) SendFax(doc, id, headerInfo string) (*FaxResult, error)
 	fax.id = id
 	fax.headerInfo = headerInfo
 
-	err := callobj.Calling.Relay.RelaySendFax(callobj.Calling.Ctx, callobj.call, &ctrlID, &fax, nil)
+	err := callobj.Calling.Relay.RelaySendFax(ctx, callobj.call, &ctrlID, &fax, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunFax(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunFax(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelaySendFaxStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // SendFaxStop TODO DESCRIPTION
@@ -147,11 +170,21 @@ func (callobj *CallObj) SendFaxStop(ctrlID *string) error {
 }
 
 func (callobj *CallObj) callbacksRunFax(ctx context.Context, ctrlID string, res *FaxAction, norunCB bool) {
+	defer callobj.trackAction("fax", ctrlID)()
+
+	if !norunCB {
+		defer callobj.call.callbacks.listen(callobj.call.CallFaxChan)()
+	}
+
 	for {
 		var out bool
 
+		var cb *callbackSlot
+
 		select {
 		case faxevent := <-callobj.call.CallFaxChan:
+			cb = callobj.call.callbacks.take(callobj.call.CallFaxChan)
+
 			if faxevent == FaxFinished {
 				out = true
 			}
@@ -166,10 +199,10 @@ func (callobj *CallObj) callbacksRunFax(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				Log.Debug("Fax finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
+				callobj.logger().Debug("Fax finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
 
-				if callobj.OnFaxFinished != nil && !norunCB {
-					callobj.OnFaxFinished(res)
+				if !norunCB {
+					callobj.emit(cb, EventFaxFinished, res)
 				}
 			case FaxPage:
 				res.Lock()
@@ -178,13 +211,13 @@ func (callobj *CallObj) callbacksRunFax(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				Log.Debug("Page event. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Page event. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnFaxPage != nil && !norunCB {
-					callobj.OnFaxPage(res)
+				if !norunCB {
+					callobj.emit(cb, EventFaxPage, res)
 				}
 			case FaxError:
-				Log.Debug("Fax error. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Fax error. ctrlID: %s\n", ctrlID)
 
 				res.Lock()
 
@@ -193,14 +226,14 @@ func (callobj *CallObj) callbacksRunFax(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				if callobj.OnFaxError != nil && !norunCB {
-					callobj.OnFaxError(res)
+				if !norunCB {
+					callobj.emit(cb, EventFaxError, res)
 				}
 			default:
-				Log.Debug("Unknown state. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Unknown state. ctrlID: %s\n", ctrlID)
 			}
 		case fax := <-callobj.call.CallFaxEventChan:
-			Log.Debug("go params: %v\n", fax)
+			callobj.logger().Debug("go params: %v\n", fax)
 
 			switch fax.EventType {
 			case "page":
@@ -273,12 +306,34 @@ func (callobj *CallObj) callbacksRunFax(ctx context.Context, ctrlID string, res
 
 			callobj.call.CallFaxReadyChan <- struct{}{}
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallFaxChan) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			res.done <- res.Result.Successful
 			break
 		}
@@ -327,6 +382,8 @@ func (callobj *CallObj) ReceiveFaxAsync() (*FaxAction, error) {
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -383,6 +440,8 @@ func (callobj *CallObj) SendFaxAsync(doc, id, headerInfo string) (*FaxAction, er
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -451,6 +510,17 @@ func (action *FaxAction) GetCompleted() bool {
 	return ret
 }
 
+// GetError returns the error the action ended with, ErrCallEnded if stopped by the end of the call
+func (action *FaxAction) GetError() error {
+	action.RLock()
+
+	ret := action.err
+
+	action.RUnlock()
+
+	return ret
+}
+
 // GetResult TODO DESCRIPTION
 func (action *FaxAction) GetResult() FaxResult {
 	action.RLock()
@@ -57,6 +57,7 @@ type PlayAction struct {
 	Payload   *json.RawMessage
 	err       error
 	done      chan bool
+	events    eventStream
 	sync.RWMutex
 }
 
@@ -102,6 +103,11 @@ type TTSParamsInternal struct {
 
 // PlayAudio TODO DESCRIPTION
 func (callobj *CallObj) PlayAudio(s string) (*PlayResult, error) {
+	return callobj.PlayAudioContext(callobj.ctx(), s)
+}
+
+// PlayAudioContext is PlayAudio, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) PlayAudioContext(ctx context.Context, s string) (*PlayResult, error) {
 	a := new(PlayAction)
 
 	if callobj.Calling == nil {
@@ -114,19 +120,30 @@ func (callobj *CallObj) PlayAudio(s string) (*PlayResult, error) {
 
 	ctrlID, _ := GenUUIDv4()
 
-	err := callobj.Calling.Relay.RelayPlayAudio(callobj.Calling.Ctx, callobj.call, ctrlID, s, nil)
+	err := callobj.Calling.Relay.RelayPlayAudio(ctx, callobj.call, ctrlID, s, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunPlay(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunPlay(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayPlayStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // PlayTTS TODO DESCRIPTION
 func (callobj *CallObj) PlayTTS(text, language, gender string) (*PlayResult, error) {
+	return callobj.PlayTTSContext(callobj.ctx(), text, language, gender)
+}
+
+// PlayTTSContext is PlayTTS, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) PlayTTSContext(ctx context.Context, text, language, gender string) (*PlayResult, error) {
 	a := new(PlayAction)
 
 	if callobj.Calling == nil {
@@ -145,19 +162,30 @@ func (callobj *CallObj) PlayTTS(text, language, gender string) (*PlayResult, err
 	tts.language = language
 	tts.gender = gender
 
-	err := callobj.Calling.Relay.RelayPlayTTS(callobj.Calling.Ctx, callobj.call, ctrlID, &tts, nil)
+	err := callobj.Calling.Relay.RelayPlayTTS(ctx, callobj.call, ctrlID, &tts, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunPlay(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunPlay(ctx, ctrlID, a, true)
 
-	return &a.Result, nil
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayPlayStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
+
+	return &a.Result, a.err
 }
 
 // PlaySilence TODO DESCRIPTION
 func (callobj *CallObj) PlaySilence(duration float64) (*PlayResult, error) {
+	return callobj.PlaySilenceContext(callobj.ctx(), duration)
+}
+
+// PlaySilenceContext is PlaySilence, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) PlaySilenceContext(ctx context.Context, duration float64) (*PlayResult, error) {
 	a := new(PlayAction)
 
 	if callobj.Calling == nil {
@@ -169,19 +197,30 @@ func (callobj *CallObj) PlaySilence(duration float64) (*PlayResult, error) {
 	}
 
 	ctrlID, _ := GenUUIDv4()
-	err := callobj.Calling.Relay.RelayPlaySilence(callobj.Calling.Ctx, callobj.call, ctrlID, duration, nil)
+	err := callobj.Calling.Relay.RelayPlaySilence(ctx, callobj.call, ctrlID, duration, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunPlay(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunPlay(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayPlayStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // PlayRingtone TODO DESCRIPTION
 func (callobj *CallObj) PlayRingtone(name string, duration float64) (*PlayResult, error) {
+	return callobj.PlayRingtoneContext(callobj.ctx(), name, duration)
+}
+
+// PlayRingtoneContext is PlayRingtone, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) PlayRingtoneContext(ctx context.Context, name string, duration float64) (*PlayResult, error) {
 	a := new(PlayAction)
 
 	if callobj.Calling == nil {
@@ -193,15 +232,21 @@ func (callobj *CallObj) PlayRingtone(name string, duration float64) (*PlayResult
 	}
 
 	ctrlID, _ := GenUUIDv4()
-	err := callobj.Calling.Relay.RelayPlayRingtone(callobj.Calling.Ctx, callobj.call, ctrlID, name, duration, nil)
+	err := callobj.Calling.Relay.RelayPlayRingtone(ctx, callobj.call, ctrlID, name, duration, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunPlay(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunPlay(ctx, ctrlID, a, true)
 
-	return &a.Result, nil
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayPlayStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
+
+	return &a.Result, a.err
 }
 
 // PlayStop TODO DESCRIPTION
@@ -219,8 +264,16 @@ func (callobj *CallObj) PlayStop(ctrlID *string) error {
 
 // callbacksRunPlay TODO DESCRIPTION
 func (callobj *CallObj) callbacksRunPlay(ctx context.Context, ctrlID string, res *PlayAction, norunCB bool) {
+	defer callobj.trackAction("play", ctrlID)()
+
+	if !norunCB {
+		defer callobj.call.callbacks.listen(callobj.call.CallPlayChans[ctrlID])()
+	}
+
 	var out bool
 
+	var cb *callbackSlot
+
 	timer := time.NewTimer(BroadcastEventTimeout * time.Second)
 
 	for {
@@ -229,6 +282,8 @@ func (callobj *CallObj) callbacksRunPlay(ctx context.Context, ctrlID string, res
 			out = true
 		// get play states
 		case playstate := <-callobj.call.CallPlayChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallPlayChans[ctrlID])
+
 			res.RLock()
 
 			prevstate := res.State
@@ -245,12 +300,12 @@ func (callobj *CallObj) callbacksRunPlay(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				Log.Debug("Play finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
+				callobj.logger().Debug("Play finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
 
 				out = true
 
-				if callobj.OnPlayFinished != nil && !norunCB {
-					callobj.OnPlayFinished(res)
+				if !norunCB {
+					callobj.emit(cb, EventPlayFinished, res)
 				}
 
 			case PlayPlaying:
@@ -261,13 +316,13 @@ func (callobj *CallObj) callbacksRunPlay(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				Log.Debug("Playing. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Playing. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnPlayPlaying != nil && !norunCB {
-					callobj.OnPlayPlaying(res)
+				if !norunCB {
+					callobj.emit(cb, EventPlayPlaying, res)
 				}
 			case PlayError:
-				Log.Debug("Play error. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Play error. ctrlID: %s\n", ctrlID)
 
 				res.Lock()
 
@@ -278,8 +333,8 @@ func (callobj *CallObj) callbacksRunPlay(ctx context.Context, ctrlID string, res
 
 				out = true
 
-				if callobj.OnPlayError != nil && !norunCB {
-					callobj.OnPlayError(res)
+				if !norunCB {
+					callobj.emit(cb, EventPlayError, res)
 				}
 			case PlayPaused:
 				timer.Reset(MaxCallDuration * time.Second)
@@ -289,17 +344,17 @@ func (callobj *CallObj) callbacksRunPlay(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				Log.Debug("Play paused. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Play paused. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnPlayPaused != nil && !norunCB {
-					callobj.OnPlayPaused(res)
+				if !norunCB {
+					callobj.emit(cb, EventPlayPaused, res)
 				}
 			default:
-				Log.Debug("Unknown state. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Unknown state. ctrlID: %s\n", ctrlID)
 			}
 
-			if prevstate != playstate && callobj.OnPlayStateChange != nil && !norunCB {
-				callobj.OnPlayStateChange(res)
+			if prevstate != playstate && !norunCB {
+				callobj.emit(cb, EventPlayStateChange, res)
 			}
 		case rawEvent := <-callobj.call.CallPlayRawEventChans[ctrlID]:
 			res.Lock()
@@ -307,12 +362,35 @@ func (callobj *CallObj) callbacksRunPlay(ctx context.Context, ctrlID string, res
 			res.Unlock()
 
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallPlayChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+		cb = nil
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			if !norunCB {
 				res.done <- res.Result.Successful
 			}
@@ -363,6 +441,8 @@ func (callobj *CallObj) PlaySilenceAsync(duration float64) (*PlayAction, error)
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -414,6 +494,8 @@ func (callobj *CallObj) PlayRingtoneAsync(name string, duration float64) (*PlayA
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -471,6 +553,8 @@ func (callobj *CallObj) PlayTTSAsync(text, language, gender string) (*PlayAction
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -522,6 +606,8 @@ func (callobj *CallObj) PlayAudioAsync(url string) (*PlayAction, error) {
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -759,6 +845,11 @@ func (playaction *PlayAction) PlayResume() (*PlayVolumeResult, error) {
 
 // Play TODO DESCRIPTION
 func (callobj *CallObj) Play(g [MaxPlay]PlayGenericParams) ([]PlayResult, error) {
+	return callobj.PlayContext(callobj.ctx(), g)
+}
+
+// PlayContext is Play, cancelling ctx stops the current media and returns ctx.Err()
+func (callobj *CallObj) PlayContext(ctx context.Context, g [MaxPlay]PlayGenericParams) ([]PlayResult, error) {
 	var result []PlayResult
 
 	for _, playParams := range g {
@@ -768,7 +859,7 @@ func (callobj *CallObj) Play(g [MaxPlay]PlayGenericParams) ([]PlayResult, error)
 		if ok {
 			params, _ := playParams.SpecificParams.(*PlayAudio)
 
-			res, err := callobj.PlayAudio(params.URL)
+			res, err := callobj.PlayAudioContext(ctx, params.URL)
 			if err != nil {
 				return result, err
 			}
@@ -780,7 +871,7 @@ func (callobj *CallObj) Play(g [MaxPlay]PlayGenericParams) ([]PlayResult, error)
 		if ok {
 			params, _ := playParams.SpecificParams.(*PlayTTS)
 
-			res, err := callobj.PlayTTS(params.Text, params.Language, params.Gender)
+			res, err := callobj.PlayTTSContext(ctx, params.Text, params.Language, params.Gender)
 			if err != nil {
 				return result, err
 			}
@@ -792,7 +883,7 @@ func (callobj *CallObj) Play(g [MaxPlay]PlayGenericParams) ([]PlayResult, error)
 		if ok {
 			params, _ := playParams.SpecificParams.(*PlaySilence)
 
-			res, err := callobj.PlaySilence(params.Duration)
+			res, err := callobj.PlaySilenceContext(ctx, params.Duration)
 			if err != nil {
 				return result, err
 			}
@@ -59,6 +59,7 @@ type PromptAction struct {
 	Payload   *json.RawMessage
 	err       error
 	done      chan bool
+	events    eventStream
 	sync.RWMutex
 }
 
@@ -74,6 +75,11 @@ type IPromptAction interface {
 
 // Prompt TODO DESCRIPTION
 func (callobj *CallObj) Prompt(playlist *[]PlayStruct, collect *CollectStruct) (*CollectResult, error) {
+	return callobj.PromptContext(callobj.ctx(), playlist, collect)
+}
+
+// PromptContext is Prompt, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) PromptContext(ctx context.Context, playlist *[]PlayStruct, collect *CollectStruct) (*CollectResult, error) {
 	a := new(PromptAction)
 
 	if callobj.Calling == nil {
@@ -86,15 +92,21 @@ func (callobj *CallObj) Prompt(playlist *[]PlayStruct, collect *CollectStruct) (
 
 	ctrlID, _ := GenUUIDv4()
 
-	err := callobj.Calling.Relay.RelayPlayAndCollect(callobj.Calling.Ctx, callobj.call, ctrlID, playlist, collect, nil)
+	err := callobj.Calling.Relay.RelayPlayAndCollect(ctx, callobj.call, ctrlID, playlist, collect, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunPlayAndCollect(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunPlayAndCollect(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayPlayAndCollectStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // PromptStop TODO DESCRIPTION
@@ -112,8 +124,18 @@ func (callobj *CallObj) PromptStop(ctrlID *string) error {
 
 // callbacksRunPlayAndCollect TODO DESCRIPTION
 func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID string, res *PromptAction, norunCB bool) {
+	defer callobj.trackAction("prompt", ctrlID)()
+
+	if !norunCB {
+		defer callobj.call.callbacks.listen(callobj.call.CallPlayChans[ctrlID])()
+		defer callobj.call.callbacks.listen(callobj.call.CallPlayAndCollectChans[ctrlID])()
+		defer callobj.call.callbacks.listen(callobj.call.CallPlayAndCollectEventChans[ctrlID])()
+	}
+
 	var out bool
 
+	var cb *callbackSlot
+
 	timer := time.NewTimer(BroadcastEventTimeout * time.Second)
 
 	resPlay := new(PlayAction)
@@ -121,6 +143,8 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 	for {
 		select {
 		case playstate := <-callobj.call.CallPlayChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallPlayChans[ctrlID])
+
 			resPlay.RLock()
 
 			prevstate := res.State
@@ -135,10 +159,10 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				resPlay.Unlock()
 
-				Log.Debug("Play (prompt)  finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
+				callobj.logger().Debug("Play (prompt)  finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
 
-				if callobj.OnPlayFinished != nil && !norunCB {
-					callobj.OnPlayFinished(resPlay)
+				if !norunCB {
+					callobj.emit(cb, EventPlayFinished, resPlay)
 				}
 
 			case PlayPlaying:
@@ -149,13 +173,13 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				resPlay.Unlock()
 
-				Log.Debug("Playing. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Playing. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnPlayPlaying != nil && !norunCB {
-					callobj.OnPlayPlaying(resPlay)
+				if !norunCB {
+					callobj.emit(cb, EventPlayPlaying, resPlay)
 				}
 			case PlayError:
-				Log.Debug("Play (prompt) error. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Play (prompt) error. ctrlID: %s\n", ctrlID)
 
 				resPlay.Lock()
 
@@ -163,8 +187,8 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				resPlay.Unlock()
 
-				if callobj.OnPlayError != nil && !norunCB {
-					callobj.OnPlayError(resPlay)
+				if !norunCB {
+					callobj.emit(cb, EventPlayError, resPlay)
 				}
 			case PlayPaused:
 				timer.Reset(MaxCallDuration * time.Second)
@@ -174,17 +198,17 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				resPlay.Unlock()
 
-				Log.Debug("Play paused. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Play paused. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnPlayPaused != nil && !norunCB {
-					callobj.OnPlayPaused(resPlay)
+				if !norunCB {
+					callobj.emit(cb, EventPlayPaused, resPlay)
 				}
 			default:
-				Log.Debug("Unknown state. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Unknown state. ctrlID: %s\n", ctrlID)
 			}
 
-			if prevstate != playstate && callobj.OnPlayStateChange != nil && !norunCB {
-				callobj.OnPlayStateChange(resPlay)
+			if prevstate != playstate && !norunCB {
+				callobj.emit(cb, EventPlayStateChange, resPlay)
 			}
 		case rawEvent := <-callobj.call.CallPlayRawEventChans[ctrlID]:
 			resPlay.Lock()
@@ -192,7 +216,9 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 			resPlay.Unlock()
 
 		case resType := <-callobj.call.CallPlayAndCollectChans[ctrlID]:
-			Log.Debug("Got Prompt result type: %s", resType.String())
+			cb = callobj.call.callbacks.take(callobj.call.CallPlayAndCollectChans[ctrlID])
+
+			callobj.logger().Debug("Got Prompt result type: %s", resType.String())
 
 			switch resType {
 			case CollectResultError:
@@ -223,19 +249,21 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				res.Unlock()
 
-				Log.Debug("Prompt finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
+				callobj.logger().Debug("Prompt finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
 
 				out = true
 
-				if callobj.OnPrompt != nil && !norunCB {
-					callobj.OnPrompt(res)
+				if !norunCB {
+					callobj.emit(cb, EventPrompt, res)
 				}
 
 			default:
-				Log.Debug("Unknown state. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Unknown state. ctrlID: %s\n", ctrlID)
 			}
 		case params := <-callobj.call.CallPlayAndCollectEventChans[ctrlID]:
-			Log.Debug("got params for ctrlID : %s params: %v\n", ctrlID, params)
+			cb = callobj.call.callbacks.take(callobj.call.CallPlayAndCollectEventChans[ctrlID])
+
+			callobj.logger().Debug("got params for ctrlID : %s params: %v\n", ctrlID, params)
 
 			res.Lock()
 
@@ -244,7 +272,7 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				confidence, ok1 := speech["confidence"].(float64)
 				if !ok1 {
-					Log.Error("type assertion error")
+					callobj.logger().Error("type assertion error")
 
 					out = true
 				} else {
@@ -253,7 +281,7 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				text, ok2 := speech["text"].(string)
 				if !ok2 {
-					Log.Error("type assertion error")
+					callobj.logger().Error("type assertion error")
 
 					out = true
 				} else {
@@ -263,8 +291,8 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 				digit := params.Result.Params
 
 				terminator, ok1 := digit["terminator"].(string)
-				if !ok1 {
-					Log.Error("type assertion error")
+				if !ok1 && !params.partial {
+					callobj.logger().Error("type assertion error")
 
 					out = true
 				} else {
@@ -273,7 +301,7 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 
 				digits, ok2 := digit["digits"].(string)
 				if !ok2 {
-					Log.Error("type assertion error")
+					callobj.logger().Error("type assertion error")
 
 					out = true
 				} else {
@@ -281,19 +309,52 @@ func (callobj *CallObj) callbacksRunPlayAndCollect(ctx context.Context, ctrlID s
 				}
 			}
 
+			if params.partial {
+				res.Result.Continue = CollectPartial
+			} else {
+				res.Result.Continue = CollectFinal
+			}
+
 			res.Unlock()
+
+			if params.partial && !out && !norunCB {
+				callobj.emit(cb, EventPromptUpdate, res)
+			}
 		case rawEvent := <-callobj.call.CallPlayAndCollectRawEventChans[ctrlID]:
 			res.Lock()
 			res.Result.Event = *rawEvent
 			res.Unlock()
 
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallPlayChans[ctrlID], callobj.call.CallPlayAndCollectChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+		cb = nil
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			if !norunCB {
 				res.done <- res.Result.Successful
 			}
@@ -346,6 +407,8 @@ func (callobj *CallObj) PromptAsync(playlist *[]PlayStruct, collect *CollectStru
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -414,6 +477,17 @@ func (action *PromptAction) GetCompleted() bool {
 	return ret
 }
 
+// GetError returns the error the action ended with, ErrCallEnded if stopped by the end of the call
+func (action *PromptAction) GetError() error {
+	action.RLock()
+
+	ret := action.err
+
+	action.RUnlock()
+
+	return ret
+}
+
 // GetResult TODO DESCRIPTION
 func (action *PromptAction) GetResult() CollectResult {
 	action.RLock()
@@ -57,6 +57,7 @@ type RecordAction struct {
 	Payload   *json.RawMessage
 	err       error
 	done      chan bool
+	events    eventStream
 	sync.RWMutex
 }
 
@@ -68,6 +69,11 @@ type IRecordAction interface {
 
 // RecordAudio TODO DESCRIPTION
 func (callobj *CallObj) RecordAudio(rec *RecordParams) (*RecordResult, error) {
+	return callobj.RecordAudioContext(callobj.ctx(), rec)
+}
+
+// RecordAudioContext is RecordAudio, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) RecordAudioContext(ctx context.Context, rec *RecordParams) (*RecordResult, error) {
 	a := new(RecordAction)
 
 	if callobj.Calling == nil {
@@ -79,15 +85,21 @@ func (callobj *CallObj) RecordAudio(rec *RecordParams) (*RecordResult, error) {
 	}
 
 	ctrlID, _ := GenUUIDv4()
-	err := callobj.Calling.Relay.RelayRecordAudio(callobj.Calling.Ctx, callobj.call, ctrlID, rec, nil)
+	err := callobj.Calling.Relay.RelayRecordAudio(ctx, callobj.call, ctrlID, rec, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunRecord(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunRecord(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayRecordAudioStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // RecordAudioStop TODO DESCRIPTION
@@ -104,8 +116,16 @@ func (callobj *CallObj) RecordAudioStop(ctrlID *string) error {
 }
 
 func (callobj *CallObj) callbacksRunRecord(ctx context.Context, ctrlID string, res *RecordAction, norunCB bool) {
+	defer callobj.trackAction("record", ctrlID)()
+
+	if !norunCB {
+		defer callobj.call.callbacks.listen(callobj.call.CallRecordChans[ctrlID])()
+	}
+
 	var out bool
 
+	var cb *callbackSlot
+
 	timer := time.NewTimer(BroadcastEventTimeout * time.Second)
 
 	for {
@@ -113,6 +133,8 @@ func (callobj *CallObj) callbacksRunRecord(ctx context.Context, ctrlID string, r
 		case <-timer.C:
 			out = true
 		case state := <-callobj.call.CallRecordChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallRecordChans[ctrlID])
+
 			res.RLock()
 
 			prevstate := res.State
@@ -129,14 +151,14 @@ func (callobj *CallObj) callbacksRunRecord(ctx context.Context, ctrlID string, r
 
 				res.Unlock()
 
-				Log.Debug("Record finished. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Record finished. ctrlID: %s\n", ctrlID)
 
 				callobj.call.RemoveAction(ctrlID)
 
 				out = true
 
-				if callobj.OnRecordFinished != nil && !norunCB {
-					callobj.OnRecordFinished(res)
+				if !norunCB {
+					callobj.emit(cb, EventRecordFinished, res)
 				}
 			case RecordRecording:
 				timer.Reset(MaxCallDuration * time.Second)
@@ -146,13 +168,13 @@ func (callobj *CallObj) callbacksRunRecord(ctx context.Context, ctrlID string, r
 
 				res.Unlock()
 
-				Log.Debug("Recording. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Recording. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnRecordRecording != nil && !norunCB {
-					callobj.OnRecordRecording(res)
+				if !norunCB {
+					callobj.emit(cb, EventRecordRecording, res)
 				}
 			case RecordNoInput:
-				Log.Debug("No input for recording. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("No input for recording. ctrlID: %s\n", ctrlID)
 				res.Lock()
 
 				res.Completed = true
@@ -162,8 +184,8 @@ func (callobj *CallObj) callbacksRunRecord(ctx context.Context, ctrlID string, r
 
 				out = true
 
-				if callobj.OnRecordNoInput != nil && !norunCB {
-					callobj.OnRecordNoInput(res)
+				if !norunCB {
+					callobj.emit(cb, EventRecordNoInput, res)
 				}
 			case RecordPaused:
 				timer.Reset(MaxCallDuration * time.Second)
@@ -173,20 +195,18 @@ func (callobj *CallObj) callbacksRunRecord(ctx context.Context, ctrlID string, r
 
 				res.Unlock()
 
-				Log.Debug("Recording paused. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Recording paused. ctrlID: %s\n", ctrlID)
 
 				out = true
 
-				if callobj.OnRecordPaused != nil {
-					callobj.OnRecordPaused(res)
-				}
+				callobj.emit(cb, EventRecordPaused, res)
 			}
 
-			if prevstate != state && callobj.OnRecordStateChange != nil && !norunCB {
-				callobj.OnRecordStateChange(res)
+			if prevstate != state && !norunCB {
+				callobj.emit(cb, EventRecordStateChange, res)
 			}
 		case params := <-callobj.call.CallRecordEventChans[ctrlID]:
-			Log.Debug("got params for ctrlID : %s\n", ctrlID)
+			callobj.logger().Debug("got params for ctrlID : %s\n", ctrlID)
 
 			res.Lock()
 
@@ -213,12 +233,35 @@ func (callobj *CallObj) callbacksRunRecord(ctx context.Context, ctrlID string, r
 
 			callobj.call.CallRecordReadyChans[ctrlID] <- struct{}{}
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallRecordChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+		cb = nil
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			if !norunCB {
 				res.done <- res.Result.Successful
 			}
@@ -270,6 +313,8 @@ func (callobj *CallObj) RecordAudioAsync(rec *RecordParams) (*RecordAction, erro
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -293,7 +338,7 @@ func (recordaction *RecordAction) recordAudioAsyncStop() error {
 
 	if len(recordaction.ControlID) == 0 {
 		recordaction.RUnlock()
-		Log.Error("no controlID\n")
+		recordaction.logger().Error("no controlID\n")
 
 		return errors.New("no controlID")
 	}
@@ -330,6 +375,17 @@ func (recordaction *RecordAction) GetCompleted() bool {
 	return ret
 }
 
+// GetError returns the error the action ended with, ErrCallEnded if stopped by the end of the call
+func (recordaction *RecordAction) GetError() error {
+	recordaction.RLock()
+
+	ret := recordaction.err
+
+	recordaction.RUnlock()
+
+	return ret
+}
+
 // GetResult TODO DESCRIPTION
 func (recordaction *RecordAction) GetResult() RecordResult {
 	recordaction.RLock()
@@ -35,6 +35,7 @@ type SendDigitsAction struct {
 	State     SendDigitsState
 	Payload   *json.RawMessage
 	err       error
+	events    eventStream
 	sync.RWMutex
 }
 
@@ -58,6 +59,11 @@ func checkDtmf(s string) bool {
 
 // SendDigits TODO DESCRIPTION
 func (callobj *CallObj) SendDigits(digits string) (*SendDigitsResult, error) {
+	return callobj.SendDigitsContext(callobj.ctx(), digits)
+}
+
+// SendDigitsContext is SendDigits, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) SendDigitsContext(ctx context.Context, digits string) (*SendDigitsResult, error) {
 	if !checkDtmf(digits) {
 		return nil, errors.New("invalid DTMF")
 	}
@@ -73,24 +79,38 @@ func (callobj *CallObj) SendDigits(digits string) (*SendDigitsResult, error) {
 	}
 
 	ctrlID, _ := GenUUIDv4()
-	err := callobj.Calling.Relay.RelaySendDigits(callobj.Calling.Ctx, callobj.call, ctrlID, digits, nil)
+	err := callobj.Calling.Relay.RelaySendDigits(ctx, callobj.call, ctrlID, digits, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunSendDigits(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunSendDigits(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), nil); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // callbacksRunSendDigits TODO DESCRIPTION
-func (callobj *CallObj) callbacksRunSendDigits(_ context.Context, ctrlID string, res *SendDigitsAction, norunCB bool) {
+func (callobj *CallObj) callbacksRunSendDigits(ctx context.Context, ctrlID string, res *SendDigitsAction, norunCB bool) {
+	defer callobj.trackAction("senddigits", ctrlID)()
+
+	if !norunCB {
+		defer callobj.call.callbacks.listen(callobj.call.CallSendDigitsChans[ctrlID])()
+	}
+
 	var out bool
 
+	var cb *callbackSlot
+
 	for {
 		select {
 		case state := <-callobj.call.CallSendDigitsChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallSendDigitsChans[ctrlID])
+
 			res.RLock()
 
 			prevstate := res.State
@@ -107,20 +127,20 @@ func (callobj *CallObj) callbacksRunSendDigits(_ context.Context, ctrlID string,
 
 				res.Unlock()
 
-				Log.Debug("SendDigits finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
+				callobj.logger().Debug("SendDigits finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
 
 				out = true
 
-				if callobj.OnSendDigitsFinished != nil && !norunCB {
-					callobj.OnSendDigitsFinished(res)
+				if !norunCB {
+					callobj.emit(cb, EventSendDigitsFinished, res)
 				}
 
 			default:
-				Log.Debug("Unknown state. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Unknown state. ctrlID: %s\n", ctrlID)
 			}
 
-			if prevstate != state && callobj.OnSendDigitsStateChange != nil && !norunCB {
-				callobj.OnSendDigitsStateChange(res)
+			if prevstate != state && !norunCB {
+				callobj.emit(cb, EventSendDigitsStateChange, res)
 			}
 		case rawEvent := <-callobj.call.CallSendDigitsRawEventChans[ctrlID]:
 			res.Lock()
@@ -129,10 +149,35 @@ func (callobj *CallObj) callbacksRunSendDigits(_ context.Context, ctrlID string,
 
 			callobj.call.CallSendDigitsReadyChans[ctrlID] <- struct{}{}
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallSendDigitsChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
+			out = true
+		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+		cb = nil
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			break
 		}
 	}
@@ -183,6 +228,8 @@ func (callobj *CallObj) SendDigitsAsync(digits string) (*SendDigitsAction, error
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		}
 		done <- struct{}{}
 	}()
@@ -203,6 +250,17 @@ func (action *SendDigitsAction) GetCompleted() bool {
 	return ret
 }
 
+// GetError returns the error the action ended with, ErrCallEnded if stopped by the end of the call
+func (action *SendDigitsAction) GetError() error {
+	action.RLock()
+
+	ret := action.err
+
+	action.RUnlock()
+
+	return ret
+}
+
 // GetResult TODO DESCRIPTION
 func (action *SendDigitsAction) GetResult() SendDigitsResult {
 	action.RLock()
@@ -87,6 +87,7 @@ type TapAction struct {
 	Payload   *json.RawMessage
 	err       error
 	done      chan bool
+	events    eventStream
 	sync.RWMutex
 }
 
@@ -103,6 +104,11 @@ type ITapAction interface {
 
 // TapAudio TODO DESCRIPTION
 func (callobj *CallObj) TapAudio(direction fmt.Stringer, tapdev *TapDevice) (*TapResult, error) {
+	return callobj.TapAudioContext(callobj.ctx(), direction, tapdev)
+}
+
+// TapAudioContext is TapAudio, cancelling ctx stops it and returns ctx.Err()
+func (callobj *CallObj) TapAudioContext(ctx context.Context, direction fmt.Stringer, tapdev *TapDevice) (*TapResult, error) {
 	a := new(TapAction)
 
 	if callobj.Calling == nil {
@@ -117,15 +123,21 @@ func (callobj *CallObj) TapAudio(direction fmt.Stringer, tapdev *TapDevice) (*Ta
 
 	var err error
 
-	a.Result.SourceDevice, err = callobj.Calling.Relay.RelayTapAudio(callobj.Calling.Ctx, callobj.call, ctrlID, direction.String(), tapdev, nil)
+	a.Result.SourceDevice, err = callobj.Calling.Relay.RelayTapAudio(ctx, callobj.call, ctrlID, direction.String(), tapdev, nil)
 
 	if err != nil {
 		return &a.Result, err
 	}
 
-	callobj.callbacksRunTap(callobj.Calling.Ctx, ctrlID, a, true)
+	callobj.callbacksRunTap(ctx, ctrlID, a, true)
+
+	if err := callobj.cancelAction(ctx, a.GetCompleted(), func(stopCtx context.Context) error {
+		return callobj.Calling.Relay.RelayTapStop(stopCtx, callobj.call, &ctrlID, nil)
+	}); err != nil {
+		return &a.Result, err
+	}
 
-	return &a.Result, nil
+	return &a.Result, a.err
 }
 
 // TapStop TODO DESCRIPTION
@@ -143,8 +155,16 @@ func (callobj *CallObj) TapStop(ctrlID *string) error {
 
 // callbacksRunTap TODO DESCRIPTION
 func (callobj *CallObj) callbacksRunTap(ctx context.Context, ctrlID string, res *TapAction, norunCB bool) {
+	defer callobj.trackAction("tap", ctrlID)()
+
+	if !norunCB {
+		defer callobj.call.callbacks.listen(callobj.call.CallTapChans[ctrlID])()
+	}
+
 	var out bool
 
+	var cb *callbackSlot
+
 	timer := time.NewTimer(BroadcastEventTimeout * time.Second)
 
 	for {
@@ -153,6 +173,8 @@ func (callobj *CallObj) callbacksRunTap(ctx context.Context, ctrlID string, res
 			out = true
 		// get tap states
 		case tapstate := <-callobj.call.CallTapChans[ctrlID]:
+			cb = callobj.call.callbacks.take(callobj.call.CallTapChans[ctrlID])
+
 			res.RLock()
 
 			prevstate := res.State
@@ -169,12 +191,12 @@ func (callobj *CallObj) callbacksRunTap(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				Log.Debug("Tap finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
+				callobj.logger().Debug("Tap finished. ctrlID: %s res [%p] Completed [%v] Successful [%v]\n", ctrlID, res, res.Completed, res.Result.Successful)
 
 				out = true
 
-				if callobj.OnTapFinished != nil && !norunCB {
-					callobj.OnTapFinished(res)
+				if !norunCB {
+					callobj.emit(cb, EventTapFinished, res)
 				}
 
 			case TapTapping:
@@ -185,21 +207,21 @@ func (callobj *CallObj) callbacksRunTap(ctx context.Context, ctrlID string, res
 
 				res.Unlock()
 
-				Log.Debug("Tapping. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Tapping. ctrlID: %s\n", ctrlID)
 
-				if callobj.OnTapTapping != nil && !norunCB {
-					callobj.OnTapTapping(res)
+				if !norunCB {
+					callobj.emit(cb, EventTapTapping, res)
 				}
 			default:
-				Log.Debug("Unknown state. ctrlID: %s\n", ctrlID)
+				callobj.logger().Debug("Unknown state. ctrlID: %s\n", ctrlID)
 			}
 
-			if prevstate != tapstate && callobj.OnTapStateChange != nil && !norunCB {
-				callobj.OnTapStateChange(res)
+			if prevstate != tapstate && !norunCB {
+				callobj.emit(cb, EventTapStateChange, res)
 			}
 
 		case params := <-callobj.call.CallTapEventChans[ctrlID]:
-			Log.Debug("got params for ctrlID : %s %v\n", ctrlID, params)
+			callobj.logger().Debug("got params for ctrlID : %s %v\n", ctrlID, params)
 
 			res.Lock()
 
@@ -259,12 +281,35 @@ func (callobj *CallObj) callbacksRunTap(ctx context.Context, ctrlID string, res
 
 			callobj.call.CallTapReadyChans[ctrlID] <- struct{}{}
 		case <-callobj.call.Hangup:
+			if buffered(callobj.call.CallTapChans[ctrlID]) {
+				// the events that came before the hangup first
+				break
+			}
+
+			res.Lock()
+			res.err = callobj.call.endedError()
+			res.Completed = true
+			res.Unlock()
+
 			out = true
 		case <-ctx.Done():
+			if callobj.ctx().Err() != nil {
+				// the consumer stopped
+				res.Lock()
+				res.err = ErrCallEnded
+				res.Completed = true
+				res.Unlock()
+			}
+
 			out = true
 		}
 
+		cb.done()
+		cb = nil
+
 		if out {
+			callobj.endEvents(&res.events)
+
 			if !norunCB {
 				res.done <- res.Result.Successful
 			}
@@ -316,6 +361,8 @@ func (callobj *CallObj) TapAudioAsync(direction fmt.Stringer, tapdev *TapDevice)
 			res.Completed = true
 
 			res.Unlock()
+
+			callobj.endEvents(&res.events)
 		} else {
 			res.Lock()
 
@@ -390,6 +437,17 @@ func (tapaction *TapAction) GetCompleted() bool {
 	return ret
 }
 
+// GetError returns the error the action ended with, ErrCallEnded if stopped by the end of the call
+func (tapaction *TapAction) GetError() error {
+	tapaction.RLock()
+
+	ret := tapaction.err
+
+	tapaction.RUnlock()
+
+	return ret
+}
+
 // GetResult TODO DESCRIPTION
 func (tapaction *TapAction) GetResult() TapResult {
 	tapaction.RLock()
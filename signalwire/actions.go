@@ -0,0 +1,372 @@
+package signalwire
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCallEnded completes the actions still running when their call ends, or when the consumer stops
+var ErrCallEnded = errors.New("call ended")
+
+// ActionReclaimTimeout is how long the actions of an ended call have to stop,
+// the ones still running after that are reported as leaked
+var ActionReclaimTimeout = 5 * time.Second
+
+// ActionStats counts the actions (play, record, detect...) of the calls of a session
+type ActionStats struct {
+	// Started actions
+	Started uint64
+	// Active actions, still running
+	Active uint64
+	// Reclaimed actions, stopped by the end of their call (or of the consumer)
+	Reclaimed uint64
+	// Leaked actions, still running ActionReclaimTimeout after their call ended
+	Leaked uint64
+}
+
+// actionCounters is the runtime side of ActionStats
+type actionCounters struct {
+	sync.Mutex
+	ActionStats
+}
+
+func (c *actionCounters) start() {
+	if c == nil {
+		return
+	}
+
+	c.Lock()
+
+	c.Started++
+	c.Active++
+
+	c.Unlock()
+}
+
+func (c *actionCounters) finish() {
+	if c == nil {
+		return
+	}
+
+	c.Lock()
+
+	c.Active--
+
+	c.Unlock()
+}
+
+func (c *actionCounters) reclaim(reclaimed, leaked uint64) {
+	if c == nil {
+		return
+	}
+
+	c.Lock()
+
+	c.Reclaimed += reclaimed
+	c.Leaked += leaked
+
+	c.Unlock()
+}
+
+func (c *actionCounters) get() ActionStats {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.ActionStats
+}
+
+// trackedAction is a running action, one per callbacksRun* go routine
+type trackedAction struct {
+	kind   string
+	ctrlID string
+}
+
+// callActions tracks the actions of a call, so that their go routines and
+// channels are reclaimed once the call has ended
+type callActions struct {
+	sync.Mutex
+	running  map[*trackedAction]struct{}
+	ctrlIDs  map[string]struct{}
+	changed  chan struct{}
+	watching bool
+	// ended is set once the call has ended, the last action to stop releases the channels
+	ended bool
+}
+
+func (a *callActions) count() int {
+	a.Lock()
+	defer a.Unlock()
+
+	return len(a.running)
+}
+
+// ctrlIDList returns the control IDs of the actions of the call and forgets them
+func (a *callActions) ctrlIDList() []string {
+	a.Lock()
+	defer a.Unlock()
+
+	list := make([]string, 0, len(a.ctrlIDs))
+
+	for id := range a.ctrlIDs {
+		list = append(list, id)
+	}
+
+	a.ctrlIDs = make(map[string]struct{})
+
+	return list
+}
+
+// actionCounters returns the counters of the session of the call, nil if none
+func (callobj *CallObj) actionCounters() *actionCounters {
+	if callobj.Calling == nil || callobj.Calling.Relay == nil || callobj.Calling.Relay.Blade == nil {
+		return nil
+	}
+
+	return &callobj.Calling.Relay.Blade.actionStats
+}
+
+// trackAction registers a running action of the call, the returned func is
+// called when its go routine exits
+func (callobj *CallObj) trackAction(kind, ctrlID string) func() {
+	call := callobj.call
+	action := &trackedAction{kind: kind, ctrlID: ctrlID}
+	stats := callobj.actionCounters()
+
+	call.actions.Lock()
+
+	if call.actions.running == nil {
+		call.actions.running = make(map[*trackedAction]struct{})
+		call.actions.ctrlIDs = make(map[string]struct{})
+		call.actions.changed = make(chan struct{}, 1)
+	}
+
+	call.actions.running[action] = struct{}{}
+
+	if ctrlID != "" {
+		call.actions.ctrlIDs[ctrlID] = struct{}{}
+	}
+
+	watch := !call.actions.watching
+	call.actions.watching = true
+
+	call.actions.Unlock()
+
+	stats.start()
+
+	if watch {
+		go callobj.reclaimActions()
+	}
+
+	return func() {
+		call.actions.Lock()
+
+		delete(call.actions.running, action)
+		last := call.actions.ended && len(call.actions.running) == 0
+
+		call.actions.Unlock()
+
+		stats.finish()
+
+		select {
+		case <-call.Hangup:
+			stats.reclaim(1, 0)
+		case <-callobj.ctx().Done():
+			stats.reclaim(1, 0)
+		default:
+		}
+
+		select {
+		case call.actions.changed <- struct{}{}:
+		default:
+		}
+
+		if last {
+			callobj.releaseActions(call.actions.ctrlIDList())
+		}
+	}
+}
+
+// reclaimActions waits for the call to end, or for the consumer to stop, then
+// for its actions to complete, and releases their channels
+func (callobj *CallObj) reclaimActions() {
+	call := callobj.call
+
+	select {
+	case <-call.Hangup:
+	case <-callobj.ctx().Done():
+	}
+
+	timer := time.NewTimer(ActionReclaimTimeout)
+	defer timer.Stop()
+
+	for call.actions.count() > 0 {
+		select {
+		case <-call.actions.changed:
+		case <-timer.C:
+			call.actions.Lock()
+			call.actions.ended = true
+			leaked := make([]string, 0, len(call.actions.running))
+
+			for action := range call.actions.running {
+				leaked = append(leaked, action.kind+" "+action.ctrlID)
+			}
+
+			call.actions.Unlock()
+
+			if len(leaked) == 0 {
+				continue
+			}
+
+			callobj.logger().Warn("call [%s]: actions not stopped %v after the end of the call, leaked: %s\n", call.GetCallID(), ActionReclaimTimeout, strings.Join(leaked, ", "))
+
+			callobj.actionCounters().reclaim(0, uint64(len(leaked)))
+
+			// the channels are released once the last of them stops
+			return
+		}
+	}
+
+	call.actions.Lock()
+	call.actions.ended = true
+	call.actions.Unlock()
+
+	callobj.releaseActions(call.actions.ctrlIDList())
+}
+
+// releaseActions removes the channels of the actions from the call, on its
+// event bus so that no event is being sent on them
+func (callobj *CallObj) releaseActions(ctrlIDs []string) {
+	if len(ctrlIDs) == 0 {
+		return
+	}
+
+	call := callobj.call
+	release := func() { call.releaseActionChans(ctrlIDs) }
+
+	if callobj.Calling == nil || callobj.Calling.Relay == nil || callobj.Calling.Relay.Blade == nil {
+		release()
+
+		return
+	}
+
+	blade := callobj.Calling.Relay.Blade
+	cfg := EventBusConfig{Overflow: EventOverflowBlock}
+
+	if blade.EventBus != nil {
+		cfg.QueueSize = blade.EventBus.QueueSize
+	}
+
+	if err := call.bus.enqueue(&cfg, &blade.eventStats, release); err != nil {
+		callobj.logger().Warn("call [%s]: cannot release the actions: %v\n", call.GetCallID(), err)
+	}
+}
+
+// releaseActionChans removes the channels of the actions from the maps of the call and closes them
+func (c *CallSession) releaseActionChans(ctrlIDs []string) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, id := range ctrlIDs {
+		if ch, ok := c.CallPlayChans[id]; ok {
+			close(ch)
+			close(c.CallPlayEventChans[id])
+			close(c.CallPlayRawEventChans[id])
+		}
+
+		delete(c.CallPlayChans, id)
+		delete(c.CallPlayEventChans, id)
+		delete(c.CallPlayReadyChans, id)
+		delete(c.CallPlayRawEventChans, id)
+
+		if ch, ok := c.CallRecordChans[id]; ok {
+			close(ch)
+			close(c.CallRecordEventChans[id])
+			close(c.CallRecordRawEventChans[id])
+		}
+
+		delete(c.CallRecordChans, id)
+		delete(c.CallRecordEventChans, id)
+		delete(c.CallRecordReadyChans, id)
+		delete(c.CallRecordRawEventChans, id)
+
+		if ch, ok := c.CallDetectMachineChans[id]; ok {
+			close(ch)
+		}
+
+		if ch, ok := c.CallDetectDigitChans[id]; ok {
+			close(ch)
+		}
+
+		if ch, ok := c.CallDetectFaxChans[id]; ok {
+			close(ch)
+		}
+
+		if ch, ok := c.CallDetectRawEventChans[id]; ok {
+			close(ch)
+		}
+
+		delete(c.CallDetectMachineChans, id)
+		delete(c.CallDetectDigitChans, id)
+		delete(c.CallDetectFaxChans, id)
+		delete(c.CallDetectEventChans, id)
+		delete(c.CallDetectReadyChans, id)
+		delete(c.CallDetectRawEventChans, id)
+
+		if ch, ok := c.CallTapChans[id]; ok {
+			close(ch)
+			close(c.CallTapEventChans[id])
+			close(c.CallTapRawEventChans[id])
+		}
+
+		delete(c.CallTapChans, id)
+		delete(c.CallTapEventChans, id)
+		delete(c.CallTapReadyChans, id)
+		delete(c.CallTapRawEventChans, id)
+
+		if ch, ok := c.CallSendDigitsChans[id]; ok {
+			close(ch)
+			close(c.CallSendDigitsRawEventChans[id])
+		}
+
+		delete(c.CallSendDigitsChans, id)
+		delete(c.CallSenDigitsEventChans, id)
+		delete(c.CallSendDigitsReadyChans, id)
+		delete(c.CallSendDigitsRawEventChans, id)
+
+		if ch, ok := c.CallPlayAndCollectChans[id]; ok {
+			close(ch)
+			close(c.CallPlayAndCollectEventChans[id])
+			close(c.CallPlayAndCollectRawEventChans[id])
+		}
+
+		delete(c.CallPlayAndCollectChans, id)
+		delete(c.CallPlayAndCollectEventChans, id)
+		delete(c.CallPlayAndCollectReadyChans, id)
+		delete(c.CallPlayAndCollectRawEventChans, id)
+	}
+}
+
+// ActionStats returns the action counters of all the calls of the session
+func (blade *BladeSession) ActionStats() ActionStats {
+	return blade.actionStats.get()
+}
+
+// ActionStats returns the action counters of all the calls of the session
+func (client *ClientSession) ActionStats() ActionStats {
+	if client.Relay.Blade == nil {
+		return ActionStats{}
+	}
+
+	return client.Relay.Blade.ActionStats()
+}
+
+// ActionStats returns the action counters of all the calls of the consumer
+func (consumer *Consumer) ActionStats() ActionStats {
+	if consumer.Client == nil {
+		return ActionStats{}
+	}
+
+	return consumer.Client.ActionStats()
+}
@@ -0,0 +1,409 @@
+package signalwire
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultAdmissionQueueTimeout is how long a queued inbound call waits for a handler
+const DefaultAdmissionQueueTimeout = 10 * time.Second
+
+// admissionRejectTimeout bounds the rejection of a queued call once the consumer is stopping
+const admissionRejectTimeout = 5 * time.Second
+
+// DefaultBusyMessage is played by AdmissionBusy
+const DefaultBusyMessage = "All our lines are busy, please call again later."
+
+// AdmissionOverflow is what the consumer does with an inbound call it cannot admit
+type AdmissionOverflow int
+
+// Overflow actions
+const (
+	// AdmissionReject ends the call, unanswered, with the busy reason
+	AdmissionReject AdmissionOverflow = iota
+	// AdmissionBusy answers the call, plays BusyMessage then hangs up
+	AdmissionBusy
+	// AdmissionQueue keeps the call waiting for a handler, up to QueueTimeout, then rejects it
+	AdmissionQueue
+)
+
+func (o AdmissionOverflow) String() string {
+	if o < AdmissionReject || o > AdmissionQueue {
+		return "Unknown"
+	}
+
+	return [...]string{"Reject", "Busy", "Queue"}[o]
+}
+
+// AdmissionConfig limits the OnIncomingCall handlers of a Consumer, so that a
+// spike of inbound calls does not exhaust the resources behind them
+type AdmissionConfig struct {
+	// MaxConcurrent is the max number of handlers running, 0 means no limit
+	MaxConcurrent int
+	// Rate is the max number of calls admitted per second, 0 means no limit
+	Rate float64
+	// Burst is the number of calls admitted at once under Rate, 0 means 1
+	Burst int
+	// Overflow is what happens to the calls above the limits
+	Overflow AdmissionOverflow
+	// BusyMessage is the TTS of AdmissionBusy, empty means DefaultBusyMessage
+	BusyMessage string
+	// QueueSize is the max number of calls waiting (AdmissionQueue), the
+	// calls above are rejected, 0 means MaxConcurrent (or no limit)
+	QueueSize int
+	// QueueTimeout is the max wait of a queued call, 0 means DefaultAdmissionQueueTimeout
+	QueueTimeout time.Duration
+}
+
+// AdmissionStats are the metrics of the admission of the inbound calls
+type AdmissionStats struct {
+	// Admitted calls, given to OnIncomingCall
+	Admitted uint64
+	// Rejected calls, on overflow, queue full or queue timeout (busy message included)
+	Rejected uint64
+	// Queued calls, that waited for a handler
+	Queued uint64
+	// Abandoned calls, hung up while queued
+	Abandoned uint64
+	// Running handlers
+	Running int
+	// Waiting calls, in the queue
+	Waiting int
+}
+
+// admission enforces the AdmissionConfig of a consumer
+type admission struct {
+	sync.Mutex
+	config AdmissionConfig
+	stats  AdmissionStats
+	// tokens of the rate limiter, refilled since last
+	tokens float64
+	last   time.Time
+	// waiters of the queued calls, first in first out: the first one is
+	// woken up when it may be admitted
+	waiters []chan struct{}
+}
+
+// setConfig changes the limits, cfg may be nil
+func (a *admission) setConfig(cfg *AdmissionConfig) {
+	a.Lock()
+	defer a.Unlock()
+
+	a.config = AdmissionConfig{}
+
+	if cfg != nil {
+		a.config = *cfg
+	}
+
+	a.tokens = float64(a.burst())
+	a.last = time.Now()
+}
+
+func (a *admission) burst() int {
+	if a.config.Burst > 0 {
+		return a.config.Burst
+	}
+
+	return 1
+}
+
+// take admits a call if under the limits, else it returns how long to wait
+// for the rate limiter (0 if there is no handler free), the lock is held
+func (a *admission) take() (bool, time.Duration) {
+	if a.config.MaxConcurrent > 0 && a.stats.Running >= a.config.MaxConcurrent {
+		return false, 0
+	}
+
+	if a.config.Rate > 0 {
+		now := time.Now()
+
+		a.tokens += now.Sub(a.last).Seconds() * a.config.Rate
+		a.last = now
+
+		if burst := float64(a.burst()); a.tokens > burst {
+			a.tokens = burst
+		}
+
+		if a.tokens < 1 {
+			return false, time.Duration((1 - a.tokens) / a.config.Rate * float64(time.Second))
+		}
+
+		a.tokens--
+	}
+
+	a.stats.Running++
+	a.stats.Admitted++
+
+	return true, 0
+}
+
+// admit is true if the call can be handled now; if not, waiter is set when
+// the call has to wait in the queue (see wait), else it is to be rejected.
+// The calls already queued go first.
+func (a *admission) admit() (ok bool, waiter chan struct{}) {
+	a.Lock()
+	defer a.Unlock()
+
+	if len(a.waiters) == 0 {
+		if ok, _ := a.take(); ok {
+			return true, nil
+		}
+	}
+
+	if a.config.Overflow != AdmissionQueue || a.queueFull() {
+		a.stats.Rejected++
+
+		return false, nil
+	}
+
+	a.stats.Waiting++
+	a.stats.Queued++
+
+	waiter = make(chan struct{}, 1)
+	a.waiters = append(a.waiters, waiter)
+
+	return false, waiter
+}
+
+func (a *admission) queueFull() bool {
+	size := a.config.QueueSize
+	if size <= 0 {
+		size = a.config.MaxConcurrent
+	}
+
+	return size > 0 && a.stats.Waiting >= size
+}
+
+func (a *admission) queueTimeout() time.Duration {
+	if a.config.QueueTimeout > 0 {
+		return a.config.QueueTimeout
+	}
+
+	return DefaultAdmissionQueueTimeout
+}
+
+// wait waits for the queued call of waiter to be admitted. It is not, and
+// leaves the queue, on timeout, when ctx is done or when hangup is closed
+// (abandoned: the caller hung up).
+func (a *admission) wait(ctx context.Context, waiter chan struct{}, hangup <-chan struct{}) (admitted, abandoned bool) {
+	a.Lock()
+	timer := time.NewTimer(a.queueTimeout())
+	a.Unlock()
+
+	defer timer.Stop()
+
+	for {
+		var (
+			ok    bool
+			delay time.Duration
+		)
+
+		a.Lock()
+
+		if len(a.waiters) > 0 && a.waiters[0] == waiter {
+			if ok, delay = a.take(); ok {
+				a.leave(waiter)
+			}
+		}
+
+		a.Unlock()
+
+		if ok {
+			return true, false
+		}
+
+		if delay <= 0 {
+			// not first, or no handler free: wait to be woken up
+			delay = a.queueTimeout()
+		}
+
+		retry := time.NewTimer(delay)
+
+		select {
+		case <-waiter:
+		case <-retry.C:
+		case <-timer.C:
+			retry.Stop()
+			a.reject(waiter)
+
+			return false, false
+		case <-ctx.Done():
+			retry.Stop()
+			a.reject(waiter)
+
+			return false, false
+		case <-hangup:
+			retry.Stop()
+			a.abandon(waiter)
+
+			return false, true
+		}
+
+		retry.Stop()
+	}
+}
+
+// leave removes waiter from the queue and wakes the next one up, the lock is held
+func (a *admission) leave(waiter chan struct{}) {
+	for i := range a.waiters {
+		if a.waiters[i] == waiter {
+			a.waiters = append(a.waiters[:i:i], a.waiters[i+1:]...)
+
+			break
+		}
+	}
+
+	a.stats.Waiting--
+	a.wakeUp()
+}
+
+// wakeUp wakes the first queued call up, the lock is held
+func (a *admission) wakeUp() {
+	if len(a.waiters) == 0 {
+		return
+	}
+
+	select {
+	case a.waiters[0] <- struct{}{}:
+	default:
+	}
+}
+
+// reject counts a rejected call, that left the queue if waiter is set
+func (a *admission) reject(waiter chan struct{}) {
+	a.Lock()
+
+	if waiter != nil {
+		a.leave(waiter)
+	}
+
+	a.stats.Rejected++
+
+	a.Unlock()
+}
+
+// abandon counts a queued call hung up by the caller
+func (a *admission) abandon(waiter chan struct{}) {
+	a.Lock()
+
+	a.leave(waiter)
+	a.stats.Abandoned++
+
+	a.Unlock()
+}
+
+// done ends an admitted call handler
+func (a *admission) done() {
+	a.Lock()
+
+	a.stats.Running--
+	a.wakeUp()
+
+	a.Unlock()
+}
+
+func (a *admission) get() AdmissionStats {
+	a.Lock()
+	defer a.Unlock()
+
+	return a.stats
+}
+
+// admitCall runs OnIncomingCall for call once admitted, or turns the call
+// away, from a go routine of its own. The handler is counted (handlers).
+func (consumer *Consumer) admitCall(ctx context.Context, call *CallSession) {
+	ok, waiter := consumer.admission.admit()
+
+	go func() {
+		defer consumer.handlers.end()
+
+		switch {
+		case ok:
+			consumer.runAdmitted(ctx, call)
+		case waiter != nil:
+			consumer.logger().Debug("call [%s] queued, waiting for a handler\n", call.GetCallID())
+
+			admitted, abandoned := consumer.admission.wait(ctx, waiter, call.Hangup)
+
+			switch {
+			case abandoned:
+				consumer.logger().Debug("call [%s] hung up while queued\n", call.GetCallID())
+
+				return
+			case !admitted:
+				consumer.logger().Warn("call [%s] not admitted from the queue, rejected\n", call.GetCallID())
+				consumer.rejectCall(ctx, call)
+
+				return
+			}
+
+			consumer.runAdmitted(ctx, call)
+		default:
+			consumer.overflowCall(ctx, call)
+		}
+	}()
+}
+
+// runAdmitted runs the handler of an admitted call and frees its slot
+func (consumer *Consumer) runAdmitted(ctx context.Context, call *CallSession) {
+	defer consumer.admission.done()
+
+	consumer.runOnIncomingCall(ctx, call)
+}
+
+// overflowCall turns away a call that was not admitted
+func (consumer *Consumer) overflowCall(ctx context.Context, call *CallSession) {
+	consumer.admission.Lock()
+	cfg := consumer.admission.config
+	consumer.admission.Unlock()
+
+	consumer.logger().Warn("call [%s] over the admission limits, overflow: %s\n", call.GetCallID(), cfg.Overflow)
+
+	if cfg.Overflow != AdmissionBusy {
+		consumer.rejectCall(ctx, call)
+
+		return
+	}
+
+	c := consumer.newCallObj(call)
+
+	if _, err := c.Answer(); err != nil {
+		consumer.logger().Debug("call [%s]: cannot answer: %v\n", call.GetCallID(), err)
+
+		return
+	}
+
+	msg := cfg.BusyMessage
+	if len(msg) == 0 {
+		msg = DefaultBusyMessage
+	}
+
+	if _, err := c.PlayTTS(msg, "", ""); err != nil {
+		consumer.logger().Debug("call [%s]: cannot play the busy message: %v\n", call.GetCallID(), err)
+	}
+
+	if _, err := c.Hangup(); err != nil {
+		consumer.logger().Debug("call [%s]: cannot hang up: %v\n", call.GetCallID(), err)
+	}
+}
+
+// rejectCall ends a call not answered, with the busy reason. Once ctx is
+// done (the consumer is stopping), it is ended with a context of its own.
+func (consumer *Consumer) rejectCall(ctx context.Context, call *CallSession) {
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(context.Background(), admissionRejectTimeout)
+		defer cancel()
+	}
+
+	if err := consumer.Client.Calling.Relay.relayCallEnd(ctx, call, "busy", nil); err != nil {
+		consumer.logger().Debug("call [%s]: cannot reject: %v\n", call.GetCallID(), err)
+	}
+}
+
+// AdmissionStats returns the metrics of the admission of the inbound calls
+func (consumer *Consumer) AdmissionStats() AdmissionStats {
+	return consumer.admission.get()
+}
@@ -0,0 +1,130 @@
+package signalwire
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestAdmission(t *testing.T) {
+	t.Run(
+		"Rate",
+		func(t *testing.T) {
+			var a admission
+
+			a.setConfig(&AdmissionConfig{Rate: 10, Burst: 2})
+
+			for i := 0; i < 2; i++ {
+				ok, _ := a.admit()
+				assert.True(t, ok, "the burst must be admitted")
+			}
+
+			ok, waiter := a.admit()
+			assert.False(t, ok, "above the rate")
+			assert.Nil(t, waiter, "rejected")
+
+			time.Sleep(110 * time.Millisecond)
+
+			ok, _ = a.admit()
+			assert.True(t, ok, "a token must be back")
+
+			assert.Equal(t, AdmissionStats{Admitted: 3, Rejected: 1, Running: 3}, a.get())
+		},
+	)
+	t.Run(
+		"QueueTimeout",
+		func(t *testing.T) {
+			var a admission
+
+			a.setConfig(&AdmissionConfig{MaxConcurrent: 1, Overflow: AdmissionQueue, QueueTimeout: 20 * time.Millisecond})
+
+			ok, _ := a.admit()
+			assert.True(t, ok)
+
+			ok, waiter := a.admit()
+			assert.False(t, ok)
+			assert.NotNil(t, waiter)
+
+			admitted, abandoned := a.wait(context.Background(), waiter, nil)
+			assert.False(t, admitted, "no handler freed in time")
+			assert.False(t, abandoned)
+
+			assert.Equal(t, AdmissionStats{Admitted: 1, Rejected: 1, Queued: 1, Running: 1}, a.get())
+		},
+	)
+	t.Run(
+		"QueueInOrder",
+		func(t *testing.T) {
+			var a admission
+
+			a.setConfig(&AdmissionConfig{MaxConcurrent: 1, Overflow: AdmissionQueue, QueueSize: 3})
+
+			ok, _ := a.admit()
+			assert.True(t, ok)
+
+			admitted := make(chan int, 3)
+
+			for i := 0; i < 3; i++ {
+				ok, waiter := a.admit()
+				assert.False(t, ok)
+
+				go func(i int) {
+					if ok, _ := a.wait(context.Background(), waiter, nil); ok {
+						admitted <- i
+					}
+				}(i)
+			}
+
+			for i := 0; i < 3; i++ {
+				a.done()
+
+				select {
+				case got := <-admitted:
+					assert.Equal(t, i, got, "the queued calls must be admitted first in first out")
+				case <-time.After(time.Second):
+					t.Fatalf("no queued call admitted")
+				}
+			}
+
+			assert.Equal(t, AdmissionStats{Admitted: 4, Queued: 3, Running: 1}, a.get())
+		},
+	)
+	t.Run(
+		"Abandoned",
+		func(t *testing.T) {
+			var a admission
+
+			a.setConfig(&AdmissionConfig{MaxConcurrent: 1, Overflow: AdmissionQueue, QueueSize: 2})
+
+			ok, _ := a.admit()
+			assert.True(t, ok)
+
+			_, first := a.admit()
+			_, second := a.admit()
+
+			hangup := make(chan struct{})
+			close(hangup)
+
+			admitted, abandoned := a.wait(context.Background(), first, hangup)
+			assert.False(t, admitted)
+			assert.True(t, abandoned, "the caller hung up")
+
+			a.done()
+
+			admitted, _ = a.wait(context.Background(), second, nil)
+			assert.True(t, admitted, "the next call must get the handler")
+
+			assert.Equal(t, AdmissionStats{Admitted: 2, Queued: 2, Abandoned: 1, Running: 1}, a.get())
+		},
+	)
+	t.Run(
+		"OverflowString",
+		func(t *testing.T) {
+			assert.Equal(t, "Queue", AdmissionQueue.String())
+			assert.Equal(t, "Unknown", AdmissionOverflow(42).String())
+			assert.Equal(t, "Unknown", AdmissionOverflow(-1).String())
+		},
+	)
+}
@@ -2,7 +2,6 @@ package signalwire
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -14,7 +13,6 @@ import (
 
 	"github.com/gorilla/websocket"
 	jsonrpc2 "github.com/sourcegraph/jsonrpc2"
-	ws "github.com/sourcegraph/jsonrpc2/websocket"
 )
 
 const (
@@ -23,6 +21,10 @@ const (
 	connTimeout  = 15
 )
 
+// ErrSessionLost is set on calls and actions that were live when the Blade
+// session was lost and could not be restored after reconnecting.
+var ErrSessionLost = errors.New("blade session lost on reconnect, call state unknown")
+
 // BladeAuth holds auth data for the WS connection
 type BladeAuth struct {
 	ProjectID string
@@ -46,14 +48,19 @@ const (
 )
 
 func (s SessionState) String() string {
+	if s < BladeOffline || s > BladeShutdown {
+		return "Unknown"
+	}
+
 	return [...]string{"Offline", "Connecting", "Connected", "Setup",
-		"Subscribed", "Running", "Closing", "Closed", "Shutdown"}[s]
+		"Subscribed", "Running", "Closing", "Closed", "Shutdown"}[s-BladeOffline]
 }
 
 // BladeSession cache Session information
 type BladeSession struct {
 	BladeHandlerIncoming ReqHandler
-	Calls                [MaxSimCalls]CallSession
+	Calls                *CallRegistry
+	CallRegistry         *CallRegistryConfig
 	RemoteAddress        url.URL
 	bladeAuth            BladeAuth
 	EventCalling         EventCalling
@@ -62,6 +69,28 @@ type BladeSession struct {
 	LastJRPCError        JError
 	SignalwireChannels   []string
 	SignalwireContexts   []string
+	Transport            *TransportConfig
+	ReconnectPolicy      *ReconnectPolicy
+	OutboundQueue        *OutboundQueue
+	Credentials          CredentialsProvider
+	WireRecorder         *WireRecorder
+	Failover             *FailoverPolicy
+	EventBus             *EventBusConfig
+	Interceptors         *Interceptors
+	Store                Store
+	StoreTTL             time.Duration
+	Log                  LoggerWrapper
+	outbound             outboundQueue
+	hosts                hostList
+	contextsMutex        sync.Mutex
+	health               linkHealth
+	eventStats           eventCounters
+	actionStats          actionCounters
+	auth                 bladeAuthState
+	onStateChange        func(ConnectionStateChange)
+	onOrphanedCalls      func([]*CallSession)
+	rawEvents            *rawEvents
+	sessionControl       *BladeSessionControl
 	jOpts                []jsonrpc2.CallOption
 	SessionID            string
 	Protocol             string
@@ -74,6 +103,7 @@ type BladeSession struct {
 	DisconnectChan       chan struct{}
 	Inbound              chan string
 	Netcast              chan string
+	migrate              chan struct{}
 	InboundDone          chan struct{}
 	InboundMsg           chan string
 	InboundMsgDone       chan struct{}
@@ -83,6 +113,7 @@ type BladeSession struct {
 	Tmutex               sync.Mutex
 	Certified            bool
 	WantReconnect        bool
+	SessionRestored      bool
 }
 
 // IBlade TODO DESCRIPTION
@@ -93,10 +124,12 @@ type IBlade interface {
 	BladeWSWatchConn(ctx context.Context)
 	BladeInit(ctx context.Context, addr string) error
 	BladeConnect(ctx context.Context, bladeAuth *BladeAuth) error
+	BladeReauthenticate(ctx context.Context) error
 	BladeSetup(ctx context.Context) error
 	BladeAddSubscription(ctx context.Context, signalwireChannels []string) error
 	BladeExecute(ctx context.Context, v interface{}, res interface{}) (interface{}, error)
 	BladeSignalwireReceive(ctx context.Context, signalwireContexts []string) error
+	BladeSignalwireUnreceive(ctx context.Context, signalwireContexts []string) error
 	BladeWaitDisconnect(ctx context.Context) int
 	BladeDisconnect(ctx context.Context) error
 	BladeWaitInboundCall(ctx context.Context) (*CallSession, error)
@@ -155,6 +188,51 @@ func (blade *BladeSession) GetConnection() (*jsonrpc2.Conn, error) {
 	return conn, nil
 }
 
+// control returns the Control Pool of Sessions of this Blade session
+func (blade *BladeSession) control() *BladeSessionControl {
+	blade.Tmutex.Lock()
+
+	if blade.sessionControl == nil {
+		blade.sessionControl = NewBladeSessionControl()
+	}
+
+	ctrl := blade.sessionControl
+
+	blade.Tmutex.Unlock()
+
+	return ctrl
+}
+
+// GetState returns the state of the Blade session
+func (blade *BladeSession) GetState() SessionState {
+	blade.Tmutex.Lock()
+	state := blade.SessionState
+	blade.Tmutex.Unlock()
+
+	return state
+}
+
+func (blade *BladeSession) setState(state SessionState) {
+	blade.Tmutex.Lock()
+	blade.SessionState = state
+	change, changed := blade.health.transition(state)
+	onStateChange := blade.onStateChange
+	blade.Tmutex.Unlock()
+
+	switch state {
+	case BladeRunning:
+		blade.outbound.resume()
+	case BladeShutdown:
+		blade.outbound.drop(ErrOutboundQueueClosed)
+	default:
+		blade.outbound.hold()
+	}
+
+	if changed && onStateChange != nil {
+		onStateChange(change)
+	}
+}
+
 // BladeCleanup TODO DESCRIPTION
 func (blade *BladeSession) BladeCleanup() error {
 	if blade == nil {
@@ -165,9 +243,9 @@ func (blade *BladeSession) BladeCleanup() error {
 		return errors.New("invalid connection")
 	}
 
-	GlobalBladeSessionControl.removeBlade(blade.conn)
+	blade.control().removeBlade(blade.conn)
 
-	blade.SessionState = BladeClosed
+	blade.setState(BladeClosed)
 
 	return blade.conn.Close()
 }
@@ -178,17 +256,11 @@ func (blade *BladeSession) BladeWSOpenConn(ctx context.Context, u url.URL) (*web
 		return nil, errors.New("empty blade session object")
 	}
 
-	Log.Debug("connecting to %s\n", u.String())
-
-	dialer := websocket.DefaultDialer
+	blade.logger().Debug("connecting to %s\n", u.String())
 
-	dialer.TLSClientConfig = &tls.Config{
-		InsecureSkipVerify: false,
-	}
+	dialer := blade.Transport.dialer()
 
-	dialer.HandshakeTimeout = WSTimeOut * time.Second
-
-	c, _, err := dialer.DialContext(ctx, u.String(), nil)
+	c, _, err := dialer.DialContext(ctx, u.String(), blade.Transport.header())
 
 	if c == nil || err != nil {
 		return nil, err
@@ -201,6 +273,8 @@ func (blade *BladeSession) BladeWSOpenConn(ctx context.Context, u url.URL) (*web
 		blade.Tconn.Reset(timeout)
 		blade.Tmutex.Unlock()
 
+		blade.health.ping()
+
 		return nil
 	})
 
@@ -221,13 +295,10 @@ func (blade *BladeSession) BladeInit(ctx context.Context, addr string) error {
 	}
 
 	blade.Ctx = ctx
-	u := url.URL{
-		Scheme: "wss",
-		Host:   addr,
-		Path:   "/",
-	}
+	u := hostURL(addr)
 
 	blade.RemoteAddress = u
+	blade.health.setHost(addr)
 	c, err := blade.I.BladeWSOpenConn(ctx, u)
 
 	if err != nil {
@@ -240,14 +311,32 @@ func (blade *BladeSession) BladeInit(ctx context.Context, addr string) error {
 		return errors.New("cannot open websocket connection")
 	}
 
-	blade.Tconn = time.NewTimer(connTimeout * time.Second /*15 sec = 3 ping/pong intervals */)
+	// kept across the attempts to connect: the watcher of the previous link may still be reading them
+	blade.Tmutex.Lock()
+
+	if blade.Tconn == nil {
+		blade.Tconn = time.NewTimer(connTimeout * time.Second /*15 sec = 3 ping/pong intervals */)
+	} else {
+		blade.Tconn.Reset(connTimeout * time.Second)
+	}
+
+	blade.Tmutex.Unlock()
+
+	if blade.WatcherDone == nil {
+		blade.WatcherDone = make(chan struct{}, 1)
+		blade.WatcherSync = make(chan struct{}, 1)
+	}
 
-	blade.WatcherDone = make(chan struct{}, 1)
-	blade.WatcherSync = make(chan struct{}, 1)
+	select {
+	case <-blade.WatcherDone:
+	default:
+	}
 
-	stream := ws.NewObjectStream(c)
-	l := new(Jsonrpc2Logger)
+	stream := blade.objectStream(c)
+	l := &Jsonrpc2Logger{Log: blade.logger()}
 
+	blade.BladeHandlerIncoming.ctrl = blade.control()
+	blade.BladeHandlerIncoming.log = blade.logger()
 	blade.conn = jsonrpc2.NewConn(
 		ctx, stream,
 		blade.BladeHandlerIncoming,
@@ -275,8 +364,21 @@ func (blade *BladeSession) BladeInit(ctx context.Context, addr string) error {
 		return err
 	}
 
-	GlobalBladeSessionControl.addBlade(blade.conn, blade)
+	blade.control().addBlade(blade.conn, blade)
+
+	if err = blade.initEvents(); err != nil {
+		return err
+	}
+
+	blade.Netcast = make(chan string)
+	blade.DisconnectChan = make(chan struct{}, 1)
+	blade.migrate = make(chan struct{}, 1)
+
+	return nil
+}
 
+// initEvents sets up the handlers and the caches of the events
+func (blade *BladeSession) initEvents() error {
 	var I IEventCalling = EventCallingNew()
 
 	/*the circular references are for unit-testing.
@@ -288,10 +390,30 @@ func (blade *BladeSession) BladeInit(ctx context.Context, addr string) error {
 	calling.I = calling
 	blade.EventCalling = *calling
 
-	if err = calling.Cache.InitCache(CacheExpiry*time.Second, CacheCleaning*time.Second); err != nil {
+	if blade.Store == nil {
+		blade.Store = NewMemoryStore(CacheCleaning * time.Second)
+	}
+
+	ttl := blade.StoreTTL
+	if ttl <= 0 {
+		ttl = CacheExpiry * time.Second
+	}
+
+	calling.Cache.store = blade.Store
+
+	if err := calling.Cache.InitCache(ttl, CacheCleaning*time.Second); err != nil {
 		return errors.New("failed to initialize cache")
 	}
 
+	calling.Cache.log = blade.logger()
+
+	if blade.Calls == nil {
+		blade.Calls = NewCallRegistry(blade.CallRegistry)
+	}
+
+	blade.Calls.log = blade.logger()
+	calling.Cache.calls = blade.Calls
+
 	blade.EventCalling = *calling
 	blade.EventCalling.blade = blade
 
@@ -302,10 +424,14 @@ func (blade *BladeSession) BladeInit(ctx context.Context, addr string) error {
 	messaging.I = messaging
 	blade.EventMessaging = *messaging
 
-	if err = messaging.Cache.InitCache(CacheExpiry*time.Second, CacheCleaning*time.Second); err != nil {
+	messaging.Cache.store = blade.Store
+
+	if err := messaging.Cache.InitCache(ttl, CacheCleaning*time.Second); err != nil {
 		return errors.New("failed to initialize cache")
 	}
 
+	messaging.Cache.log = blade.logger()
+
 	blade.EventMessaging = *messaging
 	blade.EventMessaging.blade = blade
 
@@ -316,17 +442,18 @@ func (blade *BladeSession) BladeInit(ctx context.Context, addr string) error {
 	tasking.I = tasking
 	blade.EventTasking = *tasking
 
-	if err = tasking.Cache.InitCache(CacheExpiry*time.Second, CacheCleaning*time.Second); err != nil {
+	tasking.Cache.store = blade.Store
+
+	if err := tasking.Cache.InitCache(ttl, CacheCleaning*time.Second); err != nil {
 		return errors.New("failed to initialize cache")
 	}
 
+	tasking.Cache.log = blade.logger()
+
 	blade.EventTasking = *tasking
 
 	blade.EventTasking.blade = blade
 
-	blade.Netcast = make(chan string)
-	blade.DisconnectChan = make(chan struct{})
-
 	return nil
 }
 
@@ -336,48 +463,58 @@ func (blade *BladeSession) BladeReconnect(ctx context.Context, u url.URL) {
 	blade.LastError = err
 
 	if c != nil {
-		Log.Debug("Reconnected.\n")
+		blade.logger().Debug("Reconnected.\n")
 		blade.Tmutex.Lock()
 		blade.Tconn.Reset(connTimeout * time.Second)
 		blade.Tmutex.Unlock()
 
-		go blade.I.BladeWSWatchConn(ctx)
-
-		stream := ws.NewObjectStream(c)
-		l := new(Jsonrpc2Logger)
+		stream := blade.objectStream(c)
+		l := &Jsonrpc2Logger{Log: blade.logger()}
 
-		blade.Tmutex.Lock()
-
-		blade.conn = jsonrpc2.NewConn(
+		blade.BladeHandlerIncoming.ctrl = blade.control()
+		blade.BladeHandlerIncoming.log = blade.logger()
+		conn := jsonrpc2.NewConn(
 			ctx, stream,
 			blade.BladeHandlerIncoming,
 			jsonrpc2.LogMessages(l),
 		)
 
+		blade.Tmutex.Lock()
+		blade.conn = conn
 		blade.Tmutex.Unlock()
 
-		GlobalBladeSessionControl.addBlade(blade.conn, blade)
+		blade.control().addBlade(conn, blade)
+
+		go blade.I.BladeWSWatchConn(ctx)
+		<-blade.WatcherSync
 	}
 }
 
 // BladeWSWatchConn TODO DESCRIPTION
 func (blade *BladeSession) BladeWSWatchConn(ctx context.Context) {
+	conn, _ := blade.GetConnection()
+
 	blade.WatcherSync <- struct{}{}
 
-	if blade.conn == nil || blade.Tconn == nil {
+	if conn == nil || blade.Tconn == nil {
 		return
 	}
 
 	select {
 	case <-blade.Tconn.C:
+		blade.logger().Debug("missed 3 pings from server, will reconnect.\n")
+
+		blade.Tmutex.Lock()
+		blade.WantReconnect = true
+		blade.Tmutex.Unlock()
 	case <-blade.WatcherDone:
+	case <-conn.DisconnectNotify():
+		// the link is gone, the connection that replaces it gets its own watcher
+		return
 	}
-	Log.Debug("missed 3 pings from server, will reconnect.\n")
-
-	blade.WantReconnect = true
 
-	blade.conn.Close()
-	GlobalBladeSessionControl.removeBlade(blade.conn)
+	conn.Close()
+	blade.control().removeBlade(conn)
 }
 
 // jsonrpc2ErrorCreate: helper function to create code/message jsonrpc2-like errors from the error string that the library provides.
@@ -405,27 +542,14 @@ func (blade *BladeSession) BladeConnect(ctx context.Context, bladeAuth *BladeAut
 		return errors.New("invalid connection")
 	}
 
-	var (
-		projID string
-		tokID  string
-	)
-
-	if bladeAuth == nil {
-		projID = blade.bladeAuth.ProjectID
-		tokID = blade.bladeAuth.TokenID
-	} else {
-		projID = bladeAuth.ProjectID
-		tokID = bladeAuth.TokenID
-	}
-
-	if len(projID) == 0 || len(tokID) == 0 {
-		err := fmt.Errorf("no auth")
+	auth, err := blade.authentication(ctx, bladeAuth)
+	if err != nil {
 		blade.LastError = err
 
 		return err
 	}
 
-	blade.SessionState = BladeConnecting
+	blade.setState(BladeConnecting)
 
 	var ReplyConnectDecode ReplyResultConnect
 
@@ -447,12 +571,9 @@ func (blade *BladeSession) BladeConnect(ctx context.Context, bladeAuth *BladeAut
 				Minor:    BladeVersionMinor,
 				Revision: BladeRevision,
 			},
-			SessionID: blade.SessionID,
-			Authentication: AuthStruct{
-				Project: bladeAuth.ProjectID,
-				Token:   bladeAuth.TokenID,
-			},
-			Agent: fmt.Sprintf("%s/%s", UserAgent, SDKVersion),
+			SessionID:      blade.SessionID,
+			Authentication: auth,
+			Agent:          fmt.Sprintf("%s/%s", UserAgent, SDKVersion),
 		},
 		&ReplyConnectDecode, blade.jOpts...,
 	); err != nil {
@@ -463,9 +584,17 @@ func (blade *BladeSession) BladeConnect(ctx context.Context, bladeAuth *BladeAut
 		return err
 	}
 
-	blade.SessionState = BladeConnected
+	blade.setState(BladeConnected)
 
-	Log.Debug("reply ReplyBladeConnect: %v\n", ReplyConnectDecode)
+	// keep the session ID assigned by the platform, it is presented again on reconnect to restore the session
+	blade.SessionRestored = ReplyConnectDecode.SessionRestored
+	if len(ReplyConnectDecode.SessionID) > 0 {
+		blade.SessionID = ReplyConnectDecode.SessionID
+	}
+
+	blade.setAuthorization(ReplyConnectDecode.Authorization, len(auth.JWTToken) > 0)
+
+	blade.logger().Debug("reply ReplyBladeConnect: %v\n", ReplyConnectDecode)
 
 	return nil
 }
@@ -476,8 +605,11 @@ func (blade *BladeSession) BladeDisconnect(ctx context.Context) error {
 		return errors.New("empty blade session object")
 	}
 
+	blade.Tmutex.Lock()
 	blade.WantReconnect = false
-	blade.SessionState = BladeClosing
+	blade.Tmutex.Unlock()
+
+	blade.setState(BladeClosing)
 
 	if blade.conn == nil {
 		return errors.New("invalid connection")
@@ -495,9 +627,9 @@ func (blade *BladeSession) BladeDisconnect(ctx context.Context) error {
 		return err
 	}
 
-	blade.SessionState = BladeShutdown
+	blade.setState(BladeShutdown)
 
-	Log.Debug("reply ReplyDisconnectDecode: %v\n", ReplyDisconnectDecode)
+	blade.logger().Debug("reply ReplyDisconnectDecode: %v\n", ReplyDisconnectDecode)
 
 	return nil
 }
@@ -528,8 +660,8 @@ func (blade *BladeSession) BladeSetup(ctx context.Context) error {
 
 	blade.Protocol = r.Result.Protocol
 
-	Log.Debug("reply ReplySetupDecode: %v\n", ReplySetupDecode)
-	Log.Debug("reply Reply r: %v\n", r)
+	blade.logger().Debug("reply ReplySetupDecode: %v\n", ReplySetupDecode)
+	blade.logger().Debug("reply Reply r: %v\n", r)
 
 	return nil
 }
@@ -570,7 +702,7 @@ func (blade *BladeSession) BladeAddSubscription(ctx context.Context, signalwireC
 		return err
 	}
 
-	Log.Debug("reply ReplySubscriptionDecode: %v\n", ReplySubscriptionDecode)
+	blade.logger().Debug("reply ReplySubscriptionDecode: %v\n", ReplySubscriptionDecode)
 
 	return nil
 }
@@ -587,7 +719,26 @@ func (blade *BladeSession) BladeExecute(ctx context.Context, v interface{}, res
 		return nil, errors.New("empty blade session object")
 	}
 
-	if blade.conn == nil {
+	if blade.Interceptors != nil && len(blade.Interceptors.Outgoing) > 0 {
+		return blade.interceptExecute(ctx, blade.Interceptors.Outgoing, v, res)
+	}
+
+	return blade.execute(ctx, v, res)
+}
+
+// execute sends a blade.execute
+func (blade *BladeSession) execute(ctx context.Context, v interface{}, res interface{}) (interface{}, error) {
+	if !outboundBypass(ctx) {
+		release, err := blade.outbound.acquire(ctx, blade.OutboundQueue)
+		if err != nil {
+			return nil, err
+		}
+
+		defer release()
+	}
+
+	conn, _ := blade.GetConnection()
+	if conn == nil {
 		return nil, errors.New("invalid connection")
 	}
 
@@ -598,41 +749,51 @@ func (blade *BladeSession) BladeExecute(ctx context.Context, v interface{}, res
 		IsString: true,
 	}
 
-	blade.jOpts = nil
-	blade.jOpts = append(blade.jOpts, jsonrpc2.PickID(id))
-
 	if debugJSONRPC {
 		placeholder := new(placeHolderCmd)
 
 		b, err := json.Marshal(v)
 		if err != nil {
-			Log.Error("payload: cannot marshal")
+			blade.logger().Error("payload: cannot marshal")
 		}
 
 		err = json.Unmarshal(b, placeholder)
 		if err != nil {
-			Log.Error("payload: cannot unmarshal to RawMessage")
+			blade.logger().Error("payload: cannot unmarshal to RawMessage")
 		}
 
 		bp, err := json.MarshalIndent(&placeholder, "", "\t")
 		if err != nil {
-			Log.Error("error:", err)
+			blade.logger().Error("error:", err)
 		}
 
-		Log.Debug("blade.execute params: %s\n", bp)
+		blade.logger().Debug("blade.execute params: %s\n", bp)
 	}
 
-	if err := blade.conn.Call(ctx, "blade.execute", v, res, blade.jOpts...); err != nil {
+	start := time.Now()
+
+	if err := conn.Call(ctx, "blade.execute", v, res, jsonrpc2.PickID(id)); err != nil {
 		blade.LastError = err
 
 		return nil, err
 	}
 
+	blade.health.execute(time.Since(start))
+
 	return res, nil
 }
 
 // BladeSignalwireReceive TODO DESCRIPTION
 func (blade *BladeSession) BladeSignalwireReceive(ctx context.Context, signalwireContexts []string) error {
+	return blade.signalwireContexts(ctx, "signalwire.receive", signalwireContexts)
+}
+
+// BladeSignalwireUnreceive stops the inbound calls and messages of the contexts
+func (blade *BladeSession) BladeSignalwireUnreceive(ctx context.Context, signalwireContexts []string) error {
+	return blade.signalwireContexts(ctx, "signalwire.unreceive", signalwireContexts)
+}
+
+func (blade *BladeSession) signalwireContexts(ctx context.Context, method string, signalwireContexts []string) error {
 	if blade == nil {
 		return errors.New("empty blade session object")
 	}
@@ -641,7 +802,7 @@ func (blade *BladeSession) BladeSignalwireReceive(ctx context.Context, signalwir
 
 	v := ParamsBladeExecuteStruct{
 		Protocol: blade.Protocol,
-		Method:   "signalwire.receive",
+		Method:   method,
 		Params: ParamsSignalwireReceive{
 			Contexts: signalwireContexts,
 		},
@@ -657,11 +818,15 @@ func (blade *BladeSession) BladeSignalwireReceive(ctx context.Context, signalwir
 		return errors.New("type assertion failed")
 	}
 
+	if isScopeErrorCode(r.Result.Code) {
+		return &ScopeError{Contexts: signalwireContexts, Code: r.Result.Code, Message: r.Result.Message}
+	}
+
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
 	}
 
-	Log.Debug("r.Result.Message: [%s]\n", r.Result.Message)
+	blade.logger().Debug("r.Result.Message: [%s]\n", r.Result.Message)
 
 	return nil
 }
@@ -682,12 +847,12 @@ func (blade *BladeSession) handleBladeBroadcast(ctx context.Context, req *jsonrp
 		return err
 	}
 
-	Log.Debug("broadcast.Event: %v\n", broadcast.Event)
-	Log.Debug("broadcast.Channel: %v\n", broadcast.Channel)
-	Log.Debug("broadcast.Params.EventType: %v\n", broadcast.Params.EventType)
-	Log.Debug("broadcast.Params.Params: %v\n", broadcast.Params.Params)
+	blade.logger().Debug("broadcast.Event: %v\n", broadcast.Event)
+	blade.logger().Debug("broadcast.Channel: %v\n", broadcast.Channel)
+	blade.logger().Debug("broadcast.Params.EventType: %v\n", broadcast.Params.EventType)
+	blade.logger().Debug("broadcast.Params.Params: %v\n", broadcast.Params.Params)
 
-	return blade.eventNotif(ctx, broadcast, req.Params)
+	return blade.interceptBroadcast(ctx, &broadcast, req.Params)
 }
 
 // HandleBladeNetcast TODO DESCRIPTION
@@ -718,6 +883,7 @@ func (blade *BladeSession) handleBladeNetcast(_ context.Context, req *jsonrpc2.R
 		default:
 		}
 	case "protocol.remove":
+		blade.protocolRemoved(netcast.Params.Protocol)
 	case "protocol.provider.add":
 	case "protocol.provider.remove":
 	case "protocol.provider.rank.update":
@@ -736,7 +902,7 @@ func (blade *BladeSession) handleBladeNetcast(_ context.Context, req *jsonrpc2.R
 	case "access.remove":
 	}
 
-	Log.Debug("netcast.Command: %v %p\n", netcast.Command, blade)
+	blade.logger().Debug("netcast.Command: %v %p\n", netcast.Command, blade)
 
 	return nil
 }
@@ -747,10 +913,15 @@ func (blade *BladeSession) handleBladeDisconnect(_ context.Context, c *jsonrpc2.
 		return errors.New("empty blade session object")
 	}
 
-	Log.Debug("handleBladeDisconnect conn [%p] [%p]\n", c, blade)
+	blade.logger().Debug("handleBladeDisconnect conn [%p] [%p]\n", c, blade)
+
+	if blade.linkLost() {
+		// the node is going away: reconnect, presenting the session to restore it
+		blade.logger().Info("disconnected by the server, reconnecting\n")
 
-	if blade.SessionState == BladeConnecting || blade.SessionState == BladeRunning {
-		blade.SessionState = BladeClosing
+		blade.reconnectSession(false)
+
+		return nil
 	}
 
 	return blade.I.BladeCleanup()
@@ -806,40 +977,44 @@ func (ctrl *BladeSessionControl) removeBlade(c *jsonrpc2.Conn) {
 
 // ReqHandler TODO DESCRIPTION
 type ReqHandler struct {
+	ctrl *BladeSessionControl
+	log  LoggerWrapper
 }
 
 // Handle JSONRPC2.0 reply handler
-func (ReqHandler) Handle(ctx context.Context, c *jsonrpc2.Conn, req *jsonrpc2.Request) {
-	blade := GlobalBladeSessionControl.getBlade(c)
+func (h ReqHandler) Handle(ctx context.Context, c *jsonrpc2.Conn, req *jsonrpc2.Request) {
+	blade := h.ctrl.getBlade(c)
 	if blade == nil {
-		Log.Warn("no Blade session for this connection\n")
+		h.logger().Warn("no Blade session for this connection\n")
 
 		return
 	}
 
+	blade.logger().Debug("got %s conn [%p]\n", req.Method, c)
+
+	blade.handleRequest(ctx, req)
+}
+
+// handleRequest runs the handler of a request from Blade
+func (blade *BladeSession) handleRequest(ctx context.Context, req *jsonrpc2.Request) {
 	switch req.Method {
 	case "blade.broadcast":
-		Log.Debug("got blade.broadcast conn [%p]\n", c)
 
 		if err := blade.I.handleBladeBroadcast(ctx, req); err != nil {
-			Log.Error("HandleBladeBroadcast err: %s\n", err)
+			blade.logger().Error("HandleBladeBroadcast err: %s\n", err)
 		}
 	case "blade.netcast":
-		Log.Debug("got blade.netcast conn [%p]\n", c)
-
 		if err := blade.I.handleBladeNetcast(ctx, req); err != nil {
-			Log.Error("HandleBladeNetcast err: %s\n", err)
+			blade.logger().Error("HandleBladeNetcast err: %s\n", err)
 		}
 	case "blade.disconnect":
-		Log.Debug("got blade.disconnect conn [%p]\n", c)
-
 		if err := blade.I.handleBladeDisconnect(ctx, req); err != nil {
-			Log.Error("HandleBladeDisconnect err: %s\n", err)
+			blade.logger().Error("HandleBladeDisconnect err: %s\n", err)
 		}
 	}
 
-	if debugJSONRPC {
-		Log.Debug("%s: %s\n", req.ID, *req.Params)
+	if debugJSONRPC && req.Params != nil {
+		blade.logger().Debug("%s: %s\n", req.ID, *req.Params)
 	}
 }
 
@@ -847,59 +1022,115 @@ func (ReqHandler) Handle(ctx context.Context, c *jsonrpc2.Conn, req *jsonrpc2.Re
 func (blade *BladeSession) BladeWaitDisconnect(ctx context.Context) int {
 	conn, err := blade.GetConnection()
 	if err != nil {
-		Log.Error("%v\n", err)
+		blade.logger().Error("%v\n", err)
 
 		return -1
 	}
 
 	if conn == nil {
-		Log.Error("Connection is nil\n")
+		blade.logger().Error("Connection is nil\n")
 
 		return -1
 	}
 
 	select {
 	case <-conn.DisconnectNotify(): // remote disconnect
-		Log.Debug("disconnected\n") // this comes when calling ws.Close() too.
+		blade.logger().Debug("disconnected\n") // this comes when calling ws.Close() too.
+
+		blade.Tmutex.Lock()
+		wantReconnect := blade.WantReconnect
+		blade.Tmutex.Unlock()
 
-		if blade.WantReconnect {
+		if wantReconnect || blade.linkLost() {
 			//			conn.Close()
 			blade.Tmutex.Lock()
 			blade.conn = nil
 			blade.Tmutex.Unlock()
 
+			blade.setState(BladeConnecting)
+
+			attempts := blade.ReconnectPolicy.start(blade.logger())
+
 			for {
-				Log.Debug("Reconnecting...")
-				blade.BladeReconnect(ctx, blade.RemoteAddress)
-				conn, _ = blade.GetConnection()
+				delay, ok := attempts.next(blade.LastError)
+				if !ok {
+					blade.LastError = ErrReconnectGaveUp
+					blade.outbound.drop(ErrReconnectGaveUp)
 
-				if conn != nil {
-					break
+					return -1
+				}
+
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return -1
 				}
 
-				time.Sleep(3 * time.Second)
+				if blade.reconnectHost(ctx) {
+					conn, _ = blade.GetConnection()
+
+					break
+				}
 			}
 
+			blade.health.reconnected()
+
 			return 1
 		}
 	case <-blade.DisconnectChan: // local disconnect
-		Log.Debug("got local disconnect\n")
+		blade.logger().Debug("got local disconnect\n")
+	case <-blade.migrate: // the protocol must be set up again
+		return 2
 	}
 
 	return 0
 }
 
+// linkLost is true if the connection went away without a disconnect from either side
+func (blade *BladeSession) linkLost() bool {
+	state := blade.GetState()
+
+	switch state {
+	case BladeClosing, BladeClosed, BladeShutdown, BladeOffline:
+		return false
+	}
+
+	return state != 0
+}
+
+// BladeFailCalls ends every known call with err, used when the session could not be restored
+func (blade *BladeSession) BladeFailCalls(err error) {
+	calls, cerr := blade.EventCalling.Cache.GetAllCallsCache()
+	if cerr != nil {
+		blade.logger().Error("cannot get calls: %v\n", cerr)
+
+		return
+	}
+
+	for _, call := range calls {
+		blade.logger().Debug("failing call [%s] tag [%s]: %v\n", call.GetCallID(), call.GetTagID(), err)
+
+		call.Fail(err)
+
+		_ = blade.EventCalling.Cache.RemoveCallCache(call)
+	}
+
+	if len(calls) > 0 && blade.onOrphanedCalls != nil {
+		blade.onOrphanedCalls(calls)
+	}
+}
+
 func (blade *BladeSession) handleInboundCall(_ context.Context, callID string) bool {
-	Log.Debug("handleInboundCall callID: %s\n", callID)
+	blade.logger().Debug("handleInboundCall callID: %s\n", callID)
 
 	// new inbound call
 	select {
 	case blade.Inbound <- callID:
-		Log.Debug("sent callID to Inbound handler go routine\n")
+		blade.logger().Debug("sent callID to Inbound handler go routine\n")
 		return true
 	default:
 		// channel not open - we're not waiting for inbound calls
-		Log.Debug("no new call signal sent\n")
+		blade.logger().Debug("no new call signal sent\n")
 	}
 
 	return false
@@ -953,6 +1184,8 @@ func (blade *BladeSession) eventNotif(ctx context.Context, broadcast NotifParams
 	messaging := blade.EventMessaging
 	tasking := blade.EventTasking
 
+	subscribed := blade.rawEvents.dispatch(broadcast, rawEvent)
+
 	switch broadcast.Event {
 	case "queuing.relay.events":
 		switch broadcast.Params.EventType {
@@ -997,7 +1230,7 @@ func (blade *BladeSession) eventNotif(ctx context.Context, broadcast NotifParams
 				return err
 			}
 		default:
-			Log.Debug("got event_type %s\n", broadcast.Params.EventType)
+			blade.logger().Debug("got event_type %s\n", broadcast.Params.EventType)
 		}
 	case "queuing.relay.messaging":
 		switch broadcast.Params.EventType {
@@ -1010,16 +1243,20 @@ func (blade *BladeSession) eventNotif(ctx context.Context, broadcast NotifParams
 				return err
 			}
 		default:
-			Log.Debug("got event_type %s\n", broadcast.Params.EventType)
+			blade.logger().Debug("got event_type %s\n", broadcast.Params.EventType)
 		}
 	case "queuing.relay.tasks":
 		if err := tasking.onTaskingEvent(ctx, broadcast); err != nil {
 			return err
 		}
 	case "relay":
-		Log.Debug("got RELAY event\n")
+		blade.logger().Debug("got RELAY event\n")
 	default:
-		Log.Debug("got event %s . unsupported\n", broadcast.Event)
+		if subscribed {
+			return nil
+		}
+
+		blade.logger().Debug("got event %s . unsupported\n", broadcast.Event)
 
 		return fmt.Errorf("unsupported event")
 	}
@@ -1028,15 +1265,15 @@ func (blade *BladeSession) eventNotif(ctx context.Context, broadcast NotifParams
 }
 
 func (blade *BladeSession) handleInboundMessage(_ context.Context, msgID string) bool {
-	Log.Debug("handleInboundMessage callID: %s\n", msgID)
+	blade.logger().Debug("handleInboundMessage callID: %s\n", msgID)
 
 	// new inbound msg
 	select {
 	case blade.InboundMsg <- msgID:
-		Log.Debug("sent msgID to InboundMsg handler go routine\n")
+		blade.logger().Debug("sent msgID to InboundMsg handler go routine\n")
 		return true
 	default:
-		Log.Debug("no new msg signal sent\n")
+		blade.logger().Debug("no new msg signal sent\n")
 	}
 
 	return false
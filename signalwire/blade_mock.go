@@ -121,6 +121,20 @@ func (mr *MockIBladeMockRecorder) BladeConnect(ctx, bladeAuth interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BladeConnect", reflect.TypeOf((*MockIBlade)(nil).BladeConnect), ctx, bladeAuth)
 }
 
+// BladeReauthenticate mocks base method
+func (m *MockIBlade) BladeReauthenticate(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BladeReauthenticate", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BladeReauthenticate indicates an expected call of BladeReauthenticate
+func (mr *MockIBladeMockRecorder) BladeReauthenticate(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BladeReauthenticate", reflect.TypeOf((*MockIBlade)(nil).BladeReauthenticate), ctx)
+}
+
 // BladeSetup mocks base method
 func (m *MockIBlade) BladeSetup(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -178,6 +192,20 @@ func (mr *MockIBladeMockRecorder) BladeSignalwireReceive(ctx, signalwireContexts
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BladeSignalwireReceive", reflect.TypeOf((*MockIBlade)(nil).BladeSignalwireReceive), ctx, signalwireContexts)
 }
 
+// BladeSignalwireUnreceive mocks base method
+func (m *MockIBlade) BladeSignalwireUnreceive(ctx context.Context, signalwireContexts []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BladeSignalwireUnreceive", ctx, signalwireContexts)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BladeSignalwireUnreceive indicates an expected call of BladeSignalwireUnreceive
+func (mr *MockIBladeMockRecorder) BladeSignalwireUnreceive(ctx, signalwireContexts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BladeSignalwireUnreceive", reflect.TypeOf((*MockIBlade)(nil).BladeSignalwireUnreceive), ctx, signalwireContexts)
+}
+
 // BladeWaitDisconnect mocks base method
 func (m *MockIBlade) BladeWaitDisconnect(ctx context.Context) int {
 	m.ctrl.T.Helper()
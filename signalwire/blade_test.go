@@ -3,6 +3,7 @@ package signalwire
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -276,4 +277,33 @@ func TestBlade(t *testing.T) {
 			assert.Equal(t, CallInbound, call.Direction, "Direction does not match")
 		},
 	)
+	t.Run(
+		"BladeInitHostsRounds",
+		func(t *testing.T) {
+			mockCtrl := NewController(t)
+			defer mockCtrl.Finish()
+			Imock := NewMockIBlade(mockCtrl)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			errConn := errors.New("connection refused")
+
+			// every round tries both hosts
+			InOrder(
+				Imock.EXPECT().BladeWSOpenConn(ctx, hostURL("a.test")).Return(nil, errConn),
+				Imock.EXPECT().BladeWSOpenConn(ctx, hostURL("b.test")).Return(nil, errConn),
+				Imock.EXPECT().BladeWSOpenConn(ctx, hostURL("a.test")).Return(nil, errConn),
+				Imock.EXPECT().BladeWSOpenConn(ctx, hostURL("b.test")).Return(nil, errConn),
+			)
+
+			blade := &BladeSession{I: Imock}
+			blade.hosts.set([]string{"a.test", "b.test"}, nil)
+
+			for round := 0; round < 2; round++ {
+				err := blade.bladeInitHosts(ctx)
+				assert.Equal(t, errConn, err, "both hosts are down")
+			}
+		},
+	)
 }
@@ -1,10 +1,9 @@
 package signalwire
 
 import (
+	"encoding/json"
 	"errors"
 	"time"
-
-	bladecache "github.com/dossy/go-cache"
 )
 
 const (
@@ -16,166 +15,258 @@ const (
 
 // BCache TODO DESCRIPTION
 type BCache struct {
-	p *bladecache.Cache
+	store Store
+	ttl   time.Duration
+	calls *CallRegistry
+	log   LoggerWrapper
 }
 
-// InitCache TODO DESCRIPTION
+// InitCache sets the cache up, on a MemoryStore unless a store was set. expiry is
+// the TTL of the call records and of the messages, the live calls do not expire.
 func (cache *BCache) InitCache(expiry, clean time.Duration) error {
 	if cache == nil {
 		return errors.New("empty cache object")
 	}
 
-	cache.p = bladecache.New(expiry, clean)
+	if cache.store == nil {
+		cache.store = NewMemoryStore(clean)
+	}
+
+	cache.ttl = expiry
+
+	if cache.calls == nil {
+		cache.calls = NewCallRegistry(nil)
+	}
 
 	return nil
 }
 
-// SetCallCache TODO DESCRIPTION
-func (cache *BCache) SetCallCache(callID string, sess *CallSession) error {
+func (cache *BCache) check() error {
 	if cache == nil {
 		return errors.New("empty cache object")
 	}
 
-	if cache.p == nil {
+	if cache.store == nil {
 		return errors.New("cache not initialized")
 	}
 
-	if sess == nil {
-		return errors.New("empty session object")
+	return nil
+}
+
+// SetCallCache TODO DESCRIPTION
+func (cache *BCache) SetCallCache(callID string, sess *CallSession) error {
+	if err := cache.check(); err != nil {
+		return err
 	}
 
-	cache.p.Set(callID, sess, CacheExpiry*time.Second)
+	if err := cache.calls.Set(callID, sess); err != nil {
+		return err
+	}
 
-	return nil
+	// no TTL: a live call stays until RemoveCallCache
+	return cache.store.Set(StoreCallPrefix+callID, sess, 0)
 }
 
-// GetCallCache TODO DESCRIPTION
+// GetCallCache returns the live call under callID (a call ID or a tag), from
+// the store or else from the CallRegistry, nil if none
 func (cache *BCache) GetCallCache(callID string) (*CallSession, error) {
-	if cache == nil {
-		return nil, errors.New("empty cache object")
+	if err := cache.check(); err != nil {
+		return nil, err
 	}
 
-	if cache.p == nil {
-		return nil, errors.New("cache not initialized")
-	}
-
-	if v, found := cache.p.Get(callID); found {
-		if _, ok := v.(*CallSession); !ok {
-			return nil, errors.New("wrong cache data type")
+	// ended calls are records
+	if v, found := cache.store.Get(StoreCallPrefix + callID); found {
+		if call, ok := v.(*CallSession); ok {
+			return call, nil
 		}
-
-		return v.(*CallSession), nil
 	}
 
-	return nil, nil
+	return cache.calls.Get(callID), nil
 }
 
 // DeleteCallCache TODO DESCRIPTION
 func (cache *BCache) DeleteCallCache(callID string) error {
-	if cache == nil {
-		return errors.New("empty cache object")
+	if err := cache.check(); err != nil {
+		return err
 	}
 
-	if cache.p == nil {
-		return errors.New("cache not initialized")
+	cache.calls.Delete(callID)
+
+	return cache.store.Delete(StoreCallPrefix + callID)
+}
+
+// RemoveCallCache removes the call, by call ID and by tag. Its CallRecord is kept for the TTL of the cache.
+func (cache *BCache) RemoveCallCache(call *CallSession) error {
+	if err := cache.check(); err != nil {
+		return err
+	}
+
+	if call == nil {
+		return errors.New("empty session object")
+	}
+
+	cache.calls.Remove(call)
+
+	if tag := call.GetTagID(); len(tag) > 0 {
+		if err := cache.store.Delete(StoreCallPrefix + tag); err != nil {
+			return err
+		}
 	}
 
-	cache.p.Delete(callID)
+	if callID := call.GetCallID(); len(callID) > 0 {
+		return cache.store.Set(StoreCallPrefix+callID, call.record(), cache.ttl)
+	}
 
 	return nil
 }
 
-// SetMsgCache TODO DESCRIPTION
-func (cache *BCache) SetMsgCache(msgID string, sess *MsgSession) error {
-	if cache == nil {
-		Log.Error("empty cache object")
-		return errors.New("empty cache object")
+// GetAllCallsCache returns every CallSession in the cache, once
+func (cache *BCache) GetAllCallsCache() ([]*CallSession, error) {
+	if err := cache.check(); err != nil {
+		return nil, err
 	}
 
-	if cache.p == nil {
-		Log.Error("cache not initialized")
-		return errors.New("cache not initialized")
+	return cache.calls.All(), nil
+}
+
+// CallRecords returns the records of the calls of the store, live and ended (persisted ones included)
+func (cache *BCache) CallRecords() ([]CallRecord, error) {
+	if err := cache.check(); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	records := make([]CallRecord, 0)
+
+	for _, key := range cache.store.List(StoreCallPrefix) {
+		v, found := cache.store.Get(key)
+		if !found {
+			continue
+		}
+
+		var r CallRecord
+
+		switch rec := v.(type) {
+		case *CallSession:
+			r = rec.record()
+		case CallRecord:
+			r = rec
+		case json.RawMessage:
+			if err := json.Unmarshal(rec, &r); err != nil {
+				continue
+			}
+		default:
+			continue
+		}
+
+		// the live calls are there by call ID and by tag
+		id := r.CallID + "/" + r.TagID
+		if !seen[id] {
+			seen[id] = true
+			records = append(records, r)
+		}
+	}
+
+	return records, nil
+}
+
+// SetMsgCache TODO DESCRIPTION
+func (cache *BCache) SetMsgCache(msgID string, sess *MsgSession) error {
+	if err := cache.check(); err != nil {
+		cache.logger().Error("%v", err)
+		return err
 	}
 
 	if sess == nil {
-		Log.Error("empty session object")
+		cache.logger().Error("empty session object")
 		return errors.New("empty session object")
 	}
 
-	cache.p.Set(msgID, sess, CacheExpiry*time.Second)
-
-	return nil
+	return cache.store.Set(StoreMsgPrefix+msgID, sess, cache.ttl)
 }
 
 // GetMsgCache TODO DESCRIPTION
 func (cache *BCache) GetMsgCache(msgID string) (*MsgSession, error) {
-	if cache == nil {
-		return nil, errors.New("empty cache object")
+	if err := cache.check(); err != nil {
+		return nil, err
 	}
 
-	if cache.p == nil {
-		return nil, errors.New("cache not initialized")
+	v, found := cache.store.Get(StoreMsgPrefix + msgID)
+	if !found {
+		return nil, nil
 	}
 
-	if v, found := cache.p.Get(msgID); found {
-		if _, ok := v.(*MsgSession); !ok {
-			return nil, errors.New("wrong cache data type")
-		}
-
+	switch v.(type) {
+	case *MsgSession:
 		return v.(*MsgSession), nil
+	case json.RawMessage:
+		// persisted by a previous run, not live
+		return nil, nil
+	default:
+		return nil, errors.New("wrong cache data type")
 	}
-
-	return nil, nil
 }
 
 // DeleteMsgCache TODO DESCRIPTION
 func (cache *BCache) DeleteMsgCache(msgID string) error {
-	if cache == nil {
-		return errors.New("empty cache object")
+	if err := cache.check(); err != nil {
+		return err
 	}
 
-	if cache.p == nil {
-		return errors.New("cache not initialized")
+	return cache.store.Delete(StoreMsgPrefix + msgID)
+}
+
+// MsgRecords returns the parameters of the messages of the store (persisted ones included)
+func (cache *BCache) MsgRecords() ([]MsgParams, error) {
+	if err := cache.check(); err != nil {
+		return nil, err
 	}
 
-	cache.p.Delete(msgID)
+	records := make([]MsgParams, 0)
 
-	return nil
+	for _, key := range cache.store.List(StoreMsgPrefix) {
+		v, found := cache.store.Get(key)
+		if !found {
+			continue
+		}
+
+		switch rec := v.(type) {
+		case *MsgSession:
+			records = append(records, rec.storeRecord().(MsgParams))
+		case json.RawMessage:
+			var params MsgParams
+
+			if err := json.Unmarshal(rec, &params); err == nil {
+				records = append(records, params)
+			}
+		}
+	}
+
+	return records, nil
 }
 
 // SetTasking TODO DESCRIPTION
 func (cache *BCache) SetTasking(id string, t *Tasking) error {
-	if cache == nil {
-		Log.Error("empty cache object")
-		return errors.New("empty cache object")
-	}
-
-	if cache.p == nil {
-		Log.Error("cache not initialized")
-		return errors.New("cache not initialized")
+	if err := cache.check(); err != nil {
+		cache.logger().Error("%v", err)
+		return err
 	}
 
 	if t == nil {
-		Log.Error("empty Tasking object")
+		cache.logger().Error("empty Tasking object")
 		return errors.New("empty Tasking object")
 	}
 
-	cache.p.Set(id, t, CacheExpiry*time.Second)
-
-	return nil
+	return cache.store.Set(StoreTaskingPrefix+id, t, 0)
 }
 
 // GetTasking TODO DESCRIPTION
 func (cache *BCache) GetTasking(id string) (*Tasking, error) {
-	if cache == nil {
-		return nil, errors.New("empty cache object")
-	}
-
-	if cache.p == nil {
-		return nil, errors.New("cache not initialized")
+	if err := cache.check(); err != nil {
+		return nil, err
 	}
 
-	if v, found := cache.p.Get(id); found {
+	if v, found := cache.store.Get(StoreTaskingPrefix + id); found {
 		if _, ok := v.(*Tasking); !ok {
 			return nil, errors.New("wrong cache data type")
 		}
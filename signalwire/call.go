@@ -84,6 +84,7 @@ type CallSession struct {
 	CallState               CallState
 	PrevCallState           CallState
 	CallType                CallType
+	SIP                     *DeviceSIPParams // SIP device of a sip call, Password cleared
 	CallDisconnectReason    CallDisconnectReason
 	CallConnectState        CallConnectState
 	CallStateChan           chan CallState
@@ -137,12 +138,19 @@ type CallSession struct {
 	CallPlayAndCollectReadyChans    map[string](chan struct{})
 	CallPlayAndCollectRawEventChans map[string](chan *json.RawMessage)
 
-	Hangup   chan struct{}
-	CallPeer PeerDeviceStruct
-	Actions  Actions
-	Blade    *BladeSession
-	I        ICall
-	Event    *json.RawMessage
+	Hangup     chan struct{}
+	hangupOnce sync.Once
+	bus        eventBus
+	callbacks  callbackQueue
+	actions    callActions
+	// registryKeys is the number of keys of the call in the registry
+	registryKeys int32
+	CallPeer     PeerDeviceStruct
+	Actions      Actions
+	Blade        *BladeSession
+	I            ICall
+	Event        *json.RawMessage
+	err          error
 	sync.RWMutex
 }
 
@@ -158,21 +166,6 @@ type ICall interface {
 	GetPeer(ctx context.Context) (*CallSession, error)
 }
 
-// CallTagToCallID map of tag to Call-ID
-type CallTagToCallID struct {
-	sync.RWMutex
-	m map[string]string
-}
-
-// NewCallTagToCallID returns new map of tag to Call-ID
-func NewCallTagToCallID() *CallTagToCallID {
-	var obj CallTagToCallID
-
-	obj.m = make(map[string]string)
-
-	return &obj
-}
-
 // CallInit creates the channels communicating call states
 func (c *CallSession) CallInit(_ context.Context) {
 	c.CallStateChan = make(chan CallState, EventQueue)
@@ -412,6 +405,92 @@ func (c *CallSession) SetType(t CallType) {
 	c.Unlock()
 }
 
+// SetSIP sets the SIP device of the call
+func (c *CallSession) SetSIP(p *DeviceSIPParams) {
+	var sip *DeviceSIPParams
+
+	if p != nil {
+		params := *p
+		params.Password = ""
+		sip = &params
+	}
+
+	c.Lock()
+	c.SIP = sip
+	c.Unlock()
+}
+
+// GetSIP returns the SIP device of the call, nil if not a sip call
+func (c *CallSession) GetSIP() *DeviceSIPParams {
+	c.RLock()
+	defer c.RUnlock()
+
+	if c.SIP == nil {
+		return nil
+	}
+
+	sip := *c.SIP
+
+	return &sip
+}
+
+// Fail ends the call locally with err, for when Relay can no longer report
+// on it (the Blade session was lost). Waiters and in-flight actions are released.
+func (c *CallSession) Fail(err error) {
+	c.Lock()
+	c.err = err
+	c.Active = false
+	c.PrevCallState = c.CallState
+	c.CallState = Ended
+	c.CallDisconnectReason = CallGenericError
+	c.Unlock()
+
+	select {
+	case c.CallStateChan <- Ended:
+	default:
+	}
+
+	c.callbacks.send(c.cbStateChan, func() bool {
+		select {
+		case c.cbStateChan <- Ended:
+			return true
+		default:
+			return false
+		}
+	})
+
+	c.closeHangup()
+}
+
+// GetError returns the error the call failed with, nil if it did not fail
+func (c *CallSession) GetError() error {
+	c.RLock()
+	err := c.err
+	c.RUnlock()
+
+	return err
+}
+
+// endedError is the error of the actions stopped by the end of the call
+func (c *CallSession) endedError() error {
+	if err := c.GetError(); err != nil {
+		return err
+	}
+
+	return ErrCallEnded
+}
+
+// closeHangup releases everything waiting on the Hangup channel, only once
+func (c *CallSession) closeHangup() {
+	if c.Hangup == nil {
+		return
+	}
+
+	c.hangupOnce.Do(func() {
+		close(c.Hangup)
+	})
+}
+
 // SetDisconnectReason TODO DESCRIPTION
 func (c *CallSession) SetDisconnectReason(reason CallDisconnectReason) {
 	c.Lock()
@@ -421,20 +500,21 @@ func (c *CallSession) SetDisconnectReason(reason CallDisconnectReason) {
 
 // UpdateCallConnectState TODO DESCRIPTION
 func (c *CallSession) UpdateCallConnectState(s CallConnectState) {
-	Log.Debug("[%p] [%v]\n", c, s)
+	c.logger().Debug("[%p] [%v]\n", c, s)
 
 	c.CallConnectState = s
 }
 
 // UpdateConnectPeer TODO DESCRIPTION
 func (c *CallSession) UpdateConnectPeer(p PeerDeviceStruct) {
-	Log.Debug("[%p] [%v]\n", c, p)
+	c.logger().Debug("[%p] [%v]\n", c, p)
 
 	c.CallPeer.CallID = p.CallID
 	c.CallPeer.NodeID = p.NodeID
 	c.CallPeer.Device.Type = p.Device.Type
 	c.CallPeer.Device.Params.ToNumber = p.Device.Params.ToNumber
 	c.CallPeer.Device.Params.FromNumber = p.Device.Params.FromNumber
+	c.CallPeer.Device.SIP = p.Device.SIP
 }
 
 // GetState TODO DESCRIPTION
@@ -511,18 +591,18 @@ func (c *CallSession) GetEventName() string {
 
 		b, err := json.Marshal(ev)
 		if err != nil {
-			Log.Error("payload: cannot marshal")
+			c.logger().Error("payload: cannot marshal")
 		}
 
 		err = json.Unmarshal(b, placeholder)
 		if err != nil {
-			Log.Error("payload: cannot unmarshal to RawMessage")
+			c.logger().Error("payload: cannot unmarshal to RawMessage")
 		}
 
 		return placeholder.Params.Params.EventType
 	}
 
-	Log.Error("no Event!")
+	c.logger().Error("no Event!")
 
 	return ""
 }
@@ -544,49 +624,7 @@ type CallParams struct {
 	CallState  CallState
 	EndReason  string
 	Context    string // inbound
-}
-
-// ITagToCallID TODO DESCRIPTION
-type ITagToCallID interface {
-	addCallID(tag, callID string)
-	getCallID(tag string) string
-	removeCallID(tag string)
-}
-
-func (p *CallTagToCallID) addCallID(tag, callID string) {
-	if p == nil {
-		return
-	}
-
-	p.Lock()
-
-	p.m[tag] = callID
-
-	p.Unlock()
-}
-
-func (p *CallTagToCallID) getCallID(tag string) string {
-	if p == nil {
-		return ""
-	}
-
-	p.RLock()
-
-	callID := p.m[tag]
-
-	p.RUnlock()
-
-	return callID
-}
-
-func (p *CallTagToCallID) removeCallID(tag string) {
-	if p == nil {
-		return
-	}
-
-	p.Lock()
-	delete(p.m, tag)
-	p.Unlock()
+	SIP        *DeviceSIPParams
 }
 
 // AddAction TODO DESCRIPTION
@@ -93,18 +93,18 @@ func (mr *MockICallMockRecorder) UpdateConnectPeer(p interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateConnectPeer", reflect.TypeOf((*MockICall)(nil).UpdateConnectPeer), p)
 }
 
-// WaitCallState mocks base method
-func (m *MockICall) WaitCallState(ctx context.Context, want CallState) bool {
+// WaitCallStateInternal mocks base method
+func (m *MockICall) WaitCallStateInternal(ctx context.Context, want CallState, timeoutSec uint) bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitCallState", ctx, want)
+	ret := m.ctrl.Call(m, "WaitCallStateInternal", ctx, want, timeoutSec)
 	ret0, _ := ret[0].(bool)
 	return ret0
 }
 
-// WaitCallState indicates an expected call of WaitCallState
-func (mr *MockICallMockRecorder) WaitCallState(ctx, want interface{}) *gomock.Call {
+// WaitCallStateInternal indicates an expected call of WaitCallStateInternal
+func (mr *MockICallMockRecorder) WaitCallStateInternal(ctx, want, timeoutSec interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitCallState", reflect.TypeOf((*MockICall)(nil).WaitCallState), ctx, want)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitCallStateInternal", reflect.TypeOf((*MockICall)(nil).WaitCallStateInternal), ctx, want, timeoutSec)
 }
 
 // WaitCallConnectState mocks base method
@@ -121,38 +121,13 @@ func (mr *MockICallMockRecorder) WaitCallConnectState(ctx, want interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitCallConnectState", reflect.TypeOf((*MockICall)(nil).WaitCallConnectState), ctx, want)
 }
 
-// WaitPlayState mocks base method
-func (m *MockICall) WaitPlayState(ctx context.Context, ctrlID string, want PlayState) bool {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitPlayState", ctx, ctrlID, want)
-	ret0, _ := ret[0].(bool)
-	return ret0
-}
-
-// WaitPlayState indicates an expected call of WaitPlayState
-func (mr *MockICallMockRecorder) WaitPlayState(ctx, ctrlID, want interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitPlayState", reflect.TypeOf((*MockICall)(nil).WaitPlayState), ctx, ctrlID, want)
-}
-
-// WaitRecordState mocks base method
-func (m *MockICall) WaitRecordState(ctx context.Context, ctrlID string, want RecordState) bool {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "WaitRecordState", ctx, ctrlID, want)
-	ret0, _ := ret[0].(bool)
-	return ret0
-}
-
-// WaitRecordState indicates an expected call of WaitRecordState
-func (mr *MockICallMockRecorder) WaitRecordState(ctx, ctrlID, want interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitRecordState", reflect.TypeOf((*MockICall)(nil).WaitRecordState), ctx, ctrlID, want)
-}
-
 // GetPeer mocks base method
-func (m *MockICall) GetPeer(ctx context.Context) {
+func (m *MockICall) GetPeer(ctx context.Context) (*CallSession, error) {
 	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "GetPeer", ctx)
+	ret := m.ctrl.Call(m, "GetPeer", ctx)
+	ret0, _ := ret[0].(*CallSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetPeer indicates an expected call of GetPeer
@@ -160,64 +135,3 @@ func (mr *MockICallMockRecorder) GetPeer(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPeer", reflect.TypeOf((*MockICall)(nil).GetPeer), ctx)
 }
-
-// MockITagToCallID is a mock of ITagToCallID interface
-type MockITagToCallID struct {
-	ctrl     *gomock.Controller
-	recorder *MockITagToCallIDMockRecorder
-}
-
-// MockITagToCallIDMockRecorder is the mock recorder for MockITagToCallID
-type MockITagToCallIDMockRecorder struct {
-	mock *MockITagToCallID
-}
-
-// NewMockITagToCallID creates a new mock instance
-func NewMockITagToCallID(ctrl *gomock.Controller) *MockITagToCallID {
-	mock := &MockITagToCallID{ctrl: ctrl}
-	mock.recorder = &MockITagToCallIDMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use
-func (m *MockITagToCallID) EXPECT() *MockITagToCallIDMockRecorder {
-	return m.recorder
-}
-
-// addCallID mocks base method
-func (m *MockITagToCallID) addCallID(tag, callID string) {
-	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "addCallID", tag, callID)
-}
-
-// addCallID indicates an expected call of addCallID
-func (mr *MockITagToCallIDMockRecorder) addCallID(tag, callID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "addCallID", reflect.TypeOf((*MockITagToCallID)(nil).addCallID), tag, callID)
-}
-
-// getCallID mocks base method
-func (m *MockITagToCallID) getCallID(tag string) string {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "getCallID", tag)
-	ret0, _ := ret[0].(string)
-	return ret0
-}
-
-// getCallID indicates an expected call of getCallID
-func (mr *MockITagToCallIDMockRecorder) getCallID(tag interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "getCallID", reflect.TypeOf((*MockITagToCallID)(nil).getCallID), tag)
-}
-
-// removeCallID mocks base method
-func (m *MockITagToCallID) removeCallID(tag string) {
-	m.ctrl.T.Helper()
-	m.ctrl.Call(m, "removeCallID", tag)
-}
-
-// removeCallID indicates an expected call of removeCallID
-func (mr *MockITagToCallIDMockRecorder) removeCallID(tag interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "removeCallID", reflect.TypeOf((*MockITagToCallID)(nil).removeCallID), tag)
-}
@@ -0,0 +1,205 @@
+package signalwire
+
+import (
+	"reflect"
+	"sync"
+)
+
+// callbackQueue runs the callbacks of a call one at a time, in the order the
+// events arrived from Relay, from a go routine of its own that runs while
+// there are callbacks queued. Each call has its own queue: the callbacks of
+// different calls run in parallel.
+//
+// The event bus of the call reserves a slot for every event it sends to a
+// channel with a listener (the go routine of an action, or of the call
+// state), the listener then adds the callbacks of the event to the slot.
+type callbackQueue struct {
+	sync.Mutex
+	queue     []*callbackSlot
+	running   bool
+	idle      chan struct{}
+	listeners map[interface{}]*slotListener
+}
+
+// callbackSlot is the place of an event in the queue, its callbacks run once it is done
+type callbackSlot struct {
+	fns   []func()
+	ready chan struct{}
+}
+
+// slotListener holds the slots of the events sent to a channel, not read yet
+type slotListener struct {
+	// skip is the number of events sent before listen, without a slot
+	skip  int
+	slots []*callbackSlot
+}
+
+// add adds fn to the callbacks of the slot, before done
+func (s *callbackSlot) add(fn func()) {
+	s.fns = append(s.fns, fn)
+}
+
+// done releases the slot, nil-safe
+func (s *callbackSlot) done() {
+	if s == nil {
+		return
+	}
+
+	close(s.ready)
+}
+
+// enqueue queues fn at the end of the queue, callbacks are never dropped
+func (q *callbackQueue) enqueue(fn func()) {
+	slot := &callbackSlot{fns: []func(){fn}, ready: make(chan struct{})}
+	close(slot.ready)
+
+	q.Lock()
+	q.push(slot)
+	q.Unlock()
+}
+
+// push appends slot to the queue, the lock is held
+func (q *callbackQueue) push(slot *callbackSlot) {
+	q.queue = append(q.queue, slot)
+
+	if !q.running {
+		q.running = true
+		q.idle = make(chan struct{})
+
+		go q.run()
+	}
+}
+
+// listen makes the events sent to ch reserve their slot, ch is read by the
+// go routine that calls listen. Returns the function to call when it stops
+// reading: the slots still reserved are released.
+func (q *callbackQueue) listen(ch interface{}) func() {
+	q.Lock()
+
+	if q.listeners == nil {
+		q.listeners = make(map[interface{}]*slotListener)
+	}
+
+	q.listeners[ch] = &slotListener{skip: reflect.ValueOf(ch).Len()}
+
+	q.Unlock()
+
+	return func() {
+		q.Lock()
+
+		l := q.listeners[ch]
+		delete(q.listeners, ch)
+
+		q.Unlock()
+
+		for _, slot := range l.slots {
+			slot.done()
+		}
+	}
+}
+
+// send runs the non-blocking send of an event to ch, and reserves the slot
+// of the event if ch has a listener and the event was sent
+func (q *callbackQueue) send(ch interface{}, send func() bool) {
+	q.Lock()
+	defer q.Unlock()
+
+	if !send() {
+		return
+	}
+
+	l, ok := q.listeners[ch]
+	if !ok {
+		return
+	}
+
+	slot := &callbackSlot{ready: make(chan struct{})}
+	l.slots = append(l.slots, slot)
+	q.push(slot)
+}
+
+// take returns the slot of the event just read from ch, nil if it has none
+func (q *callbackQueue) take(ch interface{}) *callbackSlot {
+	q.Lock()
+	defer q.Unlock()
+
+	l, ok := q.listeners[ch]
+	if !ok {
+		return nil
+	}
+
+	if l.skip > 0 {
+		l.skip--
+
+		return nil
+	}
+
+	if len(l.slots) == 0 {
+		return nil
+	}
+
+	slot := l.slots[0]
+	l.slots = l.slots[1:]
+
+	return slot
+}
+
+// buffered tells if one of chans has events not read yet
+func buffered(chans ...interface{}) bool {
+	for _, ch := range chans {
+		if reflect.ValueOf(ch).Len() > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (q *callbackQueue) run() {
+	for {
+		q.Lock()
+
+		if len(q.queue) == 0 {
+			q.running = false
+			close(q.idle)
+			q.Unlock()
+
+			return
+		}
+
+		slot := q.queue[0]
+		q.queue[0] = nil
+		q.queue = q.queue[1:]
+
+		q.Unlock()
+
+		<-slot.ready
+
+		for _, fn := range slot.fns {
+			fn()
+		}
+	}
+}
+
+// wait returns once the queue is empty and its go routine done
+func (q *callbackQueue) wait() {
+	q.Lock()
+
+	if !q.running {
+		q.Unlock()
+
+		return
+	}
+
+	idle := q.idle
+
+	q.Unlock()
+
+	<-idle
+}
+
+// WaitForCallbacks returns once the call has no callbacks or listeners left
+// to run. It must not be called from a callback of the same call.
+func (callobj *CallObj) WaitForCallbacks() {
+	callobj.call.callbacks.wait()
+}
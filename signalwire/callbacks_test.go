@@ -0,0 +1,77 @@
+package signalwire
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestCallbackQueue(t *testing.T) {
+	t.Run(
+		"InOrder",
+		func(t *testing.T) {
+			var (
+				q       callbackQueue
+				running int32
+				got     []int
+			)
+
+			for i := 0; i < 100; i++ {
+				i := i
+
+				q.enqueue(func() {
+					assert.Equal(t, int32(1), atomic.AddInt32(&running, 1), "callbacks must run one at a time")
+					got = append(got, i)
+					atomic.AddInt32(&running, -1)
+				})
+			}
+
+			q.wait()
+
+			assert.Len(t, got, 100)
+
+			for i := range got {
+				assert.Equal(t, i, got[i])
+			}
+		},
+	)
+	t.Run(
+		"CallsInParallel",
+		func(t *testing.T) {
+			var stuck, other callbackQueue
+
+			release := make(chan struct{})
+			done := make(chan struct{})
+
+			stuck.enqueue(func() { <-release })
+			other.enqueue(func() { close(done) })
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatalf("a stuck call must not hold up the callbacks of the others")
+			}
+
+			close(release)
+			stuck.wait()
+		},
+	)
+}
+
+func TestEventStream(t *testing.T) {
+	t.Run(
+		"NilCalling",
+		func(t *testing.T) {
+			callobj := CallObjNew()
+
+			select {
+			case _, ok := <-callobj.Events():
+				assert.False(t, ok, "the channel must be closed")
+			case <-time.After(time.Second):
+				t.Fatalf("the events of a call without a Calling object must end")
+			}
+		},
+	)
+}
@@ -3,6 +3,7 @@ package signalwire
 import (
 	"context"
 	"encoding/json"
+	"sync/atomic"
 	"time"
 )
 
@@ -52,6 +53,10 @@ type CallObj struct {
 	OnSendDigitsFinished    func(*SendDigitsAction)
 	OnSendDigitsStateChange func(*SendDigitsAction)
 	OnPrompt                func(*PromptAction)
+
+	listeners callListeners
+	events    eventStream
+	stateLoop int32
 }
 
 // ICallObj these are for unit-testing
@@ -90,6 +95,7 @@ type ICallObj interface {
 	GetTempID() string
 	GetTo() string
 	GetFrom() string
+	GetError() error
 }
 
 // ResultDial TODO DESCRIPTION
@@ -131,6 +137,7 @@ func waitStop(res *StopResult, done chan bool) {
 // ICalling object visible to the end user
 type ICalling interface {
 	DialPhone(fromNumber, toNumber string) ResultDial
+	DialSIP(params DeviceSIPParams) ResultDial
 	NewCall() *CallObj
 	Dial(c *CallObj) ResultDial
 }
@@ -142,13 +149,42 @@ func CallObjNew() *CallObj {
 
 // DialPhone  TODO DESCRIPTION
 func (calling *Calling) DialPhone(fromNumber, toNumber string) ResultDial {
+	return calling.DialPhoneContext(calling.Ctx, fromNumber, toNumber)
+}
+
+// DialPhoneContext is DialPhone, cancelling ctx while ringing hangs up the call and returns ctx.Err() as error
+func (calling *Calling) DialPhoneContext(ctx context.Context, fromNumber, toNumber string) ResultDial {
+	return calling.dial(ctx, DefaultRingTimeout, func(newcall *CallSession, payload **json.RawMessage) error {
+		return calling.Relay.I.RelayPhoneDial(ctx, newcall, fromNumber, toNumber, DefaultRingTimeout, payload)
+	})
+}
+
+// DialSIP dials a SIP endpoint, params.To is its SIP URI
+func (calling *Calling) DialSIP(params DeviceSIPParams) ResultDial {
+	return calling.DialSIPContext(calling.Ctx, params)
+}
+
+// DialSIPContext is DialSIP, cancelling ctx while ringing hangs up the call and returns ctx.Err() as error
+func (calling *Calling) DialSIPContext(ctx context.Context, params DeviceSIPParams) ResultDial {
+	timeout := params.Timeout
+	if timeout == 0 {
+		timeout = DefaultRingTimeout
+	}
+
+	return calling.dial(ctx, timeout, func(newcall *CallSession, payload **json.RawMessage) error {
+		return calling.Relay.I.RelaySIPDial(ctx, newcall, &params, payload)
+	})
+}
+
+// dial begins a new call and waits for it to be answered, up to timeout (seconds)
+func (calling *Calling) dial(ctx context.Context, timeout uint, begin func(*CallSession, **json.RawMessage) error) ResultDial {
 	res := new(ResultDial)
 
 	if calling.Relay == nil {
 		return *res
 	}
 
-	if calling.Ctx == nil {
+	if calling.Ctx == nil || ctx == nil {
 		return *res
 	}
 
@@ -161,7 +197,7 @@ func (calling *Calling) DialPhone(fromNumber, toNumber string) ResultDial {
 
 	var savePayload *json.RawMessage
 
-	if err := calling.Relay.I.RelayPhoneDial(calling.Ctx, newcall, fromNumber, toNumber, DefaultRingTimeout, &savePayload); err != nil {
+	if err := begin(newcall, &savePayload); err != nil {
 		newcall.SetActive(false)
 
 		res.err = err
@@ -177,11 +213,12 @@ func (calling *Calling) DialPhone(fromNumber, toNumber string) ResultDial {
 	c.Payload = savePayload
 	c.Calling = calling
 
-	if ret := newcall.I.WaitCallStateInternal(calling.Ctx, Answered, DefaultRingTimeout); !ret {
-		Log.Debug("did not get Answered state\n")
+	if ret := newcall.I.WaitCallStateInternal(ctx, Answered, timeout); !ret {
+		calling.logger().Debug("did not get Answered state\n")
 
 		c.call.SetActive(false)
 		res.Call = c
+		res.err = c.cancelAction(ctx, false, c.hangupNoWait)
 
 		return *res
 	}
@@ -192,6 +229,38 @@ func (calling *Calling) DialPhone(fromNumber, toNumber string) ResultDial {
 	return *res
 }
 
+// ctx is the context of the blocking methods without one
+func (callobj *CallObj) ctx() context.Context {
+	if callobj.Calling == nil || callobj.Calling.Ctx == nil {
+		return context.Background()
+	}
+
+	return callobj.Calling.Ctx
+}
+
+// cancelAction is run by the ctx variants of the blocking methods once they
+// return: if ctx is done before the action completed, the action is stopped
+// with the context of the session (stop may be nil) and ctx.Err() returned.
+func (callobj *CallObj) cancelAction(ctx context.Context, completed bool, stop func(context.Context) error) error {
+	err := ctx.Err()
+	if err == nil || completed {
+		return nil
+	}
+
+	if stop != nil && callobj.ctx().Err() == nil {
+		if serr := stop(callobj.ctx()); serr != nil {
+			callobj.logger().Debug("cannot stop the action: %v\n", serr)
+		}
+	}
+
+	return err
+}
+
+// hangupNoWait ends the call, without waiting for the Ended state
+func (callobj *CallObj) hangupNoWait(ctx context.Context) error {
+	return callobj.Calling.Relay.RelayCallEnd(ctx, callobj.call, &callobj.Payload)
+}
+
 // Hangup TODO DESCRIPTION
 func (callobj *CallObj) Hangup() (*ResultHangup, error) {
 	res := new(ResultHangup)
@@ -205,7 +274,7 @@ func (callobj *CallObj) Hangup() (*ResultHangup, error) {
 	}
 
 	if ret := call.WaitCallStateInternal(callobj.Calling.Ctx, Ended, BroadcastEventTimeout); !ret {
-		Log.Debug("did not get Ended state for call\n")
+		callobj.logger().Debug("did not get Ended state for call\n")
 	}
 
 	if call.CallState == Ended {
@@ -226,24 +295,26 @@ func (callobj *CallObj) Answer() (*ResultAnswer, error) {
 	res := new(ResultAnswer)
 
 	if call.CallState != Answered {
-		Log.Info("Answering call [%p]\n", call)
+		callobj.logger().Info("Answering call [%p]\n", call)
 
 		if err := callobj.Calling.Relay.RelayCallAnswer(callobj.Calling.Ctx, call, &callobj.Payload); err != nil {
-			Log.Debug("cannot answer call. err: %v\n", err)
+			callobj.logger().Debug("cannot answer call. err: %v\n", err)
 
 			return res, err
 		}
 	}
 
 	// 'Answered' state event may have already come before we get the 200 for calling.answer command.
-	if call.CallState != Answered {
+	if call.GetState() != Answered {
 		if ret := call.WaitCallStateInternal(callobj.Calling.Ctx, Answered, BroadcastEventTimeout); !ret {
-			Log.Debug("did not get Answered state for inbound call\n")
+			callobj.logger().Debug("did not get Answered state for inbound call\n")
 
 			return res, nil
 		}
 	}
 
+	atomic.StoreInt32(&callobj.stateLoop, 1)
+
 	go func(ctx context.Context) {
 		// states && callbacks
 		callobj.callbacksRunCallState(ctx)
@@ -266,7 +337,7 @@ func (callobj *CallObj) GetCallState() CallState {
 // WaitFor TODO DESCRIPTION
 func (callobj *CallObj) WaitFor(want CallState, timeout uint) bool {
 	if ret := callobj.call.WaitCallStateInternal(callobj.Calling.Ctx, want, timeout); !ret {
-		Log.Error("did not get %s state for call\n", want.String())
+		callobj.logger().Error("did not get %s state for call\n", want.String())
 		return false
 	}
 
@@ -276,7 +347,7 @@ func (callobj *CallObj) WaitFor(want CallState, timeout uint) bool {
 // WaitForRinging TODO DESCRIPTION
 func (callobj *CallObj) WaitForRinging(timeout uint) bool {
 	if ret := callobj.call.WaitCallStateInternal(callobj.Calling.Ctx, Ringing, timeout); !ret {
-		Log.Error("did not get Ringing state for call\n")
+		callobj.logger().Error("did not get Ringing state for call\n")
 		return false
 	}
 
@@ -286,7 +357,7 @@ func (callobj *CallObj) WaitForRinging(timeout uint) bool {
 // WaitForAnswered TODO DESCRIPTION
 func (callobj *CallObj) WaitForAnswered(timeout uint) bool {
 	if ret := callobj.call.WaitCallStateInternal(callobj.Calling.Ctx, Answered, timeout); !ret {
-		Log.Error("did not get Answered state for call\n")
+		callobj.logger().Error("did not get Answered state for call\n")
 		return false
 	}
 
@@ -296,7 +367,7 @@ func (callobj *CallObj) WaitForAnswered(timeout uint) bool {
 // WaitForEnding TODO DESCRIPTION
 func (callobj *CallObj) WaitForEnding(timeout uint) bool {
 	if ret := callobj.call.WaitCallStateInternal(callobj.Calling.Ctx, Ending, timeout); !ret {
-		Log.Error("did not get Ending state for call\n")
+		callobj.logger().Error("did not get Ending state for call\n")
 		return false
 	}
 
@@ -306,7 +377,7 @@ func (callobj *CallObj) WaitForEnding(timeout uint) bool {
 // WaitForEnded TODO DESCRIPTION
 func (callobj *CallObj) WaitForEnded(timeout uint) bool {
 	if ret := callobj.call.WaitCallStateInternal(callobj.Calling.Ctx, Ended, timeout); !ret {
-		Log.Error("did not get Ended state for call\n")
+		callobj.logger().Error("did not get Ended state for call\n")
 		return false
 	}
 
@@ -332,34 +403,30 @@ func (calling *Calling) NewCall(from, to string) *CallObj {
 }
 
 func (callobj *CallObj) callbacksRunCallState(ctx context.Context) {
+	defer callobj.call.callbacks.listen(callobj.call.cbStateChan)()
+
 	var out bool
 
+	var cb *callbackSlot
+
 	for {
 		select {
 		case rcvState := <-callobj.call.cbStateChan:
+			cb = callobj.call.callbacks.take(callobj.call.cbStateChan)
+
 			if rcvState != callobj.call.GetPrevState() {
-				if callobj.OnStateChange != nil {
-					callobj.OnStateChange(callobj)
-				}
+				callobj.emit(cb, EventStateChange, nil)
 			}
 
 			switch rcvState {
 			case Answered:
-				if callobj.OnAnswered != nil {
-					callobj.OnAnswered(callobj)
-				}
+				callobj.emit(cb, EventAnswered, nil)
 			case Ringing:
-				if callobj.OnRinging != nil {
-					callobj.OnRinging(callobj)
-				}
+				callobj.emit(cb, EventRinging, nil)
 			case Ending:
-				if callobj.OnEnding != nil {
-					callobj.OnEnding(callobj)
-				}
+				callobj.emit(cb, EventEnding, nil)
 			case Ended:
-				if callobj.OnEnded != nil {
-					callobj.OnEnded(callobj)
-				}
+				callobj.emit(cb, EventEnded, nil)
 
 				out = true
 			}
@@ -367,7 +434,12 @@ func (callobj *CallObj) callbacksRunCallState(ctx context.Context) {
 			out = true
 		}
 
+		cb.done()
+		cb = nil
+
 		if out {
+			callobj.endEvents(&callobj.events)
+
 			break
 		}
 	}
@@ -375,13 +447,18 @@ func (callobj *CallObj) callbacksRunCallState(ctx context.Context) {
 
 // Dial TODO DESCRIPTION
 func (calling *Calling) Dial(c *CallObj) ResultDial {
+	return calling.DialContext(calling.Ctx, c)
+}
+
+// DialContext is Dial, cancelling ctx while ringing hangs up the call and returns ctx.Err() as error
+func (calling *Calling) DialContext(ctx context.Context, c *CallObj) ResultDial {
 	res := new(ResultDial)
 
 	if calling.Relay == nil {
 		return *res
 	}
 
-	if calling.Ctx == nil {
+	if calling.Ctx == nil || ctx == nil {
 		return *res
 	}
 
@@ -391,7 +468,7 @@ func (calling *Calling) Dial(c *CallObj) ResultDial {
 
 	c.call.SetActive(true)
 
-	if err := calling.Relay.I.RelayPhoneDial(calling.Ctx, c.call, c.call.From, c.call.To, c.call.Timeout, &c.Payload); err != nil {
+	if err := calling.Relay.I.RelayPhoneDial(ctx, c.call, c.call.From, c.call.To, c.call.Timeout, &c.Payload); err != nil {
 		res.err = err
 
 		c.call.SetActive(false)
@@ -399,15 +476,18 @@ func (calling *Calling) Dial(c *CallObj) ResultDial {
 		return *res
 	}
 
-	if ret := c.call.I.WaitCallStateInternal(calling.Ctx, Answered, c.call.GetTimeout()); !ret {
-		Log.Debug("did not get Answered state\n")
+	if ret := c.call.I.WaitCallStateInternal(ctx, Answered, c.call.GetTimeout()); !ret {
+		calling.logger().Debug("did not get Answered state\n")
 
 		c.call.SetActive(false)
 		res.Call = c
+		res.err = c.cancelAction(ctx, false, c.hangupNoWait)
 
 		return *res
 	}
 
+	atomic.StoreInt32(&c.stateLoop, 1)
+
 	go func(ctx context.Context) {
 		// states && callbacks
 		c.callbacksRunCallState(ctx)
@@ -474,6 +554,11 @@ func (callobj *CallObj) GetID() string {
 	return callobj.call.GetCallID()
 }
 
+// GetError returns the error the call failed with, if the SDK had to end it locally
+func (callobj *CallObj) GetError() error {
+	return callobj.call.GetError()
+}
+
 // GetTempID TODO DESCRIPTION
 func (callobj *CallObj) GetTempID() string {
 	return callobj.call.GetTagID()
@@ -524,6 +609,11 @@ func (callobj *CallObj) GetType() string {
 	return callobj.call.GetType()
 }
 
+// GetSIP returns the SIP device of a sip call (URIs, headers, codecs), nil otherwise
+func (callobj *CallObj) GetSIP() *DeviceSIPParams {
+	return callobj.call.GetSIP()
+}
+
 // GetEvent TODO DESCRIPTION
 func (callobj *CallObj) GetEvent() *json.RawMessage {
 	return callobj.call.GetEventPayload()
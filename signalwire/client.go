@@ -10,22 +10,55 @@ import (
 
 // ClientSession TODO DESCRIPTION
 type ClientSession struct {
-	Project     string
-	Token       string
-	Host        string
-	Agent       string
-	Relay       RelaySession
-	Calling     Calling
-	Messaging   Messaging
-	Tasking     Tasking
-	Ctx         context.Context
-	Cancel      context.CancelFunc
-	Operational chan struct{}
-	I           IClientSession
-	Consumer    *Consumer
-	OnReady     func(*ClientSession)
+	Project string
+	Token   string
+	Host    string
+	// Hosts, if set, are used instead of Host: the session fails over between them
+	Hosts []RelayHost
+	// Failover controls when the session moves to another of the Hosts
+	Failover *FailoverPolicy
+	// EventBus sets up the event queues of the calls, nil means the defaults
+	EventBus *EventBusConfig
+	// CallRegistry sets the soft limit of concurrent calls, nil means no limit
+	CallRegistry    *CallRegistryConfig
+	Agent           string
+	Relay           RelaySession
+	Calling         Calling
+	Messaging       Messaging
+	Tasking         Tasking
+	Ctx             context.Context
+	Cancel          context.CancelFunc
+	Operational     chan struct{}
+	I               IClientSession
+	Consumer        *Consumer
+	OnReady         func(*ClientSession)
+	Transport       *TransportConfig
+	ReconnectPolicy *ReconnectPolicy
+	OutboundQueue   *OutboundQueue
+	ConnectTimeout  time.Duration
+	// Credentials, if set, are used instead of Project and Token
+	Credentials CredentialsProvider
+	// WireRecorder, if set, captures the JSON-RPC frames of the Blade link
+	WireRecorder *WireRecorder
+	// OnConnectionStateChange is called on every Blade session state
+	// transition, from the go routine of the session: it must not block.
+	OnConnectionStateChange func(*ClientSession, ConnectionStateChange)
+	// ConnectionStates, if set, receives every transition (dropped if full)
+	ConnectionStates chan ConnectionStateChange
+	// OnOrphanedCalls is called with the calls lost with the Blade session,
+	// when it could not be restored or migrated. They are ended with ErrSessionLost.
+	OnOrphanedCalls func(*ClientSession, []*CallObj)
+	// Interceptors, if set, wrap every command sent and every event received
+	Interceptors *Interceptors
+	// Store, if set, keeps the calls, messages and tasking instead of a MemoryStore
+	Store Store
+	// StoreTTL is the retention of the entries of the store, 0 means CacheExpiry
+	StoreTTL time.Duration
 
 	Log LoggerWrapper
+
+	rawEvents     *rawEvents
+	rawEventsOnce sync.Once
 }
 
 // NewClientSession TODO DESCRIPTION
@@ -54,6 +87,9 @@ func (client *ClientSession) setClient(host string, contexts []string) {
 
 	blade := &BladeSession{I: I}
 	blade.I = blade
+	blade.onStateChange = client.onStateChange
+	blade.onOrphanedCalls = client.onOrphanedCalls
+	blade.rawEvents = client.events()
 	client.Relay.Blade = blade
 	client.Relay.Blade.SignalwireContexts = contexts
 	client.Operational = make(chan struct{})
@@ -82,6 +118,7 @@ func (client *ClientSession) connectInternal(ctx context.Context, cancel context
 	client.Tasking.Cancel = client.Cancel
 
 	blade := client.Relay.Blade
+	blade.Log = client.Log
 
 	var I IRelay = RelayNew()
 
@@ -101,114 +138,222 @@ func (client *ClientSession) connectInternal(ctx context.Context, cancel context
 
 	client.Tasking.Consumer = client.Consumer
 
-	var count = BladeConnectionRetries
+	attempts := blade.ReconnectPolicy.start(client.logger())
+
+	blade.hosts.set(client.relayHosts(), blade.Failover)
 
 again:
-	if err := blade.BladeInit(ctx, client.Host); err != nil {
-		Log.Debug("cannot init Blade: %v\n", err)
+	if err := blade.bladeInitHosts(ctx); err != nil {
+		client.logger().Debug("cannot init Blade: %v\n", err)
 
-		return err
+		if ctx.Err() != nil || client.retryConnect(ctx, attempts, t, err) != nil {
+			return err
+		}
+
+		goto again
 	}
 
 reconnected:
 	if err := blade.BladeConnect(ctx, &blade.bladeAuth); err != nil {
-		Log.Debug("cannot connect to Blade Network. Error Code: [%v] Message: [%v]\n", blade.LastJRPCError.Code, blade.LastJRPCError.Message)
+		client.logger().Debug("cannot connect to Blade Network. Error Code: [%v] Message: [%v]\n", blade.LastJRPCError.Code, blade.LastJRPCError.Message)
 
 		// Timeout reply received from platform
 		if blade.LastJRPCError.Code == -32000 && strings.Contains(blade.LastJRPCError.Message, "Timeout") {
-			_ = client.disconnectInternal()
-
-			if t != nil {
-				t.Reset(GlobalConnectTimeout * time.Second)
-			}
-
-			if count != 0 {
-				time.Sleep(5 * time.Second)
-
-				if count != -1 {
-					count--
-				}
+			// only the link goes, the session (ctx, Operational) stays up for the next attempt
+			_ = blade.BladeCleanup()
 
+			if client.retryConnect(ctx, attempts, t, err) == nil {
 				goto again
 			}
 		}
 
+		if ret == 1 && !blade.linkLost() {
+			// stopped while reconnecting
+			goto stopped
+		}
+
 		return err
 	}
 
-	if blade.SessionState != BladeConnected {
-		Log.Debug("not in connected state\n")
+	if blade.GetState() != BladeConnected {
+		client.logger().Debug("not in connected state\n")
 
 		return errors.New("not in connected state")
 	}
 
-	var (
-		wg        sync.WaitGroup
-		sProtocol string
-	)
+	switch {
+	case ret == 1 && blade.SessionRestored:
+		// calls, actions and subscriptions are still bound to the restored session
+		client.logger().Debug("Blade session restored\n")
+	case ret == 1:
+		client.logger().Debug("Blade session not restored, failing live calls\n")
 
-	wg.Add(1)
+		blade.BladeFailCalls(ErrSessionLost)
 
-	go func() {
-		sProtocol = <-blade.Netcast
+		fallthrough
+	default:
+		if err := client.setupSession(ctx); err != nil {
+			switch {
+			case ret == 1 && !blade.linkLost():
+				// stopped while reconnecting
+				goto stopped
+			case ret == 1 && ctx.Err() == nil:
+				client.logger().Warn("cannot set up the session again, reconnecting: %v\n", err)
 
-		wg.Done()
-	}()
+				blade.reconnectSession(true)
 
-	Log.Debug("execute Setup\n")
+				goto wait
+			}
 
-	if err := blade.BladeSetup(ctx); err != nil {
-		Log.Debug("cannot setup protocol on Blade Network: %v\n", err)
+			return err
+		}
+	}
 
+	client.Tasking.TaskChan = make(chan ParamsEventTaskingTask, 1)
+	if err := blade.EventTasking.Cache.SetTasking("tasking", &client.Tasking); err != nil {
 		return err
 	}
 
-	Log.Debug("waiting for Netcast (protocol.add)...\n")
+	blade.setState(BladeRunning)
 
-	wg.Wait()
+	if ret != 1 {
+		go blade.refreshCredentials(ctx)
+		go blade.failback(ctx)
+
+		select {
+		case client.Operational <- struct{}{}:
+		case <-ctx.Done():
+			// RunContext gave up waiting (connect timeout or cancelled)
+			return ctx.Err()
+		}
+	}
 
-	if sProtocol != blade.Protocol {
-		Log.Debug("cannot setup protocol on Blade Network / different protocol received [%s:%s]\n", sProtocol, blade.Protocol)
+wait:
+	ret = blade.BladeWaitDisconnect(ctx)
+	if ret == 1 {
+		goto reconnected
+	}
 
-		return errors.New("different protocol received (netcast)")
+	if ret == 2 {
+		if client.migrateSession() {
+			blade.setState(BladeRunning)
+		}
+
+		goto wait
 	}
 
-	blade.SignalwireChannels = []string{"notifications"}
-	if err := blade.BladeAddSubscription(ctx, blade.SignalwireChannels); err != nil {
-		Log.Debug("cannot subscribe to notifications on Blade Network: %v\n", err)
+	if ret == -1 && blade.LastError == ErrReconnectGaveUp {
+		client.stopInbound()
+	}
 
+stopped:
+	client.logger().Debug("got Disconnect\n")
+
+	blade.WatcherDone <- struct{}{}
+
+	close(client.Tasking.TaskChan)
+	cancel()
+	runWG.Done()
+
+	return nil
+}
+
+// retryConnect waits before the next attempt to connect, it returns an
+// error if the policy gives up or if ctx is done
+func (client *ClientSession) retryConnect(ctx context.Context, attempts *reconnectAttempts, t *time.Timer, err error) error {
+	delay, ok := attempts.next(err)
+	if !ok {
 		return err
 	}
 
-	if len(blade.SignalwireContexts) > 0 {
-		if err := blade.BladeSignalwireReceive(ctx, blade.SignalwireContexts); err != nil {
-			Log.Debug("cannot subscribe to inbound context on Blade Network: %v\n", err)
+	if t != nil {
+		t.Reset(delay + client.connectTimeout())
+	}
 
-			return err
-		}
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 
-	client.Tasking.TaskChan = make(chan ParamsEventTaskingTask, 1)
-	if err := blade.EventTasking.Cache.SetTasking("tasking", &client.Tasking); err != nil {
+	return nil
+}
+
+// connectTimeout is the time allowed to get the Blade session up
+func (client *ClientSession) connectTimeout() time.Duration {
+	if client.ConnectTimeout > 0 {
+		return client.ConnectTimeout
+	}
+
+	return DefaultConnectTimeout
+}
+
+// setupSession sets up the protocol and the subscriptions on a new Blade session
+func (client *ClientSession) setupSession(ctx context.Context) error {
+	blade := client.Relay.Blade
+	ctx = withoutOutboundQueue(ctx)
+
+	// the netcast may come before the reply to setup: wait for it from now on
+	netcast := make(chan string, 1)
+	stop := make(chan struct{})
+
+	defer close(stop)
+
+	go func() {
+		select {
+		case protocol := <-blade.Netcast:
+			netcast <- protocol
+		case <-stop:
+		}
+	}()
+
+	client.logger().Debug("execute Setup\n")
+
+	if err := blade.BladeSetup(ctx); err != nil {
+		client.logger().Debug("cannot setup protocol on Blade Network: %v\n", err)
+
 		return err
 	}
 
-	if ret != 1 {
-		client.Operational <- struct{}{}
+	blade.setState(BladeSetup)
+
+	client.logger().Debug("waiting for Netcast (protocol.add)...\n")
+
+	timer := time.NewTimer(client.connectTimeout())
+	defer timer.Stop()
+
+	var sProtocol string
+
+	select {
+	case sProtocol = <-netcast:
+	case <-timer.C:
+		return errors.New("no netcast of the protocol (timeout)")
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 
-	ret = blade.BladeWaitDisconnect(ctx)
-	if ret == 1 {
-		goto reconnected
+	if sProtocol != blade.Protocol {
+		client.logger().Debug("cannot setup protocol on Blade Network / different protocol received [%s:%s]\n", sProtocol, blade.Protocol)
+
+		return errors.New("different protocol received (netcast)")
 	}
 
-	Log.Debug("got Disconnect\n")
+	blade.SignalwireChannels = []string{"notifications"}
+	if err := blade.BladeAddSubscription(ctx, blade.SignalwireChannels); err != nil {
+		client.logger().Debug("cannot subscribe to notifications on Blade Network: %v\n", err)
 
-	blade.WatcherDone <- struct{}{}
+		return err
+	}
 
-	close(client.Tasking.TaskChan)
-	cancel()
-	runWG.Done()
+	blade.setState(BladeSubscribed)
+
+	if contexts := blade.GetContexts(); len(contexts) > 0 {
+		if err := blade.BladeSignalwireReceive(ctx, contexts); err != nil {
+			client.logger().Debug("cannot subscribe to inbound context on Blade Network: %v\n", err)
+
+			return err
+		}
+	}
 
 	return nil
 }
@@ -218,23 +363,18 @@ func (client *ClientSession) disconnectInternal() error {
 	blade := client.Relay.Blade
 
 	if err := blade.BladeDisconnect(client.Ctx); err != nil {
-		Log.Debug("Blade: error disconnecting\n")
+		client.logger().Debug("Blade: error disconnecting\n")
 
 		return err
 	}
 
-	select {
-	case blade.InboundDone <- struct{}{}:
-		Log.Debug("sent InboundDone to go routine\n")
-	default:
-		Log.Debug("cannot send InboundDone to go routine\n")
-	}
+	client.stopInbound()
 
 	select {
 	case blade.DisconnectChan <- struct{}{}:
-		Log.Debug("sent DisconnectChan to go routine\n")
+		client.logger().Debug("sent DisconnectChan to go routine\n")
 	default:
-		Log.Debug("cannot send DisconnectChan to go routine\n")
+		client.logger().Debug("cannot send DisconnectChan to go routine\n")
 	}
 
 	client.Cancel()
@@ -243,6 +383,25 @@ func (client *ClientSession) disconnectInternal() error {
 	return nil
 }
 
+// stopInbound stops the go routines waiting for inbound calls and messages
+func (client *ClientSession) stopInbound() {
+	blade := client.Relay.Blade
+
+	select {
+	case blade.InboundDone <- struct{}{}:
+		client.logger().Debug("sent InboundDone to go routine\n")
+	default:
+		client.logger().Debug("cannot send InboundDone to go routine\n")
+	}
+
+	select {
+	case blade.InboundMsgDone <- struct{}{}:
+		client.logger().Debug("sent InboundMsgDone to go routine\n")
+	default:
+		client.logger().Debug("cannot send InboundMsgDone to go routine\n")
+	}
+}
+
 // setupInbound TODO DESCRIPTION
 func (client *ClientSession) setupInbound() {
 	blade := client.Relay.Blade
@@ -329,6 +488,18 @@ func (client *ClientSession) Connect() error {
 
 	wg.Add(1)
 
+	client.Relay.Blade.Transport = client.Transport
+	client.Relay.Blade.ReconnectPolicy = client.ReconnectPolicy
+	client.Relay.Blade.OutboundQueue = client.OutboundQueue
+	client.Relay.Blade.Credentials = client.Credentials
+	client.Relay.Blade.WireRecorder = client.WireRecorder
+	client.Relay.Blade.Failover = client.Failover
+	client.Relay.Blade.EventBus = client.EventBus
+	client.Relay.Blade.CallRegistry = client.CallRegistry
+	client.Relay.Blade.Interceptors = client.Interceptors
+	client.Relay.Blade.Store = client.Store
+	client.Relay.Blade.StoreTTL = client.StoreTTL
+
 	go func() {
 		err = client.connectInternal(client.Ctx, client.Cancel, &wg, nil)
 	}()
@@ -336,12 +507,12 @@ func (client *ClientSession) Connect() error {
 	<-client.Operational
 
 	if err != nil {
-		Log.Debug("cannot setup Blade: %v\n", err)
+		client.logger().Debug("cannot setup Blade: %v\n", err)
 
 		return errors.New("cannot setup Blade")
 	}
 
-	Log.Debug("Blade Client Ready...\n")
+	client.logger().Debug("Blade Client Ready...\n")
 
 	if client.OnReady != nil {
 		client.OnReady(client)
@@ -1,5 +1,7 @@
 package signalwire
 
+import "time"
+
 // SDK consts
 const (
 	WSTimeOut              = 5
@@ -9,11 +11,12 @@ const (
 	BladeVersionMajor      = 2
 	BladeVersionMinor      = 3
 	BladeRevision          = 0
-	MaxSimCalls            = 100
+	MaxSimCalls            = 100 // Deprecated: not used, the calls are held in a CallRegistry (see CallRegistryConfig.SoftLimit)
 	MaxPlay                = 10
 	TaskingEndpoint        = "https://relay.signalwire.com/api/relay/rest/tasks"
 	UserAgent              = "Go SDK"
-	BladeConnectionRetries = -1 // how many times to retry to connect before giving up . -1 keeps trying forever.
+	BladeConnectionRetries = -1                            // Deprecated: not used, see ReconnectPolicy.MaxAttempts
+	DefaultConnectTimeout  = (WSTimeOut + 1) * time.Second // we'll want to try connect on network timeout too (not only Blade)
 	/* internal */
 	BroadcastEventTimeout   = 10 /* seconds */
 	DefaultRingTimeout      = 30 /* seconds */
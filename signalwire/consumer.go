@@ -7,17 +7,20 @@ import (
 	"time"
 )
 
-// GlobalOverwriteHost TODO DESCRIPTION
-var GlobalOverwriteHost string
-
-var GlobalConnectTimeout time.Duration
-
 // Consumer TODO DESCRIPTION
 type Consumer struct {
-	Project              string
-	Token                string
-	Contexts             []string
-	Host                 string
+	Project  string
+	Token    string
+	Contexts []string
+	Host     string
+	// Hosts, if set, are used instead of Host: the consumer fails over between them
+	Hosts []RelayHost
+	// Failover controls when the consumer moves to another of the Hosts
+	Failover *FailoverPolicy
+	// EventBus sets up the event queues of the calls, nil means the defaults
+	EventBus *EventBusConfig
+	// CallRegistry sets the soft limit of concurrent calls, nil means no limit
+	CallRegistry         *CallRegistryConfig
 	Client               *ClientSession
 	Ready                func(*Consumer)
 	OnIncomingCall       func(*Consumer, *CallObj)
@@ -25,8 +28,42 @@ type Consumer struct {
 	OnMessageStateChange func(*Consumer, *MsgObj)
 	OnTask               func(*Consumer, ParamsEventTaskingTask)
 	Teardown             func(*Consumer)
+	Transport            *TransportConfig
+	ReconnectPolicy      *ReconnectPolicy
+	OutboundQueue        *OutboundQueue
+	// ConnectTimeout for Run to get the Blade session up, 0 means DefaultConnectTimeout
+	ConnectTimeout time.Duration
+	// Credentials, if set, are used instead of Project and Token: they are
+	// asked again on reconnect and before the JWT of the session expires.
+	Credentials CredentialsProvider
+	// WireRecorder, if set, captures the JSON-RPC frames of the Blade link
+	WireRecorder *WireRecorder
+	// OnConnectionStateChange is called on every Blade session state
+	// transition, from the go routine of the session: it must not block.
+	OnConnectionStateChange func(*Consumer, ConnectionStateChange)
+	// ConnectionStates, if set, receives every transition (dropped if full)
+	ConnectionStates chan ConnectionStateChange
+	// OnOrphanedCalls is called with the calls lost with the Blade session,
+	// when it could not be restored or migrated. They are ended with ErrSessionLost.
+	OnOrphanedCalls func(*Consumer, []*CallObj)
+	// Interceptors, if set, wrap every command sent and every event received
+	Interceptors *Interceptors
+	// Store, if set, keeps the calls, messages and tasking instead of a MemoryStore
+	Store Store
+	// StoreTTL is the retention of the entries of the store, 0 means CacheExpiry
+	StoreTTL time.Duration
+	// DrainTimeout is the time RunContext lets the inbound handlers finish
+	// once its context is done, 0 means DefaultDrainTimeout
+	DrainTimeout time.Duration
+	// HangupOnDrain hangs up the calls still up at the end of the drain
+	HangupOnDrain bool
+	// Admission, if set, limits the OnIncomingCall handlers (concurrency, rate)
+	Admission *AdmissionConfig
 
 	Log LoggerWrapper
+
+	handlers  handlerTracker
+	admission admission
 }
 
 // NewConsumer TODO DESCRIPTION
@@ -48,10 +85,8 @@ func (consumer *Consumer) Setup(project, token string, contexts []string) {
 	consumer.Project = project
 	consumer.Token = token
 
-	if len(GlobalOverwriteHost) == 0 {
+	if len(consumer.Host) == 0 {
 		consumer.Host = WssHost
-	} else {
-		consumer.Host = GlobalOverwriteHost
 	}
 
 	consumer.Contexts = contexts
@@ -64,12 +99,18 @@ func (consumer *Consumer) Setup(project, token string, contexts []string) {
 }
 
 func (consumer *Consumer) runOnIncomingCall(_ context.Context, call *CallSession) {
+	consumer.OnIncomingCall(consumer, consumer.newCallObj(call))
+}
+
+// newCallObj returns the CallObj of an inbound call
+func (consumer *Consumer) newCallObj(call *CallSession) *CallObj {
 	var I ICallObj = CallObjNew()
 
 	c := &CallObj{I: I}
 	c.call = call
 	c.Calling = &consumer.Client.Calling
-	consumer.OnIncomingCall(consumer, c)
+
+	return c
 }
 
 func (consumer *Consumer) runOnIncomingMessage(_ context.Context, msg *MsgSession) {
@@ -85,14 +126,16 @@ func (consumer *Consumer) incomingCall(ctx context.Context, wg *sync.WaitGroup)
 	for {
 		call, ierr := consumer.Client.I.waitInbound(ctx)
 		if ierr != nil {
-			Log.Error("Error processing incoming call: %v\n", ierr)
+			consumer.logger().Error("Error processing incoming call: %v\n", ierr)
 		} else if call == nil && ierr == nil {
 			wg.Done()
 			return
 		}
 
-		if call != nil {
-			go consumer.runOnIncomingCall(ctx, call)
+		if call != nil && !consumer.handlers.begin() {
+			consumer.logger().Debug("draining, ignoring incoming call\n")
+		} else if call != nil {
+			consumer.admitCall(ctx, call)
 		}
 	}
 }
@@ -101,29 +144,66 @@ func (consumer *Consumer) incomingMessage(ctx context.Context, wg *sync.WaitGrou
 	for {
 		msg, ierr := consumer.Client.I.waitInboundMsg(ctx)
 		if ierr != nil {
-			Log.Error("Error processing incoming msg: %v\n", ierr)
+			consumer.logger().Error("Error processing incoming msg: %v\n", ierr)
 		} else if msg == nil && ierr == nil {
 			wg.Done()
 			return
 		}
 
-		if msg != nil {
-			go consumer.runOnIncomingMessage(ctx, msg)
+		if msg != nil && !consumer.handlers.begin() {
+			consumer.logger().Debug("draining, ignoring incoming msg\n")
+		} else if msg != nil {
+			go func() {
+				defer consumer.handlers.end()
+
+				consumer.runOnIncomingMessage(ctx, msg)
+			}()
 		}
 	}
 }
 
 // Run TODO DESCRIPTION
 func (consumer *Consumer) Run() error {
+	return consumer.RunContext(context.Background())
+}
+
+// RunContext runs the consumer until it is stopped or until runCtx is done.
+// When runCtx is done, it stops taking inbound calls and messages, lets the
+// running handlers finish (DrainTimeout), optionally hangs up the calls left
+// (HangupOnDrain), then disconnects. The error summarizes an unclean drain.
+func (consumer *Consumer) RunContext(runCtx context.Context) error {
+	consumer.handlers.reset()
+	consumer.admission.setConfig(consumer.Admission)
 	consumer.Client.setClient(consumer.Host, consumer.Contexts)
 	consumer.Client.setAuth(consumer.Project, consumer.Token)
+	consumer.Client.Transport = consumer.Transport
+	consumer.Client.Relay.Blade.Transport = consumer.Transport
+	consumer.Client.ReconnectPolicy = consumer.ReconnectPolicy
+	consumer.Client.Relay.Blade.ReconnectPolicy = consumer.ReconnectPolicy
+	consumer.Client.OutboundQueue = consumer.OutboundQueue
+	consumer.Client.Log = consumer.Log
+	consumer.Client.Relay.Blade.OutboundQueue = consumer.OutboundQueue
+	consumer.Client.Credentials = consumer.Credentials
+	consumer.Client.Relay.Blade.Credentials = consumer.Credentials
+	consumer.Client.WireRecorder = consumer.WireRecorder
+	consumer.Client.Relay.Blade.WireRecorder = consumer.WireRecorder
+	consumer.Client.Hosts = consumer.Hosts
+	consumer.Client.Failover = consumer.Failover
+	consumer.Client.Relay.Blade.Failover = consumer.Failover
+	consumer.Client.EventBus = consumer.EventBus
+	consumer.Client.Relay.Blade.EventBus = consumer.EventBus
+	consumer.Client.CallRegistry = consumer.CallRegistry
+	consumer.Client.Relay.Blade.CallRegistry = consumer.CallRegistry
+	consumer.Client.Interceptors = consumer.Interceptors
+	consumer.Client.Relay.Blade.Interceptors = consumer.Interceptors
+	consumer.Client.Store = consumer.Store
+	consumer.Client.Relay.Blade.Store = consumer.Store
+	consumer.Client.StoreTTL = consumer.StoreTTL
+	consumer.Client.Relay.Blade.StoreTTL = consumer.StoreTTL
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	var (
-		err error
-		wg  sync.WaitGroup
-	)
+	var wg sync.WaitGroup
 
 	var haveIncomingMsg int
 
@@ -141,26 +221,37 @@ func (consumer *Consumer) Run() error {
 
 	wg.Add(haveIncomingMsg + haveIncomingCalls + 1)
 
-	if GlobalConnectTimeout == 0 {
-		GlobalConnectTimeout = WSTimeOut + 1 // we'll want to try connect on network timeout too (not only Blade)
+	if consumer.ConnectTimeout > 0 {
+		consumer.Client.ConnectTimeout = consumer.ConnectTimeout
 	}
 
-	timer := time.NewTimer(GlobalConnectTimeout * time.Second)
+	timer := time.NewTimer(consumer.Client.connectTimeout())
+	errc := make(chan error, 1)
 
 	go func() {
-		err = consumer.Client.I.connectInternal(ctx, cancel, &wg, timer)
+		err := consumer.Client.I.connectInternal(ctx, cancel, &wg, timer)
 		if err != nil {
-			Log.Error("Cannot setup Blade: %v\n", err)
+			consumer.logger().Error("Cannot setup Blade: %v\n", err)
 		}
+
+		errc <- err
 	}()
 
 	select {
 	case <-consumer.Client.Operational:
+	case <-runCtx.Done():
+		return consumer.abortConnect(cancel, errc, runCtx.Err())
+	case err := <-errc:
+		_ = consumer.Client.Relay.Blade.BladeCleanup()
+
+		cancel()
+
+		return err
 	case <-timer.C:
-		return errors.New("cannot setup Blade (timeout)")
+		return consumer.abortConnect(cancel, errc, errors.New("cannot setup Blade (timeout)"))
 	}
 
-	Log.Debug("Blade Ready...\n")
+	consumer.logger().Debug("Blade Ready...\n")
 
 	consumer.Client.setupInbound()
 	consumer.Client.setupInboundMsg()
@@ -171,21 +262,54 @@ func (consumer *Consumer) Run() error {
 
 	if consumer.OnIncomingCall != nil {
 		go consumer.incomingCall(ctx, &wg)
-		Log.Debug("OnIncomingCall CB enabled\n")
+		consumer.logger().Debug("OnIncomingCall CB enabled\n")
 	}
 
 	if consumer.OnIncomingMessage != nil {
 		go consumer.incomingMessage(ctx, &wg)
-		Log.Debug("OnIncomingMessage CB enabled\n")
+		consumer.logger().Debug("OnIncomingMessage CB enabled\n")
 	}
 
+	drained := make(chan error, 1)
+
+	go func() {
+		select {
+		case <-runCtx.Done():
+			drained <- consumer.drain()
+		case <-ctx.Done():
+			drained <- nil
+		}
+	}()
+
 	wg.Wait()
 
-	Log.Debug("consumer()/Run() stopped.\n")
+	consumer.logger().Debug("consumer()/Run() stopped.\n")
+
+	cancel()
+
+	if err := <-drained; err != nil {
+		return err
+	}
+
+	if consumer.Client.Relay.Blade.LastError == ErrReconnectGaveUp {
+		return ErrReconnectGaveUp
+	}
 
 	return nil
 }
 
+// abortConnect stops a connection not up yet: once the connect go routine
+// has returned, the Blade link is closed
+func (consumer *Consumer) abortConnect(cancel context.CancelFunc, errc chan error, err error) error {
+	cancel()
+
+	<-errc
+
+	_ = consumer.Client.Relay.Blade.BladeCleanup()
+
+	return err
+}
+
 // Stop TODO DESCRIPTION
 func (consumer *Consumer) Stop() error {
 	if consumer.Teardown != nil {
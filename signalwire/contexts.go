@@ -0,0 +1,130 @@
+package signalwire
+
+import (
+	"context"
+	"errors"
+)
+
+// GetContexts returns the contexts the session receives inbound calls and messages on
+func (blade *BladeSession) GetContexts() []string {
+	blade.Tmutex.Lock()
+	defer blade.Tmutex.Unlock()
+
+	return append([]string(nil), blade.SignalwireContexts...)
+}
+
+func (blade *BladeSession) setContexts(contexts []string) {
+	blade.Tmutex.Lock()
+	blade.SignalwireContexts = contexts
+	blade.Tmutex.Unlock()
+}
+
+// BladeAddContexts subscribes the live session to the new contexts. They are
+// remembered and subscribed again when the session is set up after a reconnect.
+func (blade *BladeSession) BladeAddContexts(ctx context.Context, contexts []string) error {
+	if blade == nil {
+		return errors.New("empty blade session object")
+	}
+
+	blade.contextsMutex.Lock()
+	defer blade.contextsMutex.Unlock()
+
+	current := blade.GetContexts()
+	added := contextsDiff(contexts, current)
+
+	if len(added) == 0 {
+		return nil
+	}
+
+	// set first, so that a session set up in the meantime gets them too
+	blade.setContexts(append(current, added...))
+
+	if err := blade.I.BladeSignalwireReceive(ctx, added); err != nil {
+		blade.setContexts(contextsDiff(blade.GetContexts(), added))
+
+		return err
+	}
+
+	return nil
+}
+
+// BladeRemoveContexts unsubscribes the live session from the contexts
+func (blade *BladeSession) BladeRemoveContexts(ctx context.Context, contexts []string) error {
+	if blade == nil {
+		return errors.New("empty blade session object")
+	}
+
+	blade.contextsMutex.Lock()
+	defer blade.contextsMutex.Unlock()
+
+	current := blade.GetContexts()
+	removed := contextsDiff(contexts, contextsDiff(contexts, current))
+
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := blade.I.BladeSignalwireUnreceive(ctx, removed); err != nil {
+		return err
+	}
+
+	blade.setContexts(contextsDiff(blade.GetContexts(), removed))
+
+	return nil
+}
+
+// contextsDiff returns the contexts of a that are not in b, without duplicates
+func contextsDiff(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+
+	for _, c := range b {
+		seen[c] = true
+	}
+
+	var diff []string
+
+	for _, c := range a {
+		if len(c) == 0 || seen[c] {
+			continue
+		}
+
+		seen[c] = true
+		diff = append(diff, c)
+	}
+
+	return diff
+}
+
+// AddContexts starts receiving inbound calls and messages on the contexts, without reconnecting
+func (client *ClientSession) AddContexts(contexts []string) error {
+	return client.Relay.Blade.BladeAddContexts(client.Ctx, contexts)
+}
+
+// RemoveContexts stops receiving inbound calls and messages on the contexts
+func (client *ClientSession) RemoveContexts(contexts []string) error {
+	return client.Relay.Blade.BladeRemoveContexts(client.Ctx, contexts)
+}
+
+// AddContexts starts receiving inbound calls and messages on the contexts, without reconnecting
+func (consumer *Consumer) AddContexts(contexts []string) error {
+	if consumer.Client == nil || consumer.Client.Relay.Blade == nil {
+		return errors.New("consumer not running")
+	}
+
+	err := consumer.Client.AddContexts(contexts)
+	consumer.Contexts = consumer.Client.Relay.Blade.GetContexts()
+
+	return err
+}
+
+// RemoveContexts stops receiving inbound calls and messages on the contexts
+func (consumer *Consumer) RemoveContexts(contexts []string) error {
+	if consumer.Client == nil || consumer.Client.Relay.Blade == nil {
+		return errors.New("consumer not running")
+	}
+
+	err := consumer.Client.RemoveContexts(contexts)
+	consumer.Contexts = consumer.Client.Relay.Blade.GetContexts()
+
+	return err
+}
@@ -0,0 +1,247 @@
+package signalwire
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	jsonrpc2 "github.com/sourcegraph/jsonrpc2"
+)
+
+// Credentials refresh defaults
+const (
+	// DefaultCredentialsRefreshMargin is how long before expiry the credentials are refreshed
+	DefaultCredentialsRefreshMargin = 60 * time.Second
+	// DefaultCredentialsRetryDelay is the wait before retrying a failed refresh
+	DefaultCredentialsRetryDelay = 5 * time.Second
+)
+
+// Credentials authenticate the Blade session, with either a project token or a JWT
+type Credentials struct {
+	Project string
+	Token   string
+	// JWT is a short lived token, used instead of Token when set
+	JWT string
+}
+
+// CredentialsProvider supplies the credentials when connecting, when
+// reconnecting and before the JWT of the running session expires.
+type CredentialsProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialsFunc adapts a function to CredentialsProvider
+type CredentialsFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials calls f(ctx)
+func (f CredentialsFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+// ScopeError is returned when the credentials do not grant access to the requested contexts
+type ScopeError struct {
+	Contexts []string
+	Code     string
+	Message  string
+}
+
+func (e *ScopeError) Error() string {
+	return fmt.Sprintf("contexts %v not authorized (%s): %s", e.Contexts, e.Code, e.Message)
+}
+
+// isScopeErrorCode is true for the reply codes of an unauthorized request
+func isScopeErrorCode(code string) bool {
+	return code == "401" || code == "403"
+}
+
+// bladeAuthState tracks the expiry of the credentials of the session
+type bladeAuthState struct {
+	authorization ReplyAuthStruct
+	jwt           bool
+	obtained      time.Time
+	retryAt       time.Time
+	updated       chan struct{}
+}
+
+// authentication returns the credentials to present to Blade, from the provider if any
+func (blade *BladeSession) authentication(ctx context.Context, bladeAuth *BladeAuth) (AuthStruct, error) {
+	if blade.Credentials != nil {
+		creds, err := blade.Credentials.Credentials(ctx)
+		if err != nil {
+			return AuthStruct{}, err
+		}
+
+		if len(creds.Project) == 0 || (len(creds.Token) == 0 && len(creds.JWT) == 0) {
+			return AuthStruct{}, errors.New("no auth")
+		}
+
+		if len(creds.JWT) > 0 {
+			return AuthStruct{Project: creds.Project, JWTToken: creds.JWT}, nil
+		}
+
+		return AuthStruct{Project: creds.Project, Token: creds.Token}, nil
+	}
+
+	if bladeAuth == nil {
+		bladeAuth = &blade.bladeAuth
+	}
+
+	if len(bladeAuth.ProjectID) == 0 || len(bladeAuth.TokenID) == 0 {
+		return AuthStruct{}, errors.New("no auth")
+	}
+
+	return AuthStruct{Project: bladeAuth.ProjectID, Token: bladeAuth.TokenID}, nil
+}
+
+// setAuthorization keeps the authorization granted by Blade and wakes up the refresher
+func (blade *BladeSession) setAuthorization(authorization ReplyAuthStruct, jwt bool) {
+	blade.Tmutex.Lock()
+
+	blade.auth.authorization = authorization
+	blade.auth.jwt = jwt
+	blade.auth.obtained = time.Now()
+	blade.auth.retryAt = time.Time{}
+
+	if blade.auth.updated == nil {
+		blade.auth.updated = make(chan struct{}, 1)
+	}
+
+	updated := blade.auth.updated
+
+	blade.Tmutex.Unlock()
+
+	select {
+	case updated <- struct{}{}:
+	default:
+	}
+}
+
+// Authorization returns the authorization granted by Blade to the session
+func (blade *BladeSession) Authorization() ReplyAuthStruct {
+	blade.Tmutex.Lock()
+	defer blade.Tmutex.Unlock()
+
+	return blade.auth.authorization
+}
+
+// credentialsRefreshAt returns when the JWT must be refreshed, false if it does not expire
+func (blade *BladeSession) credentialsRefreshAt() (time.Time, bool) {
+	blade.Tmutex.Lock()
+	defer blade.Tmutex.Unlock()
+
+	expiresAt := blade.auth.authorization.ExpiresAt.Time
+	if !blade.auth.jwt || expiresAt.IsZero() {
+		return time.Time{}, false
+	}
+
+	margin := DefaultCredentialsRefreshMargin
+	if lifetime := expiresAt.Sub(blade.auth.obtained); lifetime < 2*margin {
+		margin = lifetime / 2
+	}
+
+	at := expiresAt.Add(-margin)
+	if blade.auth.retryAt.After(at) {
+		at = blade.auth.retryAt
+	}
+
+	return at, true
+}
+
+// refreshCredentials renews the JWT of the running session before it expires, until ctx is done
+func (blade *BladeSession) refreshCredentials(ctx context.Context) {
+	blade.Tmutex.Lock()
+
+	if blade.auth.updated == nil {
+		blade.auth.updated = make(chan struct{}, 1)
+	}
+
+	updated := blade.auth.updated
+
+	blade.Tmutex.Unlock()
+
+	for {
+		var (
+			timer *time.Timer
+			wait  <-chan time.Time
+		)
+
+		if at, ok := blade.credentialsRefreshAt(); ok {
+			timer = time.NewTimer(time.Until(at))
+			wait = timer.C
+		}
+
+		select {
+		case <-wait:
+			if err := blade.BladeReauthenticate(ctx); err != nil {
+				blade.logger().Error("cannot refresh credentials: %v\n", err)
+
+				blade.Tmutex.Lock()
+				blade.auth.retryAt = time.Now().Add(DefaultCredentialsRetryDelay)
+				blade.Tmutex.Unlock()
+			}
+		case <-updated:
+		case <-ctx.Done():
+		}
+
+		if timer != nil {
+			timer.Stop()
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// BladeReauthenticate presents fresh credentials to the running session
+func (blade *BladeSession) BladeReauthenticate(ctx context.Context) error {
+	if blade == nil {
+		return errors.New("empty blade session object")
+	}
+
+	if blade.GetState() != BladeRunning {
+		// the credentials are asked again on reconnect
+		return errors.New("blade session not running")
+	}
+
+	conn, _ := blade.GetConnection()
+	if conn == nil {
+		return errors.New("invalid connection")
+	}
+
+	auth, err := blade.authentication(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if len(auth.JWTToken) == 0 {
+		return errors.New("cannot reauthenticate without a JWT")
+	}
+
+	var reply ReplyResultReauthenticate
+
+	reqID, _ := GenUUIDv4()
+
+	id := jsonrpc2.ID{
+		Str:      reqID,
+		IsString: true,
+	}
+
+	if err := conn.Call(
+		ctx, "blade.reauthenticate",
+		ParamsReauthenticateStruct{
+			SessionID:      blade.SessionID,
+			Authentication: auth,
+		},
+		&reply, jsonrpc2.PickID(id),
+	); err != nil {
+		return err
+	}
+
+	blade.setAuthorization(reply.Authorization, true)
+
+	blade.logger().Debug("credentials refreshed, expire at %v\n", reply.Authorization.ExpiresAt.Time)
+
+	return nil
+}
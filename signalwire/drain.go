@@ -0,0 +1,180 @@
+package signalwire
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultDrainTimeout is the time RunContext lets the handlers finish on shutdown
+const DefaultDrainTimeout = 30 * time.Second
+
+// DrainError summarizes a shutdown that did not drain cleanly
+type DrainError struct {
+	// Running is the number of OnIncomingCall/OnIncomingMessage handlers still running at the deadline
+	Running int
+	// HungUp is the number of calls hung up (HangupOnDrain)
+	HungUp int
+	// Errors met while draining
+	Errors []error
+}
+
+func (e *DrainError) Error() string {
+	return fmt.Sprintf("drain: %d handlers still running, %d calls hung up, errors: %v", e.Running, e.HungUp, e.Errors)
+}
+
+// handlerTracker counts the inbound handlers running, and refuses new ones once draining
+type handlerTracker struct {
+	sync.Mutex
+	n        int
+	draining bool
+	idle     chan struct{}
+}
+
+// reset accepts the handlers again, for a new run
+func (t *handlerTracker) reset() {
+	t.Lock()
+	t.draining = false
+	t.Unlock()
+}
+
+// begin is false if draining, the handler must not run
+func (t *handlerTracker) begin() bool {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.draining {
+		return false
+	}
+
+	t.n++
+
+	return true
+}
+
+func (t *handlerTracker) end() {
+	t.Lock()
+
+	t.n--
+
+	if t.n == 0 && t.idle != nil {
+		close(t.idle)
+		t.idle = nil
+	}
+
+	t.Unlock()
+}
+
+// drain refuses the new handlers, the channel is closed once the running ones are done
+func (t *handlerTracker) drain() <-chan struct{} {
+	t.Lock()
+	defer t.Unlock()
+
+	t.draining = true
+	idle := make(chan struct{})
+
+	if t.n == 0 {
+		close(idle)
+	} else {
+		t.idle = idle
+	}
+
+	return idle
+}
+
+func (t *handlerTracker) running() int {
+	t.Lock()
+	defer t.Unlock()
+
+	return t.n
+}
+
+func (consumer *Consumer) drainTimeout() time.Duration {
+	if consumer.DrainTimeout > 0 {
+		return consumer.DrainTimeout
+	}
+
+	return DefaultDrainTimeout
+}
+
+// drain stops taking inbound calls and messages, waits for the handlers
+// up to DrainTimeout, hangs up the calls left if asked to and disconnects.
+func (consumer *Consumer) drain() error {
+	consumer.logger().Info("draining, up to %v\n", consumer.drainTimeout())
+
+	var (
+		summary DrainError
+		blade   = consumer.Client.Relay.Blade
+	)
+
+	idle := consumer.handlers.drain()
+
+	// let the platform route the new calls and messages to the other consumers
+	if contexts := blade.GetContexts(); len(contexts) > 0 {
+		if err := blade.I.BladeSignalwireUnreceive(consumer.Client.Ctx, contexts); err != nil {
+			summary.Errors = append(summary.Errors, err)
+		}
+	}
+
+	timer := time.NewTimer(consumer.drainTimeout())
+	defer timer.Stop()
+
+	select {
+	case <-idle:
+	case <-timer.C:
+		summary.Running = consumer.handlers.running()
+
+		consumer.logger().Warn("drain timeout, %d handlers still running\n", summary.Running)
+	}
+
+	if consumer.HangupOnDrain {
+		summary.HungUp = consumer.hangupCalls(consumer.Client.Ctx, &summary)
+	}
+
+	if err := consumer.Stop(); err != nil {
+		summary.Errors = append(summary.Errors, err)
+	}
+
+	if summary.Running > 0 || len(summary.Errors) > 0 {
+		return &summary
+	}
+
+	return nil
+}
+
+// hangupCalls ends the calls still up, returns how many
+func (consumer *Consumer) hangupCalls(ctx context.Context, summary *DrainError) int {
+	calls, err := consumer.Client.Relay.Blade.EventCalling.Cache.GetAllCallsCache()
+	if err != nil {
+		summary.Errors = append(summary.Errors, err)
+
+		return 0
+	}
+
+	var n int
+
+	for _, call := range calls {
+		switch call.GetState() {
+		case Ending, Ended:
+			continue
+		}
+
+		if len(call.CallID) == 0 {
+			continue
+		}
+
+		var payload *json.RawMessage
+
+		if err := consumer.Client.Calling.Relay.RelayCallEnd(ctx, call, &payload); err != nil {
+			summary.Errors = append(summary.Errors, err)
+
+			continue
+		}
+
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,257 @@
+package signalwire
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultEventQueueSize is the number of events a call can have waiting for delivery
+const DefaultEventQueueSize = 64
+
+// ErrEventQueueFull is returned when an event is dropped by EventOverflowError
+var ErrEventQueueFull = errors.New("call event queue full, event dropped")
+
+// EventOverflow is what the event bus of a call does when its queue is full
+type EventOverflow int
+
+// Overflow policies
+const (
+	// EventOverflowBlock waits for room in the queue, holding up the Blade
+	// session: no event is lost, the default
+	EventOverflowBlock EventOverflow = iota
+	// EventOverflowDropOldest drops the oldest queued event to make room
+	EventOverflowDropOldest
+	// EventOverflowError drops the new event
+	EventOverflowError
+)
+
+func (o EventOverflow) String() string {
+	if o < EventOverflowBlock || o > EventOverflowError {
+		return "Unknown"
+	}
+
+	return [...]string{"Block", "DropOldest", "Error"}[o]
+}
+
+// EventBusConfig sets up the event bus of the calls. Each call has its own
+// queue and go routine, so that a slow or stuck call does not hold up the
+// events of the other calls.
+type EventBusConfig struct {
+	// QueueSize is the max number of events queued per call, 0 means DefaultEventQueueSize
+	QueueSize int
+	// Overflow is the policy when the queue of a call is full, EventOverflowBlock by default
+	Overflow EventOverflow
+	// BlockTimeout caps the wait of EventOverflowBlock, the event is then dropped (0 means no cap)
+	BlockTimeout time.Duration
+}
+
+// EventStats counts the events of a call, or of all the calls of a session
+type EventStats struct {
+	// Delivered events
+	Delivered uint64
+	// Dropped events, on queue overflow
+	Dropped uint64
+	// Delayed events, that waited for room in the queue (EventOverflowBlock)
+	Delayed uint64
+}
+
+// eventCounters is the runtime side of EventStats
+type eventCounters struct {
+	sync.Mutex
+	EventStats
+}
+
+func (c *eventCounters) add(delivered, dropped, delayed uint64) {
+	if c == nil {
+		return
+	}
+
+	c.Lock()
+
+	c.Delivered += delivered
+	c.Dropped += dropped
+	c.Delayed += delayed
+
+	c.Unlock()
+}
+
+func (c *eventCounters) get() EventStats {
+	c.Lock()
+	defer c.Unlock()
+
+	return c.EventStats
+}
+
+// eventBus delivers the events of a call in order, from a go routine of
+// its own that runs while there are events queued.
+type eventBus struct {
+	sync.Mutex
+	queue   []func()
+	running bool
+	space   chan struct{}
+	stats   eventCounters
+}
+
+// enqueue queues the delivery of an event, session counts it for the whole session
+func (bus *eventBus) enqueue(cfg *EventBusConfig, session *eventCounters, fn func()) error {
+	var c EventBusConfig
+
+	if cfg != nil {
+		c = *cfg
+	}
+
+	if c.QueueSize <= 0 {
+		c.QueueSize = DefaultEventQueueSize
+	}
+
+	var (
+		timer   *time.Timer
+		timeout <-chan time.Time
+		delayed bool
+	)
+
+	bus.Lock()
+
+	if bus.space == nil {
+		bus.space = make(chan struct{}, 1)
+	}
+
+	for len(bus.queue) >= c.QueueSize {
+		switch c.Overflow {
+		case EventOverflowDropOldest:
+			bus.queue[0] = nil
+			bus.queue = bus.queue[1:]
+
+			bus.dropped(session)
+		case EventOverflowBlock:
+			if !delayed {
+				delayed = true
+
+				bus.stats.add(0, 0, 1)
+				session.add(0, 0, 1)
+
+				if c.BlockTimeout > 0 {
+					timer = time.NewTimer(c.BlockTimeout)
+					defer timer.Stop()
+
+					timeout = timer.C
+				}
+			}
+
+			space := bus.space
+
+			bus.Unlock()
+
+			select {
+			case <-space:
+			case <-timeout:
+				bus.Lock()
+				bus.dropped(session)
+				bus.Unlock()
+
+				return ErrEventQueueFull
+			}
+
+			bus.Lock()
+		default:
+			bus.dropped(session)
+			bus.Unlock()
+
+			return ErrEventQueueFull
+		}
+	}
+
+	bus.queue = append(bus.queue, fn)
+
+	if !bus.running {
+		bus.running = true
+
+		go bus.run(session)
+	}
+
+	bus.Unlock()
+
+	return nil
+}
+
+// dropped counts a dropped event, the lock is held
+func (bus *eventBus) dropped(session *eventCounters) {
+	bus.stats.add(0, 1, 0)
+	session.add(0, 1, 0)
+}
+
+func (bus *eventBus) run(session *eventCounters) {
+	for {
+		bus.Lock()
+
+		if len(bus.queue) == 0 {
+			bus.running = false
+			bus.Unlock()
+
+			return
+		}
+
+		fn := bus.queue[0]
+		bus.queue[0] = nil
+		bus.queue = bus.queue[1:]
+		space := bus.space
+
+		bus.Unlock()
+
+		select {
+		case space <- struct{}{}:
+		default:
+		}
+
+		fn()
+
+		bus.stats.add(1, 0, 0)
+		session.add(1, 0, 0)
+	}
+}
+
+// deliver queues the delivery of an event to the channels of the call
+func (calling *EventCalling) deliver(call *CallSession, fn func()) error {
+	blade := calling.blade
+	if blade == nil {
+		fn()
+
+		return nil
+	}
+
+	err := call.bus.enqueue(blade.EventBus, &blade.eventStats, fn)
+	if err != nil {
+		calling.logger().Warn("call [%s]: %v\n", call.GetCallID(), err)
+	}
+
+	return err
+}
+
+// EventStats returns the event counters of all the calls of the session
+func (blade *BladeSession) EventStats() EventStats {
+	return blade.eventStats.get()
+}
+
+// EventStats returns the event counters of all the calls of the session
+func (client *ClientSession) EventStats() EventStats {
+	if client.Relay.Blade == nil {
+		return EventStats{}
+	}
+
+	return client.Relay.Blade.EventStats()
+}
+
+// EventStats returns the event counters of all the calls of the consumer
+func (consumer *Consumer) EventStats() EventStats {
+	if consumer.Client == nil {
+		return EventStats{}
+	}
+
+	return consumer.Client.EventStats()
+}
+
+// EventStats returns the event counters of the call
+func (callobj *CallObj) EventStats() EventStats {
+	return callobj.call.bus.stats.get()
+}
@@ -0,0 +1,97 @@
+package signalwire
+
+import (
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestEventBus(t *testing.T) {
+	// stuck returns a bus whose go routine is held by the first event, until release is closed
+	stuck := func(cfg *EventBusConfig, session *eventCounters) (*eventBus, chan struct{}) {
+		bus := new(eventBus)
+		release := make(chan struct{})
+		started := make(chan struct{})
+
+		assert.Nil(t, bus.enqueue(cfg, session, func() {
+			close(started)
+			<-release
+		}))
+		<-started
+
+		return bus, release
+	}
+
+	t.Run(
+		"DropOldest",
+		func(t *testing.T) {
+			var (
+				session eventCounters
+				got     []int
+				done    = make(chan struct{})
+			)
+
+			cfg := &EventBusConfig{QueueSize: 2, Overflow: EventOverflowDropOldest}
+			bus, release := stuck(cfg, &session)
+
+			for i := 1; i <= 4; i++ {
+				i := i
+				assert.Nil(t, bus.enqueue(cfg, &session, func() {
+					got = append(got, i)
+					if i == 4 {
+						close(done)
+					}
+				}))
+			}
+
+			close(release)
+			<-done
+
+			assert.Equal(t, []int{3, 4}, got, "the oldest events must be dropped")
+			assert.Equal(t, uint64(2), session.get().Dropped)
+		},
+	)
+	t.Run(
+		"Error",
+		func(t *testing.T) {
+			cfg := &EventBusConfig{QueueSize: 1, Overflow: EventOverflowError}
+			bus, release := stuck(cfg, nil)
+
+			defer close(release)
+
+			assert.Nil(t, bus.enqueue(cfg, nil, func() {}))
+			assert.Equal(t, ErrEventQueueFull, bus.enqueue(cfg, nil, func() {}))
+			assert.Equal(t, uint64(1), bus.stats.get().Dropped)
+		},
+	)
+	t.Run(
+		"Block",
+		func(t *testing.T) {
+			cfg := &EventBusConfig{QueueSize: 1}
+			assert.Equal(t, EventOverflowBlock, cfg.Overflow, "no event must be lost by default")
+
+			bus, release := stuck(cfg, nil)
+
+			assert.Nil(t, bus.enqueue(cfg, nil, func() {}))
+
+			go func() {
+				time.Sleep(50 * time.Millisecond)
+				close(release)
+			}()
+
+			assert.Nil(t, bus.enqueue(cfg, nil, func() {}), "must wait for room")
+			assert.Equal(t, uint64(1), bus.stats.get().Delayed)
+			assert.Equal(t, uint64(0), bus.stats.get().Dropped)
+
+			cfg.BlockTimeout = 10 * time.Millisecond
+			bus, release = stuck(cfg, nil)
+
+			defer close(release)
+
+			assert.Nil(t, bus.enqueue(cfg, nil, func() {}))
+			assert.Equal(t, ErrEventQueueFull, bus.enqueue(cfg, nil, func() {}), "must give up after BlockTimeout")
+			assert.Equal(t, "Unknown", EventOverflow(42).String())
+		},
+	)
+}
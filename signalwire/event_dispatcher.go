@@ -105,7 +105,7 @@ const (
 )
 
 // callConnectStateFromStr TODO DESCRIPTION
-func (*EventCalling) callConnectStateFromStr(s string) (CallConnectState, error) {
+func (calling *EventCalling) callConnectStateFromStr(s string) (CallConnectState, error) {
 	var state CallConnectState
 
 	switch strings.ToLower(s) {
@@ -121,7 +121,7 @@ func (*EventCalling) callConnectStateFromStr(s string) (CallConnectState, error)
 		return state, errors.New("invalid CallConnectState")
 	}
 
-	Log.Debug("state [%s] [%s]\n", s, state.String())
+	calling.logger().Debug("state [%s] [%s]\n", s, state.String())
 
 	return state, nil
 }
@@ -141,7 +141,7 @@ func (*EventCalling) callDirectionFromStr(s string) (CallDirection, error) {
 	return dir, nil
 }
 
-func (*EventCalling) callStateFromStr(s string) (CallState, error) {
+func (calling *EventCalling) callStateFromStr(s string) (CallState, error) {
 	var state CallState
 
 	switch strings.ToLower(s) {
@@ -159,13 +159,13 @@ func (*EventCalling) callStateFromStr(s string) (CallState, error) {
 		return state, errors.New("invalid CallState")
 	}
 
-	Log.Debug("callstate [%s] [%s]\n", s, state.String())
+	calling.logger().Debug("callstate [%s] [%s]\n", s, state.String())
 
 	return state, nil
 }
 
 // callConnectStateFromStr TODO DESCRIPTION
-func (*EventCalling) callPlayStateFromStr(s string) (PlayState, error) {
+func (calling *EventCalling) callPlayStateFromStr(s string) (PlayState, error) {
 	var state PlayState
 
 	switch strings.ToLower(s) {
@@ -181,13 +181,13 @@ func (*EventCalling) callPlayStateFromStr(s string) (PlayState, error) {
 		return state, errors.New("invalid PlayState")
 	}
 
-	Log.Debug("state [%s] [%s]\n", s, state.String())
+	calling.logger().Debug("state [%s] [%s]\n", s, state.String())
 
 	return state, nil
 }
 
 // callRecordStateFromStr TODO DESCRIPTION
-func (*EventCalling) callRecordStateFromStr(s string) (RecordState, error) {
+func (calling *EventCalling) callRecordStateFromStr(s string) (RecordState, error) {
 	var state RecordState
 
 	switch strings.ToLower(s) {
@@ -201,7 +201,7 @@ func (*EventCalling) callRecordStateFromStr(s string) (RecordState, error) {
 		return state, errors.New("invalid RecordState")
 	}
 
-	Log.Debug("state [%s] [%s]\n", s, state.String())
+	calling.logger().Debug("state [%s] [%s]\n", s, state.String())
 
 	return state, nil
 }
@@ -330,7 +330,7 @@ func (*EventCalling) callDisconnectReasonFromStr(r string) (CallDisconnectReason
 }
 
 // callTapStateFromStr TODO DESCRIPTION
-func (*EventCalling) callTapStateFromStr(s string) (TapState, error) {
+func (calling *EventCalling) callTapStateFromStr(s string) (TapState, error) {
 	var state TapState
 
 	switch strings.ToLower(s) {
@@ -342,13 +342,13 @@ func (*EventCalling) callTapStateFromStr(s string) (TapState, error) {
 		return state, errors.New("invalid Tap State")
 	}
 
-	Log.Debug("state [%s] [%s]\n", s, state.String())
+	calling.logger().Debug("state [%s] [%s]\n", s, state.String())
 
 	return state, nil
 }
 
 // callSendDigitsStateFromStr TODO DESCRIPTION
-func (*EventCalling) callSendDigitsStateFromStr(s string) (SendDigitsState, error) {
+func (calling *EventCalling) callSendDigitsStateFromStr(s string) (SendDigitsState, error) {
 	var state SendDigitsState
 
 	switch strings.ToLower(s) {
@@ -358,13 +358,13 @@ func (*EventCalling) callSendDigitsStateFromStr(s string) (SendDigitsState, erro
 		return state, errors.New("invalid Send Digits State")
 	}
 
-	Log.Debug("state [%s] [%s]\n", s, state.String())
+	calling.logger().Debug("state [%s] [%s]\n", s, state.String())
 
 	return state, nil
 }
 
 // callPlayAndCollectStateFromStr TODO DESCRIPTION
-func (*EventCalling) callPlayAndCollectStateFromStr(s string) (CollectResultType, error) {
+func (calling *EventCalling) callPlayAndCollectStateFromStr(s string) (CollectResultType, error) {
 	var resType CollectResultType
 
 	switch strings.ToLower(s) {
@@ -384,7 +384,7 @@ func (*EventCalling) callPlayAndCollectStateFromStr(s string) (CollectResultType
 		return resType, errors.New("invalid PlayAndCollect result type")
 	}
 
-	Log.Debug("resType [%s] [%s]\n", s, resType.String())
+	calling.logger().Debug("resType [%s] [%s]\n", s, resType.String())
 
 	return resType, nil
 }
@@ -404,7 +404,7 @@ func (*EventMessaging) msgDirectionFromStr(s string) (MsgDirection, error) {
 	return dir, nil
 }
 
-func (*EventMessaging) msgStateFromStr(s string) (MsgState, error) {
+func (messaging *EventMessaging) msgStateFromStr(s string) (MsgState, error) {
 	var state MsgState
 
 	switch strings.ToLower(s) {
@@ -426,12 +426,12 @@ func (*EventMessaging) msgStateFromStr(s string) (MsgState, error) {
 		return state, errors.New("invalid MsgState")
 	}
 
-	Log.Debug("msgstate [%s] [%s]\n", s, state.String())
+	messaging.logger().Debug("msgstate [%s] [%s]\n", s, state.String())
 
 	return state, nil
 }
 
-func getBroadcastGeneric(_ context.Context, in, out interface{}) error {
+func getBroadcastGeneric(_ context.Context, log LoggerWrapper, in, out interface{}) error {
 	var (
 		jsonData []byte
 		err      error
@@ -439,13 +439,13 @@ func getBroadcastGeneric(_ context.Context, in, out interface{}) error {
 
 	jsonData, err = json.Marshal(in)
 	if err != nil {
-		Log.Error("error marshaling Params\n")
+		log.Error("error marshaling Params\n")
 
 		return err
 	}
 
 	if err = json.Unmarshal(jsonData, out); err != nil {
-		Log.Error("error unmarshaling\n")
+		log.Error("error unmarshaling\n")
 
 		return err
 	}
@@ -453,16 +453,16 @@ func getBroadcastGeneric(_ context.Context, in, out interface{}) error {
 	return nil
 }
 
-func (*EventCalling) getBroadcastParams(ctx context.Context, in, out interface{}) error {
-	return getBroadcastGeneric(ctx, in, out)
+func (calling *EventCalling) getBroadcastParams(ctx context.Context, in, out interface{}) error {
+	return getBroadcastGeneric(ctx, calling.logger(), in, out)
 }
 
-func (*EventMessaging) getBroadcastParams(ctx context.Context, in, out interface{}) error {
-	return getBroadcastGeneric(ctx, in, out)
+func (messaging *EventMessaging) getBroadcastParams(ctx context.Context, in, out interface{}) error {
+	return getBroadcastGeneric(ctx, messaging.logger(), in, out)
 }
 
-func (*EventTasking) getBroadcastParams(ctx context.Context, in, out interface{}) error {
-	return getBroadcastGeneric(ctx, in, out)
+func (tasking *EventTasking) getBroadcastParams(ctx context.Context, in, out interface{}) error {
+	return getBroadcastGeneric(ctx, tasking.logger(), in, out)
 }
 
 func (calling *EventCalling) onCallingEventConnect(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
@@ -472,10 +472,10 @@ func (calling *EventCalling) onCallingEventConnect(ctx context.Context, broadcas
 		return err
 	}
 
-	Log.Debug("broadcast.Params.Params.CallID: %v\n", params.CallID)
-	Log.Debug("broadcast.Params.Params.NodeID: %v\n", params.NodeID)
-	Log.Debug("broadcast.Params.Params.TagID: %v\n", params.TagID)
-	Log.Debug("params.CallState: %v\n", params.ConnectState)
+	calling.logger().Debug("broadcast.Params.Params.CallID: %v\n", params.CallID)
+	calling.logger().Debug("broadcast.Params.Params.NodeID: %v\n", params.NodeID)
+	calling.logger().Debug("broadcast.Params.Params.TagID: %v\n", params.TagID)
+	calling.logger().Debug("params.CallState: %v\n", params.ConnectState)
 
 	state, err := calling.I.callConnectStateFromStr(params.ConnectState)
 	if err != nil {
@@ -520,6 +520,12 @@ func (calling *EventCalling) onCallingEventState(ctx context.Context, broadcast
 	callParams.CallState = state
 	callParams.EndReason = params.EndReason
 
+	if params.Device.Type == DeviceSIP && params.Device.SIP != nil {
+		callParams.ToNumber = params.Device.SIP.To
+		callParams.FromNumber = params.Device.SIP.From
+		callParams.SIP = params.Device.SIP
+	}
+
 	return calling.I.dispatchStateNotif(ctx, callParams, rawEvent)
 }
 
@@ -547,12 +553,18 @@ func (calling *EventCalling) onCallingEventReceive(ctx context.Context, broadcas
 	callParams.CallState = state
 	callParams.Context = params.Context
 
+	if params.Device.Type == DeviceSIP && params.Device.SIP != nil {
+		callParams.ToNumber = params.Device.SIP.To
+		callParams.FromNumber = params.Device.SIP.From
+		callParams.SIP = params.Device.SIP
+	}
+
 	// only state Created
 	return calling.I.dispatchStateNotif(ctx, callParams, rawEvent)
 }
 
 func (calling *EventCalling) onCallingEventPlay(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
-	Log.Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
+	calling.logger().Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
 
 	var params ParamsEventCallingCallPlay
 
@@ -575,7 +587,7 @@ func (calling *EventCalling) onCallingEventPlay(ctx context.Context, broadcast N
 }
 
 func (calling *EventCalling) onCallingEventCollect(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
-	Log.Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
+	calling.logger().Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
 
 	var params ParamsEventCallingCallPlayAndCollect
 
@@ -588,6 +600,17 @@ func (calling *EventCalling) onCallingEventCollect(ctx context.Context, broadcas
 		return err
 	}
 
+	// partial results (partial_results) come with "final": false, the prompt goes on
+	var final struct {
+		Final *bool `json:"final"`
+	}
+
+	if err := calling.getBroadcastParams(ctx, broadcast.Params.Params, &final); err == nil && final.Final != nil && !*final.Final {
+		params.partial = true
+
+		return calling.I.dispatchPlayAndCollectEventParams(ctx, params.CallID, params.ControlID, params)
+	}
+
 	if err := calling.I.dispatchPlayAndCollectEventParams(ctx, params.CallID, params.ControlID, params); err != nil {
 		return err
 	}
@@ -602,7 +625,7 @@ func (calling *EventCalling) onCallingEventCollect(ctx context.Context, broadcas
 }
 
 func (calling *EventCalling) onCallingEventRecord(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
-	Log.Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
+	calling.logger().Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
 
 	var params ParamsEventCallingCallRecord
 
@@ -629,7 +652,7 @@ func (calling *EventCalling) onCallingEventRecord(ctx context.Context, broadcast
 }
 
 func (calling *EventCalling) onCallingEventTap(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
-	Log.Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
+	calling.logger().Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
 
 	var params ParamsEventCallingCallTap
 
@@ -656,7 +679,7 @@ func (calling *EventCalling) onCallingEventTap(ctx context.Context, broadcast No
 }
 
 func (calling *EventCalling) onCallingEventDetect(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
-	Log.Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
+	calling.logger().Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
 
 	var params ParamsEventCallingCallDetect
 
@@ -679,7 +702,7 @@ func (calling *EventCalling) onCallingEventDetect(ctx context.Context, broadcast
 }
 
 func (calling *EventCalling) onCallingEventFax(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
-	Log.Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
+	calling.logger().Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
 
 	var params ParamsEventCallingFax
 
@@ -706,7 +729,7 @@ func (calling *EventCalling) onCallingEventFax(ctx context.Context, broadcast No
 }
 
 func (calling *EventCalling) onCallingEventSendDigits(ctx context.Context, broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) error {
-	Log.Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
+	calling.logger().Debug("ctx: %p calling %p %v\n", ctx, calling, broadcast)
 
 	var params ParamsEventCallingCallSendDigits
 
@@ -768,23 +791,19 @@ func (calling *EventCalling) getCall(ctx context.Context, tag, callID string) (*
 		err  error
 	)
 
-	Log.Debug("tag [%s] callid [%s] [%p]\n", tag, callID, calling)
+	calling.logger().Debug("tag [%s] callid [%s] [%p]\n", tag, callID, calling)
 
 	/* some events don't have the tag */
 	if len(callID) > 0 && len(tag) > 0 {
 		call, err = calling.Cache.GetCallCache(tag)
 		if err != nil {
-			Log.Debug("GetCallCache failed: %v", err)
+			calling.logger().Debug("GetCallCache failed: %v", err)
 		}
 
 		if call != nil {
-			// remove call object from the mapping and read with the call_id as key
-			if err = calling.Cache.DeleteCallCache(tag); err != nil {
-				Log.Debug("DeleteCallCache failed: %v", err)
-			}
-
+			// the call is found with the call_id as key too
 			if err = calling.Cache.SetCallCache(callID, call); err != nil {
-				Log.Debug("SetCallCache failed: %v", err)
+				calling.logger().Debug("SetCallCache failed: %v", err)
 			}
 		}
 	}
@@ -794,14 +813,14 @@ func (calling *EventCalling) getCall(ctx context.Context, tag, callID string) (*
 		// new inbound call
 		call = new(CallSession)
 		if err = calling.Cache.SetCallCache(callID, call); err != nil {
-			Log.Debug("SetCallCache failed: %v\n", err)
+			calling.logger().Debug("SetCallCache failed: %v\n", err)
 		}
 
 		call.CallInit(ctx)
 		// default to "phone" for now
 		call.SetType(CallTypePhone)
 
-		Log.Debug("new inbound call: [%p]\n", call)
+		calling.logger().Debug("new inbound call: [%p]\n", call)
 	}
 
 	return call, err
@@ -813,19 +832,19 @@ func (messaging *EventMessaging) getMsg(ctx context.Context, msgID string) (*Msg
 		err error
 	)
 
-	Log.Debug("msgid [%s] [%p]\n", msgID, messaging)
+	messaging.logger().Debug("msgid [%s] [%p]\n", msgID, messaging)
 
 	msg, err = messaging.Cache.GetMsgCache(msgID)
 	if msg == nil {
 		// new inbound msg
 		msg = new(MsgSession)
 		if err = messaging.Cache.SetMsgCache(msgID, msg); err != nil {
-			Log.Debug("SetMsgCache failed: %v\n", err)
+			messaging.logger().Debug("SetMsgCache failed: %v\n", err)
 		}
 
 		msg.MsgInit(ctx)
 
-		Log.Debug("new inbound msg: [%p]\n", msg)
+		messaging.logger().Debug("new inbound msg: [%p]\n", msg)
 	}
 
 	return msg, err
@@ -844,24 +863,24 @@ func (tasking *EventTasking) onTaskingEvent(ctx context.Context, broadcast Notif
 	}
 	select {
 	case consumerTasking.TaskChan <- params:
-		Log.Debug("sent task event to Consumer\n")
+		tasking.logger().Debug("sent task event to Consumer\n")
 	default:
-		Log.Debug("no task event sent\n")
+		tasking.logger().Debug("no task event sent\n")
 	}
 
 	return nil
 }
 
 func (calling *EventCalling) dispatchStateNotif(ctx context.Context, callParams CallParams, rawEvent *json.RawMessage) error {
-	Log.Debug("tag [%s] callstate [%s] blade [%p] direction: %s\n", callParams.TagID, callParams.CallState.String(), calling.blade, callParams.Direction)
-	Log.Debug("direction : %v\n", callParams.Direction)
+	calling.logger().Debug("tag [%s] callstate [%s] blade [%p] direction: %s\n", callParams.TagID, callParams.CallState.String(), calling.blade, callParams.Direction)
+	calling.logger().Debug("direction : %v\n", callParams.Direction)
 
 	call, _ := calling.I.getCall(ctx, callParams.TagID, callParams.CallID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
 	direction, err := calling.I.callDirectionFromStr(callParams.Direction)
 	if err != nil {
@@ -870,6 +889,11 @@ func (calling *EventCalling) dispatchStateNotif(ctx context.Context, callParams
 
 	call.SetParams(callParams.CallID, callParams.NodeID, callParams.ToNumber, callParams.FromNumber, callParams.Context, direction)
 
+	if callParams.SIP != nil {
+		call.SetType(CallTypeSIP)
+		call.SetSIP(callParams.SIP)
+	}
+
 	call.UpdateCallState(callParams.CallState)
 
 	call.Blade = calling.blade
@@ -888,42 +912,46 @@ func (calling *EventCalling) dispatchStateNotif(ctx context.Context, callParams
 			return err
 		}
 
-		if err := calling.Cache.DeleteCallCache(call.CallID); err != nil {
+		if err := calling.Cache.RemoveCallCache(call); err != nil {
 			return errors.New("cannot remove the call from cache")
 		}
 
 		call.SetDisconnectReason(disconnectReason)
 	}
-	select {
-	case call.CallStateChan <- callParams.CallState:
-		Log.Debug("sent callstate\n")
-	default:
-		Log.Debug("no callstate sent\n")
-	}
-
-	select {
-	case call.cbStateChan <- callParams.CallState:
-		Log.Debug("sent callstate / CB signal\n")
-	default:
-		Log.Debug("no callstate / CB signal sent\n")
-	}
+	return calling.deliver(call, func() {
+		select {
+		case call.CallStateChan <- callParams.CallState:
+			calling.logger().Debug("sent callstate\n")
+		default:
+			calling.logger().Debug("no callstate sent\n")
+		}
 
-	if callParams.CallState == Ended {
-		close(call.Hangup)
-	}
+		call.callbacks.send(call.cbStateChan, func() bool {
+			select {
+			case call.cbStateChan <- callParams.CallState:
+				calling.logger().Debug("sent callstate / CB signal\n")
+				return true
+			default:
+				calling.logger().Debug("no callstate / CB signal sent\n")
+				return false
+			}
+		})
 
-	return nil
+		if callParams.CallState == Ended {
+			call.closeHangup()
+		}
+	})
 }
 
 func (calling *EventCalling) dispatchConnectStateNotif(ctx context.Context, callParams CallParams, peer PeerDeviceStruct, ccstate CallConnectState, rawEvent *json.RawMessage) error {
-	Log.Debug("tag [%s] [%s] [%p]\n", callParams.TagID, ccstate.String(), calling.blade)
+	calling.logger().Debug("tag [%s] [%s] [%p]\n", callParams.TagID, ccstate.String(), calling.blade)
 
 	call, _ := calling.I.getCall(ctx, callParams.TagID, callParams.CallID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
 	call.UpdateCallConnectState(ccstate)
 
@@ -931,357 +959,425 @@ func (calling *EventCalling) dispatchConnectStateNotif(ctx context.Context, call
 		call.UpdateConnectPeer(peer)
 	}
 
-	select {
-	case call.CallConnectRawEventChan <- rawEvent:
-		Log.Debug("sent raw event\n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
-
-	select {
-	case call.CallConnectStateChan <- ccstate:
-		Log.Debug("sent connstate\n")
-	default:
-		Log.Debug("no connstate sent\n")
-	}
+	return calling.deliver(call, func() {
+		select {
+		case call.CallConnectRawEventChan <- rawEvent:
+			calling.logger().Debug("sent raw event\n")
+		default:
+			calling.logger().Debug("no raw event sent\n")
+		}
 
-	return nil
+		call.callbacks.send(call.CallConnectStateChan, func() bool {
+			select {
+			case call.CallConnectStateChan <- ccstate:
+				calling.logger().Debug("sent connstate\n")
+				return true
+			default:
+				calling.logger().Debug("no connstate sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (calling *EventCalling) dispatchPlayState(ctx context.Context, callID, ctrlID string, playState PlayState, rawEvent *json.RawMessage) error {
-	Log.Debug("callid [%s] playstate [%s] blade [%p] ctrlID: %s\n", callID, playState, calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] playstate [%s] blade [%p] ctrlID: %s\n", callID, playState, calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	select {
-	case call.CallPlayRawEventChans[ctrlID] <- rawEvent:
-		Log.Debug("sent raw event\n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
-
-	select {
-	case call.CallPlayChans[ctrlID] <- playState:
-		Log.Debug("sent playstate\n")
-	default:
-		Log.Debug("no playstate sent\n")
-	}
+	return calling.deliver(call, func() {
+		select {
+		case call.CallPlayRawEventChans[ctrlID] <- rawEvent:
+			calling.logger().Debug("sent raw event\n")
+		default:
+			calling.logger().Debug("no raw event sent\n")
+		}
 
-	return nil
+		call.callbacks.send(call.CallPlayChans[ctrlID], func() bool {
+			select {
+			case call.CallPlayChans[ctrlID] <- playState:
+				calling.logger().Debug("sent playstate\n")
+				return true
+			default:
+				calling.logger().Debug("no playstate sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (calling *EventCalling) dispatchRecordState(ctx context.Context, callID, ctrlID string, recordState RecordState, rawEvent *json.RawMessage) error {
-	Log.Debug("callid [%s] recordstate [%s] blade [%p] ctrlID: %s\n", callID, recordState.String(), calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] recordstate [%s] blade [%p] ctrlID: %s\n", callID, recordState.String(), calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
 	/* // possibly redundant, we have a map of Recording channels with key as ctrlID
 	if call.GetActionState(ctrlID) == "" {
 		return fmt.Errorf("error, unknown control ID: %s", ctrlID)
 	}*/
 
-	<-call.CallRecordReadyChans[ctrlID]
-	select {
-	case call.CallRecordRawEventChans[ctrlID] <- rawEvent:
-		Log.Debug("sent raw event\n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
-	<-call.CallRecordReadyChans[ctrlID]
-	select {
-	case call.CallRecordChans[ctrlID] <- recordState:
-		Log.Debug("sent recordstate\n")
-	default:
-		Log.Debug("no recordstate sent\n")
-	}
+	return calling.deliver(call, func() {
+		ready := call.CallRecordReadyChans[ctrlID]
+		if ready == nil {
+			// the action was reclaimed with its call
+			calling.logger().Debug("no record action, ctrlID: %s\n", ctrlID)
 
-	return nil
+			return
+		}
+
+		<-ready
+		select {
+		case call.CallRecordRawEventChans[ctrlID] <- rawEvent:
+			calling.logger().Debug("sent raw event\n")
+		default:
+			calling.logger().Debug("no raw event sent\n")
+		}
+		<-ready
+		call.callbacks.send(call.CallRecordChans[ctrlID], func() bool {
+			select {
+			case call.CallRecordChans[ctrlID] <- recordState:
+				calling.logger().Debug("sent recordstate\n")
+				return true
+			default:
+				calling.logger().Debug("no recordstate sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (calling *EventCalling) dispatchRecordEventParams(ctx context.Context, callID, ctrlID string, params ParamsEventCallingCallRecord) error {
-	Log.Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
-
-	select {
-	case call.CallRecordEventChans[ctrlID] <- params:
-		Log.Debug("sent params (event)\n")
-	default:
-		Log.Debug("no params (event) sent\n")
-	}
+	calling.logger().Debug("call [%p]\n", call)
 
-	return nil
+	return calling.deliver(call, func() {
+		select {
+		case call.CallRecordEventChans[ctrlID] <- params:
+			calling.logger().Debug("sent params (event)\n")
+		default:
+			calling.logger().Debug("no params (event) sent\n")
+		}
+	})
 }
 
 func (calling *EventCalling) dispatchDetect(ctx context.Context, callID, ctrlID string, v interface{}, rawEvent *json.RawMessage) error {
-	Log.Debug("callid [%s] detectevent [%v] blade [%p] ctrlID: %s\n", callID, v, calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] detectevent [%v] blade [%p] ctrlID: %s\n", callID, v, calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	select {
-	case call.CallDetectRawEventChans[ctrlID] <- rawEvent:
-		Log.Debug("sent raw event\n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
-
-	detectEventMachine, ok1 := v.(DetectMachineEvent)
-	if ok1 {
+	return calling.deliver(call, func() {
 		select {
-		case call.CallDetectMachineChans[ctrlID] <- detectEventMachine:
-			Log.Debug("sent detectevent Machine\n")
+		case call.CallDetectRawEventChans[ctrlID] <- rawEvent:
+			calling.logger().Debug("sent raw event\n")
 		default:
-			Log.Debug("no detectevent sent - Machine\n")
+			calling.logger().Debug("no raw event sent\n")
 		}
 
-		return nil
-	}
-
-	detectEventDigit, ok2 := v.(DetectDigitEvent)
-	if ok2 {
-		select {
-		case call.CallDetectDigitChans[ctrlID] <- detectEventDigit:
-			Log.Debug("sent detectevent Digit\n")
-		default:
-			Log.Debug("no detectevent sent - Digit\n")
+		detectEventMachine, ok1 := v.(DetectMachineEvent)
+		if ok1 {
+			call.callbacks.send(call.CallDetectMachineChans[ctrlID], func() bool {
+				select {
+				case call.CallDetectMachineChans[ctrlID] <- detectEventMachine:
+					calling.logger().Debug("sent detectevent Machine\n")
+					return true
+				default:
+					calling.logger().Debug("no detectevent sent - Machine\n")
+					return false
+				}
+			})
+
+			return
 		}
 
-		return nil
-	}
-
-	detectEventFax, ok3 := v.(DetectFaxEvent)
-	if ok3 {
-		select {
-		case call.CallDetectFaxChans[ctrlID] <- detectEventFax:
-			Log.Debug("sent detectevent Fax\n")
-		default:
-			Log.Debug("no detectevent sent - Fax\n")
+		detectEventDigit, ok2 := v.(DetectDigitEvent)
+		if ok2 {
+			call.callbacks.send(call.CallDetectDigitChans[ctrlID], func() bool {
+				select {
+				case call.CallDetectDigitChans[ctrlID] <- detectEventDigit:
+					calling.logger().Debug("sent detectevent Digit\n")
+					return true
+				default:
+					calling.logger().Debug("no detectevent sent - Digit\n")
+					return false
+				}
+			})
+
+			return
 		}
 
-		return nil
-	}
-
-	Log.Error("type assertion failed (detector event)\n")
+		detectEventFax, ok3 := v.(DetectFaxEvent)
+		if ok3 {
+			call.callbacks.send(call.CallDetectFaxChans[ctrlID], func() bool {
+				select {
+				case call.CallDetectFaxChans[ctrlID] <- detectEventFax:
+					calling.logger().Debug("sent detectevent Fax\n")
+					return true
+				default:
+					calling.logger().Debug("no detectevent sent - Fax\n")
+					return false
+				}
+			})
+
+			return
+		}
 
-	return nil
+		calling.logger().Error("type assertion failed (detector event)\n")
+	})
 }
 
 func (calling *EventCalling) dispatchDetectEventParams(ctx context.Context, callID, ctrlID string, params ParamsEventCallingCallDetect) error {
-	Log.Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	select {
-	case call.CallDetectEventChans[ctrlID] <- params:
-		Log.Debug("sent params (event)\n")
-	default:
-		Log.Debug("no params (event) sent\n")
-	}
-
-	return nil
+	return calling.deliver(call, func() {
+		select {
+		case call.CallDetectEventChans[ctrlID] <- params:
+			calling.logger().Debug("sent params (event)\n")
+		default:
+			calling.logger().Debug("no params (event) sent\n")
+		}
+	})
 }
 
 func (calling *EventCalling) dispatchFax(ctx context.Context, callID, ctrlID string, faxType FaxEventType, rawEvent *json.RawMessage) error {
-	Log.Debug("callid [%s] faxtype [%s] blade [%p] ctrlID: %s\n", callID, faxType.String(), calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] faxtype [%s] blade [%p] ctrlID: %s\n", callID, faxType.String(), calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
-
-	<-call.CallFaxReadyChan
-	select {
-	case call.CallFaxRawEventChan <- rawEvent:
-		Log.Debug("sent raw event \n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
+	calling.logger().Debug("call [%p]\n", call)
 
-	<-call.CallFaxReadyChan
-	select {
-	case call.CallFaxChan <- faxType:
-		Log.Debug("sent faxType\n")
-	default:
-		Log.Debug("no faxType sent\n")
-	}
+	return calling.deliver(call, func() {
+		<-call.CallFaxReadyChan
+		select {
+		case call.CallFaxRawEventChan <- rawEvent:
+			calling.logger().Debug("sent raw event \n")
+		default:
+			calling.logger().Debug("no raw event sent\n")
+		}
 
-	return nil
+		<-call.CallFaxReadyChan
+		call.callbacks.send(call.CallFaxChan, func() bool {
+			select {
+			case call.CallFaxChan <- faxType:
+				calling.logger().Debug("sent faxType\n")
+				return true
+			default:
+				calling.logger().Debug("no faxType sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (calling *EventCalling) dispatchFaxEventParams(ctx context.Context, callID, ctrlID string, params ParamsEventCallingFax) error {
-	Log.Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	select {
-	case call.CallFaxEventChan <- params.Fax:
-		Log.Debug("sent params (event)\n")
-	default:
-		Log.Debug("no params (event) sent\n")
-	}
-
-	return nil
+	return calling.deliver(call, func() {
+		select {
+		case call.CallFaxEventChan <- params.Fax:
+			calling.logger().Debug("sent params (event)\n")
+		default:
+			calling.logger().Debug("no params (event) sent\n")
+		}
+	})
 }
 
 func (calling *EventCalling) dispatchTapEventParams(ctx context.Context, callID, ctrlID string, params ParamsEventCallingCallTap) error {
-	Log.Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	select {
-	case call.CallTapEventChans[ctrlID] <- params:
-		Log.Debug("sent params (event)\n")
-	default:
-		Log.Debug("no params (event) sent\n")
-	}
-
-	return nil
+	return calling.deliver(call, func() {
+		select {
+		case call.CallTapEventChans[ctrlID] <- params:
+			calling.logger().Debug("sent params (event)\n")
+		default:
+			calling.logger().Debug("no params (event) sent\n")
+		}
+	})
 }
 
 func (calling *EventCalling) dispatchTapState(ctx context.Context, callID, ctrlID string, tapState TapState, rawEvent *json.RawMessage) error {
-	Log.Debug("callid [%s] tapstate [%s] blade [%p] ctrlID: %s\n", callID, tapState.String(), calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] tapstate [%s] blade [%p] ctrlID: %s\n", callID, tapState.String(), calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	<-call.CallTapReadyChans[ctrlID]
-	select {
-	case call.CallTapRawEventChans[ctrlID] <- rawEvent:
-		Log.Debug("sent raw event\n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
+	return calling.deliver(call, func() {
+		ready := call.CallTapReadyChans[ctrlID]
+		if ready == nil {
+			// the action was reclaimed with its call
+			calling.logger().Debug("no tap action, ctrlID: %s\n", ctrlID)
 
-	<-call.CallTapReadyChans[ctrlID]
-	select {
-	case call.CallTapChans[ctrlID] <- tapState:
-		Log.Debug("sent tapstate\n")
-	default:
-		Log.Debug("no tapstate sent\n")
-	}
+			return
+		}
 
-	return nil
+		<-ready
+		select {
+		case call.CallTapRawEventChans[ctrlID] <- rawEvent:
+			calling.logger().Debug("sent raw event\n")
+		default:
+			calling.logger().Debug("no raw event sent\n")
+		}
+
+		<-ready
+		call.callbacks.send(call.CallTapChans[ctrlID], func() bool {
+			select {
+			case call.CallTapChans[ctrlID] <- tapState:
+				calling.logger().Debug("sent tapstate\n")
+				return true
+			default:
+				calling.logger().Debug("no tapstate sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (calling *EventCalling) dispatchSendDigitsState(ctx context.Context, callID, ctrlID string, sendDigitsState SendDigitsState, rawEvent *json.RawMessage) error {
-	Log.Debug("callid [%s] sendDigitsState [%s] blade [%p] ctrlID: %s\n", callID, sendDigitsState.String(), calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] sendDigitsState [%s] blade [%p] ctrlID: %s\n", callID, sendDigitsState.String(), calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	select {
-	case call.CallSendDigitsRawEventChans[ctrlID] <- rawEvent:
-		Log.Debug("sent raw event\n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
+	return calling.deliver(call, func() {
+		ready := call.CallSendDigitsReadyChans[ctrlID]
+		if ready == nil {
+			// the action was reclaimed with its call
+			calling.logger().Debug("no senddigits action, ctrlID: %s\n", ctrlID)
 
-	<-call.CallSendDigitsReadyChans[ctrlID]
-	select {
-	case call.CallSendDigitsChans[ctrlID] <- sendDigitsState:
-		Log.Debug("sent senddigits state\n")
-	default:
-		Log.Debug("no senddigits state sent\n")
-	}
+			return
+		}
 
-	return nil
+		select {
+		case call.CallSendDigitsRawEventChans[ctrlID] <- rawEvent:
+			calling.logger().Debug("sent raw event\n")
+		default:
+			calling.logger().Debug("no raw event sent\n")
+		}
+
+		<-ready
+		call.callbacks.send(call.CallSendDigitsChans[ctrlID], func() bool {
+			select {
+			case call.CallSendDigitsChans[ctrlID] <- sendDigitsState:
+				calling.logger().Debug("sent senddigits state\n")
+				return true
+			default:
+				calling.logger().Debug("no senddigits state sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (calling *EventCalling) dispatchPlayAndCollectResType(ctx context.Context, callID, ctrlID string, resType CollectResultType, rawEvent *json.RawMessage) error {
-	Log.Debug("callid [%s] resType [%s] blade [%p] ctrlID: %s\n", callID, resType.String(), calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] resType [%s] blade [%p] ctrlID: %s\n", callID, resType.String(), calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
+	calling.logger().Debug("call [%p]\n", call)
 
-	select {
-	case call.CallPlayAndCollectRawEventChans[ctrlID] <- rawEvent:
-		Log.Debug("sent raw event\n")
-	default:
-		Log.Debug("no raw event sent\n")
-	}
-
-	select {
-	case call.CallPlayAndCollectChans[ctrlID] <- resType:
-		Log.Debug("sent collect resType\n")
-	default:
-		Log.Debug("no collect resType sent\n")
-	}
+	return calling.deliver(call, func() {
+		select {
+		case call.CallPlayAndCollectRawEventChans[ctrlID] <- rawEvent:
+			calling.logger().Debug("sent raw event\n")
+		default:
+			calling.logger().Debug("no raw event sent\n")
+		}
 
-	return nil
+		call.callbacks.send(call.CallPlayAndCollectChans[ctrlID], func() bool {
+			select {
+			case call.CallPlayAndCollectChans[ctrlID] <- resType:
+				calling.logger().Debug("sent collect resType\n")
+				return true
+			default:
+				calling.logger().Debug("no collect resType sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (calling *EventCalling) dispatchPlayAndCollectEventParams(ctx context.Context, callID, ctrlID string, params ParamsEventCallingCallPlayAndCollect) error {
-	Log.Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
+	calling.logger().Debug("callid [%s] blade [%p] ctrlID: %s\n", callID, calling.blade, ctrlID)
 
 	call, _ := calling.I.getCall(ctx, "", callID)
 	if call == nil {
 		return fmt.Errorf("error, nil CallSession")
 	}
 
-	Log.Debug("call [%p]\n", call)
-
-	select {
-	case call.CallPlayAndCollectEventChans[ctrlID] <- params:
-		Log.Debug("sent params (event)\n")
-	default:
-		Log.Debug("no params (event) sent\n")
-	}
+	calling.logger().Debug("call [%p]\n", call)
 
-	return nil
+	return calling.deliver(call, func() {
+		call.callbacks.send(call.CallPlayAndCollectEventChans[ctrlID], func() bool {
+			select {
+			case call.CallPlayAndCollectEventChans[ctrlID] <- params:
+				calling.logger().Debug("sent params (event)\n")
+				return true
+			default:
+				calling.logger().Debug("no params (event) sent\n")
+				return false
+			}
+		})
+	})
 }
 
 func (messaging *EventMessaging) dispatchMsgStateNotif(ctx context.Context, msgParams MsgParams) error {
-	Log.Debug("msgID [%s] msgstate [%s] blade [%p] direction: %s\n", msgParams.MsgID, msgParams.MsgState.String(), messaging.blade, msgParams.Direction)
-	Log.Debug("direction : %v\n", msgParams.Direction)
+	messaging.logger().Debug("msgID [%s] msgstate [%s] blade [%p] direction: %s\n", msgParams.MsgID, msgParams.MsgState.String(), messaging.blade, msgParams.Direction)
+	messaging.logger().Debug("direction : %v\n", msgParams.Direction)
 
 	msg, _ := messaging.I.getMsg(ctx, msgParams.MsgID)
 	if msg == nil {
@@ -1306,9 +1402,9 @@ func (messaging *EventMessaging) dispatchMsgStateNotif(ctx context.Context, msgP
 
 	select {
 	case msg.MsgStateChan <- msgParams.MsgState:
-		Log.Debug("sent msgstate\n")
+		messaging.logger().Debug("sent msgstate\n")
 	default:
-		Log.Debug("no msgstate sent\n")
+		messaging.logger().Debug("no msgstate sent\n")
 	}
 
 	return nil
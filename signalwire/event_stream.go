@@ -0,0 +1,162 @@
+package signalwire
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// eventStream is the channel returned by Events(). It is fed by a listener
+// and closed from the callback queue of the call, so that it is never closed
+// while an event is sent on it.
+type eventStream struct {
+	sync.Mutex
+	ch          chan *CallEventArgs
+	unsubscribe func()
+	ended       bool
+}
+
+// open returns the channel of the stream, subscribing it to the events of
+// callobj that match. Without callobj (the action did not start), the
+// channel is closed.
+func (s *eventStream) open(callobj *CallObj, match func(*CallEventArgs) bool) (ch <-chan *CallEventArgs, opened bool) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ch != nil {
+		return s.ch, false
+	}
+
+	s.ch = make(chan *CallEventArgs, DefaultEventQueueSize)
+
+	if s.ended || callobj == nil {
+		s.ended = true
+		close(s.ch)
+
+		return s.ch, false
+	}
+
+	stream := s.ch
+	s.unsubscribe = callobj.OnAny(func(args *CallEventArgs) {
+		if !match(args) {
+			return
+		}
+
+		select {
+		case stream <- args:
+		default:
+			callobj.logger().Warn("call [%s]: events channel full, %s dropped\n", callobj.call.GetCallID(), args.Event)
+		}
+	})
+
+	return s.ch, true
+}
+
+// end closes the channel, it runs on the callback queue of the call
+func (s *eventStream) end() {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.ended {
+		return
+	}
+
+	s.ended = true
+
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+	}
+
+	if s.ch != nil {
+		close(s.ch)
+	}
+}
+
+// endEvents closes the stream after the events queued so far
+func (callobj *CallObj) endEvents(s *eventStream) {
+	callobj.call.callbacks.enqueue(s.end)
+}
+
+// Events returns a channel with the events of the call and of its actions,
+// as passed to the listeners. It is closed once the call has ended. Events
+// are dropped when the channel is full (DefaultEventQueueSize). Without a
+// Calling object, the channel is closed.
+func (callobj *CallObj) Events() <-chan *CallEventArgs {
+	if callobj.Calling == nil {
+		callobj.logger().Error("nil Calling object\n")
+		callobj.events.end()
+	}
+
+	ch, opened := callobj.events.open(callobj, func(*CallEventArgs) bool { return true })
+	if opened {
+		go func() {
+			select {
+			case <-callobj.call.Hangup:
+			case <-callobj.Calling.Ctx.Done():
+			}
+
+			// the call state go routine, if any, ends the stream after EventEnded
+			if atomic.LoadInt32(&callobj.stateLoop) == 0 {
+				callobj.endEvents(&callobj.events)
+			}
+		}()
+	}
+
+	return ch
+}
+
+// Events returns a channel with the events of the play, closed once it has ended or if it failed
+func (action *PlayAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
+
+// Events returns a channel with the events of the recording, closed once it has ended or if it failed
+func (action *RecordAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
+
+// Events returns a channel with the events of the detector, closed once it has ended or if it failed
+func (action *DetectAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
+
+// Events returns a channel with the events of the fax, closed once it has ended or if it failed
+func (action *FaxAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
+
+// Events returns a channel with the events of the connect, closed once it has ended or if it failed
+func (action *ConnectAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.Result.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
+
+// Events returns a channel with the events of the tap, closed once it has ended or if it failed
+func (action *TapAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
+
+// Events returns a channel with the events of the digits sent, closed once it has ended or if it failed
+func (action *SendDigitsAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
+
+// Events returns a channel with the events of the prompt, partial results
+// included (EventPromptUpdate), closed once it has ended or if it failed
+func (action *PromptAction) Events() <-chan *CallEventArgs {
+	ch, _ := action.events.open(action.CallObj, func(args *CallEventArgs) bool { return args.action == action })
+
+	return ch
+}
@@ -1,7 +1,4 @@
 package signalwire
 
-// GlobalBladeSessionControl - needs Refactor
-var GlobalBladeSessionControl = NewBladeSessionControl()
-
-// Log is a global logger
+// Log is the default logger, used by the sessions that have no logger of their own
 var Log LoggerWrapper = CreateNewBasicLogger()
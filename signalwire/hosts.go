@@ -0,0 +1,323 @@
+package signalwire
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultProbeTimeout is the time allowed to open the websocket to a host when probing it
+const DefaultProbeTimeout = 5 * time.Second
+
+// RelayHost is one of the Relay endpoints the session fails over between
+type RelayHost struct {
+	Host string
+	// Weight ranks the host, the highest first. Hosts of equal weight keep their order.
+	Weight int
+}
+
+// FailoverPolicy controls when the session moves to another host of the list
+type FailoverPolicy struct {
+	// MaxFailures in a row on a host before moving to the next one (0 means 1)
+	MaxFailures int
+	// FailbackInterval is how often the preferred hosts are probed while
+	// running on a fallback one, 0 means no fail-back
+	FailbackInterval time.Duration
+	// ProbeTimeout for opening the websocket to a probed host, 0 means DefaultProbeTimeout
+	ProbeTimeout time.Duration
+}
+
+// sortHosts returns the hosts by weight, the highest first
+func sortHosts(hosts []RelayHost) []string {
+	sorted := make([]RelayHost, 0, len(hosts))
+
+	for _, h := range hosts {
+		if len(h.Host) > 0 {
+			sorted = append(sorted, h)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Weight > sorted[j].Weight
+	})
+
+	ret := make([]string, len(sorted))
+	for i, h := range sorted {
+		ret[i] = h.Host
+	}
+
+	return ret
+}
+
+// hostList tracks the active host and the failures of the session
+type hostList struct {
+	sync.Mutex
+	hosts  []string
+	policy FailoverPolicy
+	active int
+	// failures on the active host, and on all the hosts, in a row
+	failures int
+	failed   int
+}
+
+func (l *hostList) set(hosts []string, policy *FailoverPolicy) {
+	l.Lock()
+
+	l.hosts = hosts
+	l.active = 0
+	l.failures = 0
+	l.failed = 0
+	l.policy = FailoverPolicy{}
+
+	if policy != nil {
+		l.policy = *policy
+	}
+
+	l.Unlock()
+}
+
+func (l *hostList) maxFailures() int {
+	if l.policy.MaxFailures > 0 {
+		return l.policy.MaxFailures
+	}
+
+	return 1
+}
+
+// current returns the host to connect to
+func (l *hostList) current() string {
+	l.Lock()
+	defer l.Unlock()
+
+	if len(l.hosts) == 0 {
+		return WssHost
+	}
+
+	return l.hosts[l.active]
+}
+
+// fail records a failure of the active host and moves to the next one after
+// MaxFailures. It is true once every host failed MaxFailures times in a row,
+// the next round then tries every host again.
+func (l *hostList) fail() bool {
+	l.Lock()
+	defer l.Unlock()
+
+	l.failures++
+	l.failed++
+
+	if l.failures >= l.maxFailures() && len(l.hosts) > 0 {
+		l.active = (l.active + 1) % len(l.hosts)
+		l.failures = 0
+	}
+
+	if l.failed < l.maxFailures()*len(l.hosts) {
+		return false
+	}
+
+	l.failed = 0
+
+	return true
+}
+
+// round starts a round of attempts over all the hosts
+func (l *hostList) round() {
+	l.Lock()
+	l.failed = 0
+	l.Unlock()
+}
+
+// ok resets the failures once connected
+func (l *hostList) ok() {
+	l.Lock()
+	l.failures = 0
+	l.failed = 0
+	l.Unlock()
+}
+
+// preferred returns the hosts ranked before the active one
+func (l *hostList) preferred() []string {
+	l.Lock()
+	defer l.Unlock()
+
+	return append([]string(nil), l.hosts[:l.active]...)
+}
+
+// moveTo makes host the active one
+func (l *hostList) moveTo(host string) {
+	l.Lock()
+
+	for i, h := range l.hosts {
+		if h == host {
+			l.active = i
+			l.failures = 0
+		}
+	}
+
+	l.Unlock()
+}
+
+func (l *hostList) failbackInterval() time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	return l.policy.FailbackInterval
+}
+
+func (l *hostList) probeTimeout() time.Duration {
+	l.Lock()
+	defer l.Unlock()
+
+	if l.policy.ProbeTimeout > 0 {
+		return l.policy.ProbeTimeout
+	}
+
+	return DefaultProbeTimeout
+}
+
+// hostURL returns the websocket URL of a host
+func hostURL(host string) url.URL {
+	return url.URL{
+		Scheme: "wss",
+		Host:   host,
+		Path:   "/",
+	}
+}
+
+// bladeInitHosts opens the link to the first host of the list that answers
+func (blade *BladeSession) bladeInitHosts(ctx context.Context) error {
+	blade.hosts.round()
+
+	for {
+		host := blade.hosts.current()
+
+		err := blade.BladeInit(ctx, host)
+		if err == nil {
+			blade.hosts.ok()
+
+			return nil
+		}
+
+		if blade.hosts.fail() || ctx.Err() != nil {
+			return err
+		}
+
+		if next := blade.hosts.current(); next != host {
+			blade.logger().Warn("cannot connect to %s, failing over to %s: %v\n", host, next, err)
+		}
+	}
+}
+
+// reconnectHost opens a new link to the active host, moving to the next one on failure
+func (blade *BladeSession) reconnectHost(ctx context.Context) bool {
+	host := blade.hosts.current()
+	u := hostURL(host)
+
+	blade.logger().Debug("Reconnecting to %s...\n", host)
+
+	blade.RemoteAddress = u
+	blade.health.setHost(host)
+	blade.BladeReconnect(ctx, u)
+
+	if conn, _ := blade.GetConnection(); conn != nil {
+		blade.hosts.ok()
+
+		return true
+	}
+
+	blade.hosts.fail()
+
+	if next := blade.hosts.current(); next != host {
+		blade.logger().Warn("cannot reconnect to %s, failing over to %s: %v\n", host, next, blade.LastError)
+	}
+
+	return false
+}
+
+// failback probes the preferred hosts while the session runs on a fallback
+// one. The link is moved to the first that answers: the session reconnects
+// to it, restoring the session as after a network failure.
+func (blade *BladeSession) failback(ctx context.Context) {
+	interval := blade.hosts.failbackInterval()
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if blade.GetState() != BladeRunning {
+			continue
+		}
+
+		for _, host := range blade.hosts.preferred() {
+			if err := blade.probeHost(ctx, host); err != nil {
+				blade.logger().Debug("probe of %s failed: %v\n", host, err)
+
+				continue
+			}
+
+			blade.logger().Info("%s is back, failing back\n", host)
+
+			blade.hosts.moveTo(host)
+
+			blade.Tmutex.Lock()
+			blade.WantReconnect = true
+			conn := blade.conn
+			blade.Tmutex.Unlock()
+
+			if conn != nil {
+				conn.Close()
+			}
+
+			break
+		}
+	}
+}
+
+// probeHost opens then closes a websocket to host
+func (blade *BladeSession) probeHost(ctx context.Context, host string) error {
+	ctx, cancel := context.WithTimeout(ctx, blade.hosts.probeTimeout())
+	defer cancel()
+
+	u := hostURL(host)
+
+	c, _, err := blade.Transport.dialer().DialContext(ctx, u.String(), blade.Transport.header())
+	if err != nil {
+		return err
+	}
+
+	return c.Close()
+}
+
+// relayHosts returns the hosts to connect to, in order
+func (client *ClientSession) relayHosts() []string {
+	if hosts := sortHosts(client.Hosts); len(hosts) > 0 {
+		return hosts
+	}
+
+	if len(client.Host) == 0 {
+		return []string{WssHost}
+	}
+
+	return []string{client.Host}
+}
+
+// ActiveHost returns the Relay host the session is connected, or connecting, to
+func (client *ClientSession) ActiveHost() string {
+	return client.LinkHealth().Host
+}
+
+// ActiveHost returns the Relay host the session is connected, or connecting, to
+func (consumer *Consumer) ActiveHost() string {
+	return consumer.LinkHealth().Host
+}
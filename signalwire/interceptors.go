@@ -0,0 +1,99 @@
+package signalwire
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// OutgoingCommand is a blade.execute as seen by the outgoing interceptors.
+// Changes to Protocol, Method or Params are sent.
+type OutgoingCommand struct {
+	Protocol string
+	Method   string
+	Params   json.RawMessage
+	// Latency is the time to the reply (outbound queue included), set once next returns
+	Latency time.Duration
+}
+
+// ExecuteHandler sends a command, res gets the result
+type ExecuteHandler func(ctx context.Context, cmd *OutgoingCommand, res interface{}) (interface{}, error)
+
+// OutgoingInterceptor wraps the sending of every command: it calls next to
+// go on, or returns without calling it to fail or answer the command itself.
+type OutgoingInterceptor func(ctx context.Context, cmd *OutgoingCommand, res interface{}, next ExecuteHandler) (interface{}, error)
+
+// BroadcastHandler dispatches a blade.broadcast, raw is its params
+type BroadcastHandler func(ctx context.Context, broadcast *NotifParamsBladeBroadcast, raw *json.RawMessage) error
+
+// IncomingInterceptor sees every blade.broadcast before it is dispatched: it
+// calls next to go on, or returns without calling it to drop the event.
+type IncomingInterceptor func(ctx context.Context, broadcast *NotifParamsBladeBroadcast, raw *json.RawMessage, next BroadcastHandler) error
+
+// Interceptors are the middleware chains around the Blade traffic, the first
+// interceptor of a chain is the outermost one
+type Interceptors struct {
+	Outgoing []OutgoingInterceptor
+	Incoming []IncomingInterceptor
+}
+
+// interceptExecute sends v through the outgoing interceptors
+func (blade *BladeSession) interceptExecute(ctx context.Context, interceptors []OutgoingInterceptor, v interface{}, res interface{}) (interface{}, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	placeholder := new(placeHolderCmd)
+
+	if err := json.Unmarshal(b, placeholder); err != nil {
+		return nil, err
+	}
+
+	cmd := &OutgoingCommand{
+		Protocol: placeholder.Protocol,
+		Method:   placeholder.Method,
+		Params:   placeholder.Params,
+	}
+
+	handler := func(ctx context.Context, cmd *OutgoingCommand, res interface{}) (interface{}, error) {
+		start := time.Now()
+
+		r, err := blade.execute(ctx, &placeHolderCmd{Protocol: cmd.Protocol, Method: cmd.Method, Params: cmd.Params}, res)
+
+		cmd.Latency = time.Since(start)
+
+		return r, err
+	}
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, cmd *OutgoingCommand, res interface{}) (interface{}, error) {
+			return interceptor(ctx, cmd, res, next)
+		}
+	}
+
+	return handler(ctx, cmd, res)
+}
+
+// interceptBroadcast dispatches a blade.broadcast through the incoming interceptors
+func (blade *BladeSession) interceptBroadcast(ctx context.Context, broadcast *NotifParamsBladeBroadcast, raw *json.RawMessage) error {
+	handler := func(ctx context.Context, broadcast *NotifParamsBladeBroadcast, raw *json.RawMessage) error {
+		return blade.eventNotif(ctx, *broadcast, raw)
+	}
+
+	if blade.Interceptors == nil {
+		return handler(ctx, broadcast, raw)
+	}
+
+	interceptors := blade.Interceptors.Incoming
+
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], handler
+		handler = func(ctx context.Context, broadcast *NotifParamsBladeBroadcast, raw *json.RawMessage) error {
+			return interceptor(ctx, broadcast, raw, next)
+		}
+	}
+
+	return handler(ctx, broadcast, raw)
+}
@@ -1,13 +1,17 @@
 package signalwire
 
 // Jsonrpc2Logger is a wrapper for sourcegraph jsonrpc2 logger
-type Jsonrpc2Logger struct{}
-
-//revive:disable:unused-receiver
+type Jsonrpc2Logger struct {
+	Log LoggerWrapper
+}
 
 // Printf is a logger for Jsonrpc2 library
 func (l *Jsonrpc2Logger) Printf(format string, args ...interface{}) {
-	Log.Trace(format, args...)
-}
+	if l == nil || l.Log == nil {
+		Log.Trace(format, args...)
 
-//revive:enable:unused-receiver
+		return
+	}
+
+	l.Log.Trace(format, args...)
+}
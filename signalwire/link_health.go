@@ -0,0 +1,148 @@
+package signalwire
+
+import (
+	"sync"
+	"time"
+)
+
+// ConnectionStateChange is a transition of the Blade session state
+type ConnectionStateChange struct {
+	Previous SessionState
+	State    SessionState
+	Time     time.Time
+	// Host is the Relay host of the session
+	Host string
+}
+
+// LinkHealth is a snapshot of the health of the Blade link
+type LinkHealth struct {
+	State SessionState
+	// StateSince is when the session entered State
+	StateSince time.Time
+	// LastPing is when the last ping was received from the server
+	LastPing time.Time
+	// ExecuteLatency is the round trip of the last blade.execute
+	ExecuteLatency time.Duration
+	// Reconnects is the number of times the link was brought back up
+	Reconnects int
+	// Host is the Relay host the link is up, or being brought up, to
+	Host string
+}
+
+// linkHealth is the runtime side of LinkHealth
+type linkHealth struct {
+	sync.Mutex
+	LinkHealth
+}
+
+func (h *linkHealth) ping() {
+	h.Lock()
+	h.LastPing = time.Now()
+	h.Unlock()
+}
+
+func (h *linkHealth) execute(rtt time.Duration) {
+	h.Lock()
+	h.ExecuteLatency = rtt
+	h.Unlock()
+}
+
+func (h *linkHealth) setHost(host string) {
+	h.Lock()
+	h.Host = host
+	h.Unlock()
+}
+
+func (h *linkHealth) reconnected() {
+	h.Lock()
+	h.Reconnects++
+	h.Unlock()
+}
+
+// transition records the new state, false if the state did not change
+func (h *linkHealth) transition(state SessionState) (ConnectionStateChange, bool) {
+	h.Lock()
+	defer h.Unlock()
+
+	change := ConnectionStateChange{
+		Previous: h.State,
+		State:    state,
+		Time:     time.Now(),
+		Host:     h.Host,
+	}
+
+	if h.State == state {
+		return change, false
+	}
+
+	h.State = state
+	h.StateSince = change.Time
+
+	return change, true
+}
+
+func (h *linkHealth) get() LinkHealth {
+	h.Lock()
+	defer h.Unlock()
+
+	return h.LinkHealth
+}
+
+// LinkHealth returns the health of the Blade link
+func (blade *BladeSession) LinkHealth() LinkHealth {
+	return blade.health.get()
+}
+
+// LinkHealth returns the health of the Blade link
+func (client *ClientSession) LinkHealth() LinkHealth {
+	if client.Relay.Blade == nil {
+		return LinkHealth{}
+	}
+
+	return client.Relay.Blade.LinkHealth()
+}
+
+// LinkHealth returns the health of the Blade link
+func (consumer *Consumer) LinkHealth() LinkHealth {
+	if consumer.Client == nil {
+		return LinkHealth{}
+	}
+
+	return consumer.Client.LinkHealth()
+}
+
+// onStateChange runs the callbacks and feeds the channels of the session
+func (client *ClientSession) onStateChange(change ConnectionStateChange) {
+	client.logger().Debug("Blade state: %v -> %v\n", change.Previous, change.State)
+
+	if client.OnConnectionStateChange != nil {
+		client.OnConnectionStateChange(client, change)
+	}
+
+	sendStateChange(client.ConnectionStates, change)
+
+	consumer := client.Consumer
+	if consumer == nil {
+		return
+	}
+
+	if consumer.OnConnectionStateChange != nil {
+		consumer.OnConnectionStateChange(consumer, change)
+	}
+
+	if consumer.ConnectionStates != client.ConnectionStates {
+		sendStateChange(consumer.ConnectionStates, change)
+	}
+}
+
+// sendStateChange never blocks the Blade session, the change is dropped if ch is full
+func sendStateChange(ch chan ConnectionStateChange, change ConnectionStateChange) {
+	if ch == nil {
+		return
+	}
+
+	select {
+	case ch <- change:
+	default:
+	}
+}
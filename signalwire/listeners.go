@@ -0,0 +1,455 @@
+package signalwire
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// CallEvent is an event of a call that listeners can subscribe to with CallObj.On
+type CallEvent int
+
+// Call events, one per callback field of CallObj (but EventPromptUpdate)
+const (
+	EventStateChange CallEvent = iota
+	EventRinging
+	EventAnswered
+	EventEnding
+	EventEnded
+	EventPlayFinished
+	EventPlayPaused
+	EventPlayError
+	EventPlayPlaying
+	EventPlayStateChange
+	EventRecordStateChange
+	EventRecordRecording
+	EventRecordPaused
+	EventRecordFinished
+	EventRecordNoInput
+	EventDetectUpdate
+	EventDetectError
+	EventDetectFinished
+	EventFaxFinished
+	EventFaxPage
+	EventFaxError
+	EventConnectStateChange
+	EventConnectFailed
+	EventConnectConnecting
+	EventConnectConnected
+	EventConnectDisconnected
+	EventTapStateChange
+	EventTapFinished
+	EventTapTapping
+	EventSendDigitsFinished
+	EventSendDigitsStateChange
+	EventPrompt
+	// EventPromptUpdate is a partial result of a prompt, it has no callback field
+	EventPromptUpdate
+)
+
+func (e CallEvent) String() string {
+	return [...]string{
+		"StateChange",
+		"Ringing",
+		"Answered",
+		"Ending",
+		"Ended",
+		"PlayFinished",
+		"PlayPaused",
+		"PlayError",
+		"PlayPlaying",
+		"PlayStateChange",
+		"RecordStateChange",
+		"RecordRecording",
+		"RecordPaused",
+		"RecordFinished",
+		"RecordNoInput",
+		"DetectUpdate",
+		"DetectError",
+		"DetectFinished",
+		"FaxFinished",
+		"FaxPage",
+		"FaxError",
+		"ConnectStateChange",
+		"ConnectFailed",
+		"ConnectConnecting",
+		"ConnectConnected",
+		"ConnectDisconnected",
+		"TapStateChange",
+		"TapFinished",
+		"TapTapping",
+		"SendDigitsFinished",
+		"SendDigitsStateChange",
+		"Prompt",
+		"PromptUpdate",
+	}[e]
+}
+
+// CallEventArgs is passed to the listeners. Call is always set, of the
+// actions only the one the event is about (none for the call state events).
+type CallEventArgs struct {
+	Event      CallEvent
+	Call       *CallObj
+	Play       *PlayAction
+	Record     *RecordAction
+	Detect     *DetectAction
+	Fax        *FaxAction
+	Connect    *ConnectAction
+	Tap        *TapAction
+	SendDigits *SendDigitsAction
+	Prompt     *PromptAction
+	// ActionState is the state of the action when the event arrived, nil for the call state events
+	ActionState *ActionState
+	// action is the action the event is about
+	action interface{}
+}
+
+// ActionState is a copy of the state of an action when one of its events
+// arrived, the action itself goes on changing with the later events.
+type ActionState struct {
+	ControlID string
+	Completed bool
+	// State is the PlayState, RecordState, CallConnectState, TapState or
+	// SendDigitsState of the action, nil for detect and fax
+	State interface{}
+	// Result is the PlayResult, RecordResult, DetectResult, FaxResult,
+	// ConnectResult, TapResult, SendDigitsResult or CollectResult of the action
+	Result  interface{}
+	Payload *json.RawMessage
+	Err     error
+}
+
+// CallEventListener is a function subscribed to the events of a call
+type CallEventListener func(*CallEventArgs)
+
+type callListener struct {
+	id    uint64
+	event CallEvent
+	any   bool
+	fn    CallEventListener
+}
+
+// callListeners holds the listeners of a call, in subscription order
+type callListeners struct {
+	sync.Mutex
+	next uint64
+	list []callListener
+}
+
+// On subscribes fn to event, next to the callback field of the event and to
+// the other listeners. Returns the function that unsubscribes it.
+func (callobj *CallObj) On(event CallEvent, fn CallEventListener) func() {
+	return callobj.listeners.add(callListener{event: event, fn: fn})
+}
+
+// OnAny subscribes fn to every event of the call. Returns the function that unsubscribes it.
+func (callobj *CallObj) OnAny(fn CallEventListener) func() {
+	return callobj.listeners.add(callListener{any: true, fn: fn})
+}
+
+func (l *callListeners) add(listener callListener) func() {
+	if listener.fn == nil {
+		return func() {}
+	}
+
+	l.Lock()
+
+	l.next++
+	listener.id = l.next
+	l.list = append(l.list, listener)
+
+	l.Unlock()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			l.remove(listener.id)
+		})
+	}
+}
+
+func (l *callListeners) remove(id uint64) {
+	l.Lock()
+	defer l.Unlock()
+
+	for i := range l.list {
+		if l.list[i].id == id {
+			// copy, emit may be going through the old slice
+			list := make([]callListener, 0, len(l.list)-1)
+			list = append(list, l.list[:i]...)
+			l.list = append(list, l.list[i+1:]...)
+
+			return
+		}
+	}
+}
+
+// get returns the listeners of event, wildcard ones last
+func (l *callListeners) get(event CallEvent) []CallEventListener {
+	l.Lock()
+	list := l.list
+	l.Unlock()
+
+	var fns, any []CallEventListener
+
+	for _, listener := range list {
+		switch {
+		case listener.any:
+			any = append(any, listener.fn)
+		case listener.event == event:
+			fns = append(fns, listener.fn)
+		}
+	}
+
+	return append(fns, any...)
+}
+
+// emit queues the callback field of the event, then the listeners, in the
+// slot of the event on the callback queue of the call (at the end of the
+// queue if nil). action is the *XxxAction the event is about, nil for the
+// call state events, its current state is copied to ActionState.
+func (callobj *CallObj) emit(slot *callbackSlot, event CallEvent, action interface{}) {
+	args := &CallEventArgs{Event: event, Call: callobj, action: action}
+
+	switch a := action.(type) {
+	case *PlayAction:
+		args.Play = a
+		args.ActionState = a.state()
+	case *RecordAction:
+		args.Record = a
+		args.ActionState = a.state()
+	case *DetectAction:
+		args.Detect = a
+		args.ActionState = a.state()
+	case *FaxAction:
+		args.Fax = a
+		args.ActionState = a.state()
+	case *ConnectAction:
+		args.Connect = a
+		args.ActionState = a.state()
+	case *TapAction:
+		args.Tap = a
+		args.ActionState = a.state()
+	case *SendDigitsAction:
+		args.SendDigits = a
+		args.ActionState = a.state()
+	case *PromptAction:
+		args.Prompt = a
+		args.ActionState = a.state()
+	}
+
+	fn := func() {
+		callobj.runCallback(args)
+
+		for _, fn := range callobj.listeners.get(event) {
+			fn(args)
+		}
+	}
+
+	if slot == nil {
+		callobj.call.callbacks.enqueue(fn)
+
+		return
+	}
+
+	slot.add(fn)
+}
+
+func (action *PlayAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		State:     action.State,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+func (action *RecordAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		State:     action.State,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+func (action *DetectAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+func (action *FaxAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+func (action *ConnectAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		State:     action.State,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+func (action *TapAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		State:     action.State,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+func (action *SendDigitsAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		State:     action.State,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+func (action *PromptAction) state() *ActionState {
+	action.RLock()
+	defer action.RUnlock()
+
+	return &ActionState{
+		ControlID: action.ControlID,
+		Completed: action.Completed,
+		State:     action.State,
+		Result:    action.Result,
+		Payload:   action.Payload,
+		Err:       action.err,
+	}
+}
+
+// runCallback runs the callback field of the event, if set
+func (callobj *CallObj) runCallback(args *CallEventArgs) {
+	switch args.Event {
+	case EventStateChange, EventRinging, EventAnswered, EventEnding, EventEnded:
+		cb := map[CallEvent]func(*CallObj){
+			EventStateChange: callobj.OnStateChange,
+			EventRinging:     callobj.OnRinging,
+			EventAnswered:    callobj.OnAnswered,
+			EventEnding:      callobj.OnEnding,
+			EventEnded:       callobj.OnEnded,
+		}[args.Event]
+		if cb != nil {
+			cb(callobj)
+		}
+	case EventPlayFinished, EventPlayPaused, EventPlayError, EventPlayPlaying, EventPlayStateChange:
+		cb := map[CallEvent]func(*PlayAction){
+			EventPlayFinished:    callobj.OnPlayFinished,
+			EventPlayPaused:      callobj.OnPlayPaused,
+			EventPlayError:       callobj.OnPlayError,
+			EventPlayPlaying:     callobj.OnPlayPlaying,
+			EventPlayStateChange: callobj.OnPlayStateChange,
+		}[args.Event]
+		if cb != nil {
+			cb(args.Play)
+		}
+	case EventRecordStateChange, EventRecordRecording, EventRecordPaused, EventRecordFinished, EventRecordNoInput:
+		cb := map[CallEvent]func(*RecordAction){
+			EventRecordStateChange: callobj.OnRecordStateChange,
+			EventRecordRecording:   callobj.OnRecordRecording,
+			EventRecordPaused:      callobj.OnRecordPaused,
+			EventRecordFinished:    callobj.OnRecordFinished,
+			EventRecordNoInput:     callobj.OnRecordNoInput,
+		}[args.Event]
+		if cb != nil {
+			cb(args.Record)
+		}
+	case EventDetectUpdate, EventDetectError, EventDetectFinished:
+		cb := map[CallEvent]func(*DetectAction){
+			EventDetectUpdate:   callobj.OnDetectUpdate,
+			EventDetectError:    callobj.OnDetectError,
+			EventDetectFinished: callobj.OnDetectFinished,
+		}[args.Event]
+		if cb != nil {
+			cb(args.Detect)
+		}
+	case EventFaxFinished, EventFaxPage, EventFaxError:
+		cb := map[CallEvent]func(*FaxAction){
+			EventFaxFinished: callobj.OnFaxFinished,
+			EventFaxPage:     callobj.OnFaxPage,
+			EventFaxError:    callobj.OnFaxError,
+		}[args.Event]
+		if cb != nil {
+			cb(args.Fax)
+		}
+	case EventConnectStateChange, EventConnectFailed, EventConnectConnecting, EventConnectConnected, EventConnectDisconnected:
+		cb := map[CallEvent]func(*ConnectAction){
+			EventConnectStateChange:  callobj.OnConnectStateChange,
+			EventConnectFailed:       callobj.OnConnectFailed,
+			EventConnectConnecting:   callobj.OnConnectConnecting,
+			EventConnectConnected:    callobj.OnConnectConnected,
+			EventConnectDisconnected: callobj.OnConnectDisconnected,
+		}[args.Event]
+		if cb != nil {
+			cb(args.Connect)
+		}
+	case EventTapStateChange, EventTapFinished, EventTapTapping:
+		cb := map[CallEvent]func(*TapAction){
+			EventTapStateChange: callobj.OnTapStateChange,
+			EventTapFinished:    callobj.OnTapFinished,
+			EventTapTapping:     callobj.OnTapTapping,
+		}[args.Event]
+		if cb != nil {
+			cb(args.Tap)
+		}
+	case EventSendDigitsFinished, EventSendDigitsStateChange:
+		cb := map[CallEvent]func(*SendDigitsAction){
+			EventSendDigitsFinished:    callobj.OnSendDigitsFinished,
+			EventSendDigitsStateChange: callobj.OnSendDigitsStateChange,
+		}[args.Event]
+		if cb != nil {
+			cb(args.SendDigits)
+		}
+	case EventPrompt:
+		if callobj.OnPrompt != nil {
+			callobj.OnPrompt(args.Prompt)
+		}
+	}
+}
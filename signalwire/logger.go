@@ -23,3 +23,142 @@ type LoggerWrapper interface {
 
 	SetLevel(level int)
 }
+
+// The loggers below are scoped to a Consumer/ClientSession: the logger set
+// on the session is used, the package Log if there is none.
+
+func (consumer *Consumer) logger() LoggerWrapper {
+	if consumer == nil || consumer.Log == nil {
+		return Log
+	}
+
+	return consumer.Log
+}
+
+func (client *ClientSession) logger() LoggerWrapper {
+	if client == nil || client.Log == nil {
+		return Log
+	}
+
+	return client.Log
+}
+
+func (blade *BladeSession) logger() LoggerWrapper {
+	if blade == nil || blade.Log == nil {
+		return Log
+	}
+
+	return blade.Log
+}
+
+func (relay *RelaySession) logger() LoggerWrapper {
+	if relay == nil {
+		return Log
+	}
+
+	return relay.Blade.logger()
+}
+
+func (calling *Calling) logger() LoggerWrapper {
+	if calling == nil {
+		return Log
+	}
+
+	return calling.Relay.logger()
+}
+
+func (messaging *Messaging) logger() LoggerWrapper {
+	if messaging == nil {
+		return Log
+	}
+
+	return messaging.Relay.logger()
+}
+
+func (tasking *Tasking) logger() LoggerWrapper {
+	if tasking == nil {
+		return Log
+	}
+
+	return tasking.Relay.logger()
+}
+
+func (callobj *CallObj) logger() LoggerWrapper {
+	if callobj == nil {
+		return Log
+	}
+
+	return callobj.Calling.logger()
+}
+
+func (msgobj *MsgObj) logger() LoggerWrapper {
+	if msgobj == nil {
+		return Log
+	}
+
+	return msgobj.Messaging.logger()
+}
+
+func (c *CallSession) logger() LoggerWrapper {
+	if c == nil {
+		return Log
+	}
+
+	return c.Blade.logger()
+}
+
+func (calling *EventCalling) logger() LoggerWrapper {
+	if calling == nil {
+		return Log
+	}
+
+	return calling.blade.logger()
+}
+
+func (messaging *EventMessaging) logger() LoggerWrapper {
+	if messaging == nil {
+		return Log
+	}
+
+	return messaging.blade.logger()
+}
+
+func (tasking *EventTasking) logger() LoggerWrapper {
+	if tasking == nil {
+		return Log
+	}
+
+	return tasking.blade.logger()
+}
+
+func (detectaction *DetectAction) logger() LoggerWrapper {
+	if detectaction == nil {
+		return Log
+	}
+
+	return detectaction.CallObj.logger()
+}
+
+func (recordaction *RecordAction) logger() LoggerWrapper {
+	if recordaction == nil {
+		return Log
+	}
+
+	return recordaction.CallObj.logger()
+}
+
+func (cache *BCache) logger() LoggerWrapper {
+	if cache == nil || cache.log == nil {
+		return Log
+	}
+
+	return cache.log
+}
+
+func (h ReqHandler) logger() LoggerWrapper {
+	if h.log == nil {
+		return Log
+	}
+
+	return h.log
+}
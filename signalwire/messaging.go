@@ -78,7 +78,7 @@ func (messaging *Messaging) NewMessage(signalwireContext, from, to, text string)
 	return m
 }
 
-func (msgobj *MsgObj) callbacksRunSend(_ context.Context, res *SendResult) {
+func (msgobj *MsgObj) callbacksRunSend(ctx context.Context, res *SendResult) {
 	var out bool
 
 	timer := time.NewTimer(BroadcastEventTimeout * time.Second)
@@ -167,7 +167,7 @@ func (msgobj *MsgObj) callbacksRunSend(_ context.Context, res *SendResult) {
 					go msgobj.OnMessageInitiated(res)
 				}
 			default:
-				Log.Debug("Unknown state.")
+				msgobj.logger().Debug("Unknown state.")
 			}
 
 			if prevstate != state && msgobj.OnMessageStateChange != nil {
@@ -175,6 +175,8 @@ func (msgobj *MsgObj) callbacksRunSend(_ context.Context, res *SendResult) {
 			}
 		case <-timer.C:
 			out = true
+		case <-ctx.Done():
+			out = true
 		}
 
 		if out {
@@ -185,13 +187,18 @@ func (msgobj *MsgObj) callbacksRunSend(_ context.Context, res *SendResult) {
 
 // Send TODO DESCRIPTION
 func (messaging *Messaging) Send(fromNumber, toNumber, signalwireContext, msgBody string) *SendResult {
+	return messaging.SendContext(messaging.Ctx, fromNumber, toNumber, signalwireContext, msgBody)
+}
+
+// SendContext is Send, it returns with ctx.Err() as error when ctx is done first
+func (messaging *Messaging) SendContext(ctx context.Context, fromNumber, toNumber, signalwireContext, msgBody string) *SendResult {
 	res := new(SendResult)
 
 	if messaging.Relay == nil {
 		return res
 	}
 
-	if messaging.Ctx == nil {
+	if messaging.Ctx == nil || ctx == nil {
 		return res
 	}
 
@@ -202,9 +209,9 @@ func (messaging *Messaging) Send(fromNumber, toNumber, signalwireContext, msgBod
 
 	var msgID string
 
-	msgID, err = messaging.Relay.RelaySendMessage(messaging.Ctx, newmsg, fromNumber, toNumber, signalwireContext, msgBody)
+	msgID, err = messaging.Relay.RelaySendMessage(ctx, newmsg, fromNumber, toNumber, signalwireContext, msgBody)
 	if err != nil {
-		Log.Error("RelaySendMessage: %v", err)
+		messaging.logger().Error("RelaySendMessage: %v", err)
 		res.err = err
 
 		return res
@@ -221,20 +228,29 @@ func (messaging *Messaging) Send(fromNumber, toNumber, signalwireContext, msgBod
 	res.Msg = m
 
 	/*no callbacks*/
-	res.Msg.callbacksRunSend(messaging.Ctx, res)
+	res.Msg.callbacksRunSend(ctx, res)
+
+	if err := ctx.Err(); err != nil && !res.GetCompleted() {
+		res.err = err
+	}
 
 	return res
 }
 
 // SendMsg TODO DESCRIPTION
 func (messaging *Messaging) SendMsg(mObj *MsgObj) *SendResult {
+	return messaging.SendMsgContext(messaging.Ctx, mObj)
+}
+
+// SendMsgContext is SendMsg, it returns with ctx.Err() as error when ctx is done first
+func (messaging *Messaging) SendMsgContext(ctx context.Context, mObj *MsgObj) *SendResult {
 	res := new(SendResult)
 
 	if messaging.Relay == nil {
 		return res
 	}
 
-	if messaging.Ctx == nil {
+	if messaging.Ctx == nil || ctx == nil {
 		return res
 	}
 
@@ -247,14 +263,14 @@ func (messaging *Messaging) SendMsg(mObj *MsgObj) *SendResult {
 	done := make(chan struct{})
 
 	go func() {
-		mObj.callbacksRunSend(messaging.Ctx, res)
+		mObj.callbacksRunSend(ctx, res)
 
 		done <- struct{}{}
 	}()
 
-	msgID, err = messaging.Relay.RelaySendMessage(messaging.Ctx, mObj.msg, mObj.msg.MsgParams.From, mObj.msg.MsgParams.To, mObj.msg.MsgParams.Context, mObj.msg.MsgParams.Body)
+	msgID, err = messaging.Relay.RelaySendMessage(ctx, mObj.msg, mObj.msg.MsgParams.From, mObj.msg.MsgParams.To, mObj.msg.MsgParams.Context, mObj.msg.MsgParams.Body)
 	if err != nil {
-		Log.Error("RelaySendMessage: %v", err)
+		messaging.logger().Error("RelaySendMessage: %v", err)
 		res.err = err
 
 		return res
@@ -266,6 +282,10 @@ func (messaging *Messaging) SendMsg(mObj *MsgObj) *SendResult {
 
 	<-done
 
+	if err := ctx.Err(); err != nil && !res.GetCompleted() {
+		res.err = err
+	}
+
 	return res
 }
 
@@ -274,6 +294,19 @@ func (resultSend *SendResult) GetSuccessful() bool {
 	return resultSend.Successful
 }
 
+// GetCompleted TODO DESCRIPTION
+func (resultSend *SendResult) GetCompleted() bool {
+	resultSend.RLock()
+	defer resultSend.RUnlock()
+
+	return resultSend.Completed
+}
+
+// GetError TODO DESCRIPTION
+func (resultSend *SendResult) GetError() error {
+	return resultSend.err
+}
+
 // GetReason TODO DESCRIPTION
 func (resultSend *SendResult) GetReason() string {
 	if resultSend.Msg != nil {
@@ -0,0 +1,86 @@
+package signalwire
+
+// protocolRemoved sets the session up again when the platform removes its protocol (node drain)
+func (blade *BladeSession) protocolRemoved(protocol string) {
+	if len(protocol) == 0 || protocol != blade.Protocol || blade.GetState() != BladeRunning {
+		return
+	}
+
+	blade.logger().Info("protocol %s removed, setting up the session again\n", protocol)
+
+	select {
+	case blade.migrate <- struct{}{}:
+	default:
+	}
+}
+
+// reconnectSession drops the link, the session reconnects to Relay. With
+// renew a new session is started instead of restoring the current one.
+func (blade *BladeSession) reconnectSession(renew bool) {
+	blade.Tmutex.Lock()
+
+	if renew {
+		if id, err := GenUUIDv4(); err == nil {
+			blade.SessionID = id
+		}
+	}
+
+	blade.WantReconnect = true
+	conn := blade.conn
+
+	blade.Tmutex.Unlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// migrateSession sets up the protocol, the subscriptions and the contexts
+// again on the live link. The calls keep going on the new protocol, their
+// CallObj still valid. On failure a new session is started: the calls are
+// then orphaned.
+func (client *ClientSession) migrateSession() bool {
+	blade := client.Relay.Blade
+
+	blade.setState(BladeConnected)
+
+	if err := client.setupSession(client.Ctx); err != nil {
+		client.logger().Warn("cannot set up the session again, reconnecting: %v\n", err)
+
+		blade.reconnectSession(true)
+
+		return false
+	}
+
+	if calls, err := blade.EventCalling.Cache.GetAllCallsCache(); err == nil {
+		client.logger().Info("session set up again, %d calls migrated\n", len(calls))
+	}
+
+	return true
+}
+
+// onOrphanedCalls runs the callbacks with the calls lost with the session
+func (client *ClientSession) onOrphanedCalls(calls []*CallSession) {
+	if client.OnOrphanedCalls == nil && (client.Consumer == nil || client.Consumer.OnOrphanedCalls == nil) {
+		return
+	}
+
+	objs := make([]*CallObj, len(calls))
+
+	for i, call := range calls {
+		var I ICallObj = CallObjNew()
+
+		c := &CallObj{I: I}
+		c.call = call
+		c.Calling = &client.Calling
+		objs[i] = c
+	}
+
+	if client.OnOrphanedCalls != nil {
+		client.OnOrphanedCalls(client, objs)
+	}
+
+	if client.Consumer != nil && client.Consumer.OnOrphanedCalls != nil {
+		client.Consumer.OnOrphanedCalls(client.Consumer, objs)
+	}
+}
@@ -0,0 +1,204 @@
+package signalwire
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Outbound queue defaults
+const (
+	DefaultOutboundQueueSize   = 100
+	DefaultOutboundQueueMaxAge = 10 * time.Second
+)
+
+// Outbound queue errors
+var (
+	ErrOutboundQueueTimeout = errors.New("relay command not sent: timeout waiting for the Blade link to come back")
+	ErrOutboundQueueFull    = errors.New("relay command not sent: outbound queue full")
+	ErrOutboundQueueClosed  = errors.New("relay command not sent: Blade session closed")
+)
+
+// OutboundQueue holds the Relay commands issued while the Blade link is
+// reconnecting and replays them in order once the session is running again.
+type OutboundQueue struct {
+	// Size is the max number of commands held, 0 means DefaultOutboundQueueSize
+	Size int
+	// MaxAge is the max time a command is held, 0 means DefaultOutboundQueueMaxAge
+	MaxAge time.Duration
+}
+
+type outboundCmd struct {
+	ready chan error
+}
+
+// outboundQueue is the runtime side of OutboundQueue
+type outboundQueue struct {
+	sync.Mutex
+	pending  []*outboundCmd
+	inflight *outboundCmd
+	open     bool
+	closeErr error
+}
+
+type outboundBypassKey struct{}
+
+// withoutOutboundQueue marks ctx for the commands of the Blade handshake,
+// which must go out while the session is not running yet.
+func withoutOutboundQueue(ctx context.Context) context.Context {
+	return context.WithValue(ctx, outboundBypassKey{}, true)
+}
+
+func outboundBypass(ctx context.Context) bool {
+	bypass, _ := ctx.Value(outboundBypassKey{}).(bool)
+
+	return bypass
+}
+
+func (conf *OutboundQueue) size() int {
+	if conf.Size > 0 {
+		return conf.Size
+	}
+
+	return DefaultOutboundQueueSize
+}
+
+func (conf *OutboundQueue) maxAge() time.Duration {
+	if conf.MaxAge > 0 {
+		return conf.MaxAge
+	}
+
+	return DefaultOutboundQueueMaxAge
+}
+
+// acquire returns when the command can be sent. release must be called once
+// the command completed, to let the next queued command go.
+// A nil conf means no queueing.
+func (q *outboundQueue) acquire(ctx context.Context, conf *OutboundQueue) (release func(), err error) {
+	q.Lock()
+
+	if conf == nil || (q.open && q.inflight == nil && len(q.pending) == 0) {
+		q.Unlock()
+
+		return func() {}, nil
+	}
+
+	if q.closeErr != nil {
+		err = q.closeErr
+		q.Unlock()
+
+		return nil, err
+	}
+
+	if len(q.pending) >= conf.size() {
+		q.Unlock()
+
+		return nil, ErrOutboundQueueFull
+	}
+
+	cmd := &outboundCmd{ready: make(chan error, 1)}
+	q.pending = append(q.pending, cmd)
+
+	q.Unlock()
+
+	timer := time.NewTimer(conf.maxAge())
+	defer timer.Stop()
+
+	select {
+	case err = <-cmd.ready:
+		if err != nil {
+			return nil, err
+		}
+
+		return func() { q.release(cmd) }, nil
+	case <-timer.C:
+		err = ErrOutboundQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	q.Lock()
+
+	if !q.remove(cmd) {
+		// it was released in the meantime
+		if rerr := <-cmd.ready; rerr == nil {
+			q.inflight = nil
+			q.next()
+		}
+	}
+
+	q.Unlock()
+
+	return nil, err
+}
+
+// remove takes cmd out of the pending list, false if it was not there
+func (q *outboundQueue) remove(cmd *outboundCmd) bool {
+	for i, c := range q.pending {
+		if c == cmd {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+
+			return true
+		}
+	}
+
+	return false
+}
+
+// next lets the oldest pending command go, if the link is up.
+// Must be called with the lock held.
+func (q *outboundQueue) next() {
+	if !q.open || q.inflight != nil || len(q.pending) == 0 {
+		return
+	}
+
+	q.inflight = q.pending[0]
+	q.pending = q.pending[1:]
+	q.inflight.ready <- nil
+}
+
+func (q *outboundQueue) release(cmd *outboundCmd) {
+	q.Lock()
+
+	if q.inflight == cmd {
+		q.inflight = nil
+		q.next()
+	}
+
+	q.Unlock()
+}
+
+// resume replays the pending commands, the link is up
+func (q *outboundQueue) resume() {
+	q.Lock()
+
+	q.open = true
+	q.closeErr = nil
+	q.next()
+
+	q.Unlock()
+}
+
+// hold queues the new commands, the link is down
+func (q *outboundQueue) hold() {
+	q.Lock()
+	q.open = false
+	q.Unlock()
+}
+
+// drop fails every pending command and the ones issued later with err
+func (q *outboundQueue) drop(err error) {
+	q.Lock()
+
+	q.open = false
+	q.closeErr = err
+
+	for _, cmd := range q.pending {
+		cmd.ready <- err
+	}
+
+	q.pending = nil
+
+	q.Unlock()
+}
@@ -0,0 +1,166 @@
+package signalwire
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// RawEventAll subscribes to every event
+const RawEventAll = "*"
+
+// RawEvent is a Relay event as received, including the event types the SDK does not know yet
+type RawEvent struct {
+	// Event is the Blade broadcast event, like queuing.relay.events
+	Event string
+	// EventType is the Relay event type, like calling.call.state
+	EventType string
+	// Params are the params of the event
+	Params json.RawMessage
+	// Broadcast is the whole blade.broadcast params
+	Broadcast json.RawMessage
+}
+
+// RawEventHandler is called from the go routine of the session: it must not block
+type RawEventHandler func(*RawEvent)
+
+// rawEvents holds the subscriptions to raw events, by event type
+type rawEvents struct {
+	sync.RWMutex
+	m    map[string]map[int]RawEventHandler
+	next int
+}
+
+func (r *rawEvents) subscribe(eventType string, h RawEventHandler) func() {
+	r.Lock()
+
+	if r.m == nil {
+		r.m = make(map[string]map[int]RawEventHandler)
+	}
+
+	if r.m[eventType] == nil {
+		r.m[eventType] = make(map[int]RawEventHandler)
+	}
+
+	id := r.next
+	r.next++
+	r.m[eventType][id] = h
+
+	r.Unlock()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			r.Lock()
+			delete(r.m[eventType], id)
+			r.Unlock()
+		})
+	}
+}
+
+// dispatch runs the handlers of the event, false if there was none
+func (r *rawEvents) dispatch(broadcast NotifParamsBladeBroadcast, rawEvent *json.RawMessage) bool {
+	if r == nil {
+		return false
+	}
+
+	eventType := broadcast.Params.EventType
+	if len(eventType) == 0 {
+		eventType = broadcast.Event
+	}
+
+	r.RLock()
+
+	handlers := make([]RawEventHandler, 0, len(r.m[eventType])+len(r.m[RawEventAll]))
+
+	for _, h := range r.m[eventType] {
+		handlers = append(handlers, h)
+	}
+
+	for _, h := range r.m[RawEventAll] {
+		handlers = append(handlers, h)
+	}
+
+	r.RUnlock()
+
+	if len(handlers) == 0 {
+		return false
+	}
+
+	ev := &RawEvent{
+		Event:     broadcast.Event,
+		EventType: eventType,
+	}
+
+	if params, err := json.Marshal(broadcast.Params.Params); err == nil {
+		ev.Params = params
+	}
+
+	if rawEvent != nil {
+		ev.Broadcast = *rawEvent
+	}
+
+	for _, h := range handlers {
+		h(ev)
+	}
+
+	return true
+}
+
+// events returns the raw event subscriptions of the session, kept across reconnects
+func (client *ClientSession) events() *rawEvents {
+	client.rawEventsOnce.Do(func() {
+		client.rawEvents = new(rawEvents)
+	})
+
+	return client.rawEvents
+}
+
+// Subscribe calls h for every event of eventType (RawEventAll for all of them),
+// before the SDK handles it. It returns a func to unsubscribe.
+func (client *ClientSession) Subscribe(eventType string, h RawEventHandler) func() {
+	return client.events().subscribe(eventType, h)
+}
+
+// Subscribe calls h for every event of eventType (RawEventAll for all of them),
+// before the SDK handles it. It returns a func to unsubscribe.
+func (consumer *Consumer) Subscribe(eventType string, h RawEventHandler) func() {
+	return consumer.Client.Subscribe(eventType, h)
+}
+
+// BladeExecuteRaw executes a Relay method and returns its result as received
+func (blade *BladeSession) BladeExecuteRaw(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	if blade == nil {
+		return nil, errors.New("empty blade session object")
+	}
+
+	var reply struct {
+		Result json.RawMessage `json:"result"`
+	}
+
+	v := ParamsBladeExecuteStruct{
+		Protocol: blade.Protocol,
+		Method:   method,
+		Params:   params,
+	}
+
+	if _, err := blade.I.BladeExecute(ctx, &v, &reply); err != nil {
+		return nil, err
+	}
+
+	var result ReplyBladeExecuteResult
+
+	if err := json.Unmarshal(reply.Result, &result); err == nil && len(result.Code) > 0 && result.Code != okCode {
+		return reply.Result, errors.New(result.Message)
+	}
+
+	return reply.Result, nil
+}
+
+// ExecuteRaw executes a Relay method on the session, for the methods the SDK
+// does not wrap yet. The result is returned as received.
+func (client *ClientSession) ExecuteRaw(ctx context.Context, method string, params interface{}) (json.RawMessage, error) {
+	return client.Relay.Blade.BladeExecuteRaw(ctx, method, params)
+}
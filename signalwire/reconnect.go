@@ -0,0 +1,122 @@
+package signalwire
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ErrReconnectGaveUp is returned when the ReconnectPolicy is exhausted
+var ErrReconnectGaveUp = errors.New("cannot reconnect to Blade (reconnect policy exhausted)")
+
+// ReconnectPolicy controls how the connection to Relay is retried,
+// at connect time (network/Blade timeouts) and after the link was lost.
+type ReconnectPolicy struct {
+	// InitialDelay before the first attempt
+	InitialDelay time.Duration
+	// Multiplier applied to the delay after each failed attempt (< 1 means 1)
+	Multiplier float64
+	// MaxDelay caps the delay between attempts (0 means no cap)
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of each delay that is randomized,
+	// so that many clients don't reconnect in lockstep.
+	Jitter float64
+	// MaxAttempts before giving up (0 means no limit)
+	MaxAttempts int
+	// Deadline is the overall time allowed for reconnecting (0 means no limit)
+	Deadline time.Duration
+	// OnAttempt is called before each attempt, with the error of the previous one
+	OnAttempt func(attempt int, delay time.Duration, lastErr error)
+	// OnGiveUp is called once when the policy is exhausted
+	OnGiveUp func(attempts int, lastErr error)
+}
+
+// DefaultReconnectPolicy returns the policy used when none is set: retry
+// forever, from 1 second up to 30 seconds between attempts.
+func DefaultReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		InitialDelay: 1 * time.Second,
+		Multiplier:   2,
+		MaxDelay:     30 * time.Second,
+		Jitter:       0.3,
+	}
+}
+
+// reconnectAttempts keeps track of the attempts of one reconnection
+type reconnectAttempts struct {
+	policy  *ReconnectPolicy
+	attempt int
+	started time.Time
+	log     LoggerWrapper
+}
+
+// start begins a new series of attempts. A nil policy means the default one.
+func (p *ReconnectPolicy) start(log LoggerWrapper) *reconnectAttempts {
+	if p == nil {
+		p = DefaultReconnectPolicy()
+	}
+
+	return &reconnectAttempts{
+		policy:  p,
+		started: time.Now(),
+		log:     log,
+	}
+}
+
+// backoff returns the delay before attempt n (starting at 1), without jitter
+func (p *ReconnectPolicy) backoff(n int) time.Duration {
+	delay := p.InitialDelay
+
+	for i := 1; i < n && p.Multiplier > 1; i++ {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+
+		if p.MaxDelay > 0 && delay >= p.MaxDelay {
+			break
+		}
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return delay
+}
+
+// next returns the delay to wait before the next attempt,
+// or false if the policy gives up.
+func (r *reconnectAttempts) next(lastErr error) (time.Duration, bool) {
+	p := r.policy
+	r.attempt++
+
+	delay := p.backoff(r.attempt)
+
+	if p.Jitter > 0 {
+		jitter := p.Jitter
+		if jitter > 1 {
+			jitter = 1
+		}
+
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay)) // nolint: gosec
+	}
+
+	giveUp := p.MaxAttempts > 0 && r.attempt > p.MaxAttempts
+	if p.Deadline > 0 && time.Since(r.started)+delay > p.Deadline {
+		giveUp = true
+	}
+
+	if giveUp {
+		r.log.Error("giving up reconnecting after %d attempts: %v\n", r.attempt-1, lastErr)
+
+		if p.OnGiveUp != nil {
+			p.OnGiveUp(r.attempt-1, lastErr)
+		}
+
+		return 0, false
+	}
+
+	if p.OnAttempt != nil {
+		p.OnAttempt(r.attempt, delay, lastErr)
+	}
+
+	return delay, true
+}
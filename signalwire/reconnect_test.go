@@ -0,0 +1,85 @@
+package signalwire
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestReconnectPolicy(t *testing.T) {
+	t.Run(
+		"Backoff",
+		func(t *testing.T) {
+			p := &ReconnectPolicy{
+				InitialDelay: 100 * time.Millisecond,
+				Multiplier:   2,
+				MaxDelay:     time.Second,
+			}
+			attempts := p.start(Log)
+			want := []time.Duration{100, 200, 400, 800, 1000, 1000}
+			for i, w := range want {
+				delay, ok := attempts.next(nil)
+				assert.True(t, ok, "policy without limits must not give up")
+				assert.Equal(t, w*time.Millisecond, delay, "wrong delay for attempt %d", i+1)
+			}
+		},
+	)
+	t.Run(
+		"Jitter",
+		func(t *testing.T) {
+			p := &ReconnectPolicy{
+				InitialDelay: time.Second,
+				Jitter:       0.5,
+			}
+			attempts := p.start(Log)
+			for i := 0; i < 100; i++ {
+				delay, _ := attempts.next(nil)
+				assert.True(t, delay > 500*time.Millisecond && delay <= time.Second, "delay out of jitter range: %v", delay)
+			}
+		},
+	)
+	t.Run(
+		"MaxAttempts",
+		func(t *testing.T) {
+			var (
+				tries   []int
+				gaveUp  int
+				lastErr error
+			)
+			errConn := errors.New("connection refused")
+			p := &ReconnectPolicy{
+				MaxAttempts: 3,
+				OnAttempt: func(attempt int, _ time.Duration, _ error) {
+					tries = append(tries, attempt)
+				},
+				OnGiveUp: func(attempts int, err error) {
+					gaveUp = attempts
+					lastErr = err
+				},
+			}
+			attempts := p.start(Log)
+			for i := 0; i < 3; i++ {
+				_, ok := attempts.next(errConn)
+				assert.True(t, ok, "must retry")
+			}
+			_, ok := attempts.next(errConn)
+			assert.False(t, ok, "must give up")
+			assert.Equal(t, []int{1, 2, 3}, tries)
+			assert.Equal(t, 3, gaveUp)
+			assert.Equal(t, errConn, lastErr)
+		},
+	)
+	t.Run(
+		"Deadline",
+		func(t *testing.T) {
+			p := &ReconnectPolicy{
+				InitialDelay: time.Second,
+				Deadline:     500 * time.Millisecond,
+			}
+			_, ok := p.start(Log).next(nil)
+			assert.False(t, ok, "next attempt would be after the deadline")
+		},
+	)
+}
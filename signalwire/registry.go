@@ -0,0 +1,278 @@
+package signalwire
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+	"sync/atomic"
+)
+
+// callRegistryShards is the number of locks the registry spreads the calls over
+const callRegistryShards = 32
+
+// CallRegistryConfig sets up the call registry of a session
+type CallRegistryConfig struct {
+	// SoftLimit is the number of concurrent calls above which the registry
+	// warns and counts the new calls, they are still accepted (0 means no limit)
+	SoftLimit int
+	// OnSoftLimit is called when a new call goes above SoftLimit, with the calls active
+	OnSoftLimit func(active int)
+}
+
+// CallRegistryStats are the metrics of the call registry
+type CallRegistryStats struct {
+	// Active calls
+	Active int
+	// Peak of the active calls
+	Peak int
+	// Added and Removed calls since the session started
+	Added   uint64
+	Removed uint64
+	// AboveSoftLimit is the number of calls added above the soft limit
+	AboveSoftLimit uint64
+}
+
+// CallRegistry holds the calls of a session, by call ID and by tag
+type CallRegistry struct {
+	shards [callRegistryShards]callRegistryShard
+
+	mu     sync.Mutex
+	config CallRegistryConfig
+	stats  CallRegistryStats
+	log    LoggerWrapper
+}
+
+type callRegistryShard struct {
+	sync.RWMutex
+	m map[string]*CallSession
+}
+
+// NewCallRegistry returns an empty registry, cfg may be nil
+func NewCallRegistry(cfg *CallRegistryConfig) *CallRegistry {
+	r := new(CallRegistry)
+
+	if cfg != nil {
+		r.config = *cfg
+	}
+
+	return r
+}
+
+func (r *CallRegistry) shard(key string) *callRegistryShard {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+
+	return &r.shards[h.Sum32()%callRegistryShards]
+}
+
+// Set registers call under key, a call ID or a tag
+func (r *CallRegistry) Set(key string, call *CallSession) error {
+	if r == nil {
+		return errors.New("empty call registry")
+	}
+
+	if call == nil {
+		return errors.New("empty session object")
+	}
+
+	if len(key) == 0 {
+		return errors.New("empty call key")
+	}
+
+	s := r.shard(key)
+
+	s.Lock()
+
+	if s.m == nil {
+		s.m = make(map[string]*CallSession)
+	}
+
+	old, found := s.m[key]
+	s.m[key] = call
+
+	s.Unlock()
+
+	if found && old == call {
+		return nil
+	}
+
+	if found {
+		r.release(old)
+	}
+
+	if atomic.AddInt32(&call.registryKeys, 1) == 1 {
+		r.added()
+	}
+
+	return nil
+}
+
+// Get returns the call registered under key, nil if none
+func (r *CallRegistry) Get(key string) *CallSession {
+	if r == nil {
+		return nil
+	}
+
+	s := r.shard(key)
+
+	s.RLock()
+	call := s.m[key]
+	s.RUnlock()
+
+	return call
+}
+
+// Delete unregisters key
+func (r *CallRegistry) Delete(key string) {
+	if r == nil || len(key) == 0 {
+		return
+	}
+
+	s := r.shard(key)
+
+	s.Lock()
+
+	call, found := s.m[key]
+	delete(s.m, key)
+
+	s.Unlock()
+
+	if found {
+		r.release(call)
+	}
+}
+
+// Remove unregisters the call, by call ID and by tag
+func (r *CallRegistry) Remove(call *CallSession) {
+	if call == nil {
+		return
+	}
+
+	for _, key := range []string{call.GetCallID(), call.GetTagID()} {
+		if r.Get(key) == call {
+			r.Delete(key)
+		}
+	}
+}
+
+// All returns every call, once
+func (r *CallRegistry) All() []*CallSession {
+	if r == nil {
+		return nil
+	}
+
+	seen := make(map[*CallSession]bool)
+	calls := make([]*CallSession, 0)
+
+	for i := range r.shards {
+		s := &r.shards[i]
+
+		s.RLock()
+
+		for _, call := range s.m {
+			if !seen[call] {
+				seen[call] = true
+				calls = append(calls, call)
+			}
+		}
+
+		s.RUnlock()
+	}
+
+	return calls
+}
+
+// Len returns the number of active calls
+func (r *CallRegistry) Len() int {
+	return r.Stats().Active
+}
+
+// Stats returns the metrics of the registry
+func (r *CallRegistry) Stats() CallRegistryStats {
+	if r == nil {
+		return CallRegistryStats{}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.stats
+}
+
+// SetConfig changes the soft limit of the registry
+func (r *CallRegistry) SetConfig(cfg CallRegistryConfig) {
+	r.mu.Lock()
+	r.config = cfg
+	r.mu.Unlock()
+}
+
+func (r *CallRegistry) added() {
+	r.mu.Lock()
+
+	r.stats.Active++
+	r.stats.Added++
+
+	if r.stats.Active > r.stats.Peak {
+		r.stats.Peak = r.stats.Active
+	}
+
+	active := r.stats.Active
+	limit := r.config.SoftLimit
+	onSoftLimit := r.config.OnSoftLimit
+	above := limit > 0 && active > limit
+
+	if above {
+		r.stats.AboveSoftLimit++
+	}
+
+	r.mu.Unlock()
+
+	if !above {
+		return
+	}
+
+	if r.log != nil {
+		r.log.Warn("%d calls active, above the soft limit of %d\n", active, limit)
+	}
+
+	if onSoftLimit != nil {
+		onSoftLimit(active)
+	}
+}
+
+// release drops a key of the call, the call is removed with its last key
+func (r *CallRegistry) release(call *CallSession) {
+	if atomic.AddInt32(&call.registryKeys, -1) != 0 {
+		return
+	}
+
+	r.mu.Lock()
+
+	r.stats.Active--
+	r.stats.Removed++
+
+	r.mu.Unlock()
+}
+
+// CallStats returns the metrics of the calls of the session
+func (blade *BladeSession) CallStats() CallRegistryStats {
+	return blade.Calls.Stats()
+}
+
+// CallStats returns the metrics of the calls of the session
+func (client *ClientSession) CallStats() CallRegistryStats {
+	if client.Relay.Blade == nil {
+		return CallRegistryStats{}
+	}
+
+	return client.Relay.Blade.CallStats()
+}
+
+// CallStats returns the metrics of the calls of the consumer
+func (consumer *Consumer) CallStats() CallRegistryStats {
+	if consumer.Client == nil {
+		return CallRegistryStats{}
+	}
+
+	return consumer.Client.CallStats()
+}
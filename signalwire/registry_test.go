@@ -0,0 +1,89 @@
+package signalwire
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestCallRegistry(t *testing.T) {
+	t.Run(
+		"TagAndCallID",
+		func(t *testing.T) {
+			r := NewCallRegistry(nil)
+			call := &CallSession{TagID: "tag-1"}
+
+			assert.Nil(t, r.Set(call.TagID, call))
+			assert.Equal(t, call, r.Get("tag-1"))
+
+			call.CallID = "call-1"
+			assert.Nil(t, r.Set(call.CallID, call))
+			assert.Equal(t, call, r.Get("call-1"))
+			assert.Equal(t, call, r.Get("tag-1"), "the call must still be found by tag")
+			assert.Equal(t, 1, r.Len(), "two keys, one call")
+			assert.Len(t, r.All(), 1)
+
+			r.Remove(call)
+			assert.Nil(t, r.Get("call-1"))
+			assert.Nil(t, r.Get("tag-1"))
+			assert.Equal(t, CallRegistryStats{Peak: 1, Added: 1, Removed: 1}, r.Stats())
+		},
+	)
+	t.Run(
+		"SoftLimit",
+		func(t *testing.T) {
+			var above []int
+
+			r := NewCallRegistry(&CallRegistryConfig{
+				SoftLimit: 100,
+				OnSoftLimit: func(active int) {
+					above = append(above, active)
+				},
+			})
+
+			for i := 0; i < 102; i++ {
+				assert.Nil(t, r.Set(fmt.Sprintf("call-%d", i), new(CallSession)), "calls above the soft limit must be accepted")
+			}
+
+			assert.Equal(t, []int{101, 102}, above)
+			assert.Equal(t, uint64(2), r.Stats().AboveSoftLimit)
+			assert.Equal(t, 102, r.Len())
+		},
+	)
+	t.Run(
+		"Concurrent",
+		func(t *testing.T) {
+			r := NewCallRegistry(nil)
+
+			var wg sync.WaitGroup
+
+			for w := 0; w < 8; w++ {
+				wg.Add(1)
+
+				go func(w int) {
+					defer wg.Done()
+
+					for i := 0; i < 500; i++ {
+						call := &CallSession{TagID: fmt.Sprintf("tag-%d-%d", w, i), CallID: fmt.Sprintf("call-%d-%d", w, i)}
+						_ = r.Set(call.TagID, call)
+						_ = r.Set(call.CallID, call)
+
+						if i%2 == 0 {
+							r.Remove(call)
+						}
+					}
+				}(w)
+			}
+
+			wg.Wait()
+
+			stats := r.Stats()
+			assert.Equal(t, 2000, stats.Active)
+			assert.Equal(t, uint64(4000), stats.Added)
+			assert.Equal(t, uint64(2000), stats.Removed)
+			assert.Len(t, r.All(), 2000)
+		},
+	)
+}
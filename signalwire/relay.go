@@ -15,6 +15,7 @@ type RelaySession struct {
 type IRelay interface {
 	/*calling*/
 	RelayPhoneDial(ctx context.Context, call *CallSession, fromNumber string, toNumber string, timeout uint, payload **json.RawMessage) error
+	RelaySIPDial(ctx context.Context, call *CallSession, params *DeviceSIPParams, payload **json.RawMessage) error
 	RelayPhoneConnect(ctx context.Context, call *CallSession, fromNumber string, toNumber string, payload **json.RawMessage) error
 	RelayCallEnd(ctx context.Context, call *CallSession, payload **json.RawMessage) error
 	RelayStop(ctx context.Context) error
@@ -9,6 +9,30 @@ import (
 
 // RelayPhoneDial make outbound phone call
 func (relay *RelaySession) RelayPhoneDial(ctx context.Context, call *CallSession, fromNumber string, toNumber string, timeout uint, payload **json.RawMessage) error {
+	device := DeviceStruct{
+		Type: DevicePhone,
+		Params: DevicePhoneParams{
+			ToNumber:   toNumber,
+			FromNumber: fromNumber,
+		},
+	}
+
+	return relay.relayDial(ctx, call, device, timeout, payload)
+}
+
+// RelaySIPDial make outbound SIP call
+func (relay *RelaySession) RelaySIPDial(ctx context.Context, call *CallSession, params *DeviceSIPParams, payload **json.RawMessage) error {
+	if params == nil {
+		return errors.New("empty SIP params")
+	}
+
+	sip := *params
+
+	return relay.relayDial(ctx, call, DeviceStruct{Type: DeviceSIP, SIP: &sip}, params.Timeout, payload)
+}
+
+// relayDial begins an outbound call to device
+func (relay *RelaySession) relayDial(ctx context.Context, call *CallSession, device DeviceStruct, timeout uint, payload **json.RawMessage) error {
 	var err error
 
 	if relay == nil {
@@ -35,25 +59,28 @@ func (relay *RelaySession) RelayPhoneDial(ctx context.Context, call *CallSession
 	}
 
 	call.CallInit(ctx)
-	call.SetType(CallTypePhone)
+
+	if device.Type == DeviceSIP {
+		device.SIP.Timeout = call.Timeout
+
+		call.SetType(CallTypeSIP)
+		call.SetSIP(device.SIP)
+	} else {
+		device.Params.Timeout = call.Timeout
+
+		call.SetType(CallTypePhone)
+	}
 
 	v := ParamsBladeExecuteStruct{
 		Protocol: relay.Blade.Protocol,
 		Method:   "calling.begin",
 		Params: ParamsCallingBeginStruct{
-			Device: DeviceStruct{
-				Type: "phone",
-				Params: DevicePhoneParams{
-					ToNumber:   toNumber,
-					FromNumber: fromNumber,
-					Timeout:    call.Timeout,
-				},
-			},
-			Tag: call.TagID,
+			Device: device,
+			Tag:    call.TagID,
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -68,8 +95,8 @@ func (relay *RelaySession) RelayPhoneDial(ctx context.Context, call *CallSession
 	}
 
 	/* use tag as Call-ID*/
-	Log.Debug("call [%p] tag_id [%s]\n", call, call.TagID)
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("call [%p] tag_id [%s]\n", call, call.TagID)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -93,7 +120,7 @@ func (relay *RelaySession) RelayPhoneConnect(ctx context.Context, call *CallSess
 	}
 
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -121,7 +148,7 @@ func (relay *RelaySession) RelayPhoneConnect(ctx context.Context, call *CallSess
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -135,7 +162,7 @@ func (relay *RelaySession) RelayPhoneConnect(ctx context.Context, call *CallSess
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -159,7 +186,7 @@ func (relay *RelaySession) RelayConnect(ctx context.Context, call *CallSession,
 	}
 
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -175,7 +202,7 @@ func (relay *RelaySession) RelayConnect(ctx context.Context, call *CallSession,
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -189,7 +216,7 @@ func (relay *RelaySession) RelayConnect(ctx context.Context, call *CallSession,
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -201,7 +228,7 @@ func (relay *RelaySession) RelayConnect(ctx context.Context, call *CallSession,
 // RelayCallAnswer TODO DESCRIPTION
 func (relay *RelaySession) RelayCallAnswer(ctx context.Context, call *CallSession, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -215,7 +242,7 @@ func (relay *RelaySession) RelayCallAnswer(ctx context.Context, call *CallSessio
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -229,7 +256,7 @@ func (relay *RelaySession) RelayCallAnswer(ctx context.Context, call *CallSessio
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -240,8 +267,13 @@ func (relay *RelaySession) RelayCallAnswer(ctx context.Context, call *CallSessio
 
 // RelayCallEnd TODO DESCRIPTION
 func (relay *RelaySession) RelayCallEnd(ctx context.Context, call *CallSession, payload **json.RawMessage) error {
+	return relay.relayCallEnd(ctx, call, "hangup", payload)
+}
+
+// relayCallEnd ends the call with reason (hangup, cancel, busy or error)
+func (relay *RelaySession) relayCallEnd(ctx context.Context, call *CallSession, reason string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -250,13 +282,13 @@ func (relay *RelaySession) RelayCallEnd(ctx context.Context, call *CallSession,
 		Protocol: relay.Blade.Protocol,
 		Method:   "calling.end",
 		Params: ParamsCallEndStruct{
-			Reason: "hangup",
+			Reason: reason,
 			NodeID: call.NodeID,
 			CallID: call.CallID,
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -270,7 +302,7 @@ func (relay *RelaySession) RelayCallEnd(ctx context.Context, call *CallSession,
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -307,7 +339,7 @@ func (relay *RelaySession) RelayOnInboundAnswer(ctx context.Context) (*CallSessi
 // RelayPlayAudio TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayAudio(ctx context.Context, call *CallSession, ctrlID string, url string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -327,7 +359,7 @@ func (relay *RelaySession) RelayPlayAudio(ctx context.Context, call *CallSession
 // RelayPlayTTS TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayTTS(ctx context.Context, call *CallSession, ctrlID string, tts *TTSParamsInternal, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -349,7 +381,7 @@ func (relay *RelaySession) RelayPlayTTS(ctx context.Context, call *CallSession,
 // RelayPlayRingtone TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayRingtone(ctx context.Context, call *CallSession, ctrlID string, name string, duration float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -370,7 +402,7 @@ func (relay *RelaySession) RelayPlayRingtone(ctx context.Context, call *CallSess
 // RelayPlaySilence TODO DESCRIPTION
 func (relay *RelaySession) RelayPlaySilence(ctx context.Context, call *CallSession, ctrlID string, duration float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -390,7 +422,7 @@ func (relay *RelaySession) RelayPlaySilence(ctx context.Context, call *CallSessi
 // RelayPlay TODO DESCRIPTION
 func (relay *RelaySession) RelayPlay(ctx context.Context, call *CallSession, controlID string, play []PlayStruct, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -407,7 +439,7 @@ func (relay *RelaySession) RelayPlay(ctx context.Context, call *CallSession, con
 	}
 
 	/* prepare payload per Action in case user want to inspect it. We'll not send this, jsonrpc2 lib will do it's own marshaling on v */
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	call.Lock()
 
@@ -421,9 +453,9 @@ func (relay *RelaySession) RelayPlay(ctx context.Context, call *CallSession, con
 	select {
 	case call.CallPlayControlIDs <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
@@ -438,7 +470,7 @@ func (relay *RelaySession) RelayPlay(ctx context.Context, call *CallSession, con
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -450,7 +482,7 @@ func (relay *RelaySession) RelayPlay(ctx context.Context, call *CallSession, con
 // RelayPlayVolume TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayVolume(ctx context.Context, call *CallSession, ctrlID *string, vol float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -466,7 +498,7 @@ func (relay *RelaySession) RelayPlayVolume(ctx context.Context, call *CallSessio
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -480,7 +512,7 @@ func (relay *RelaySession) RelayPlayVolume(ctx context.Context, call *CallSessio
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -492,7 +524,7 @@ func (relay *RelaySession) RelayPlayVolume(ctx context.Context, call *CallSessio
 // RelayPlayResume TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayResume(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -507,7 +539,7 @@ func (relay *RelaySession) RelayPlayResume(ctx context.Context, call *CallSessio
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -521,7 +553,7 @@ func (relay *RelaySession) RelayPlayResume(ctx context.Context, call *CallSessio
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -533,7 +565,7 @@ func (relay *RelaySession) RelayPlayResume(ctx context.Context, call *CallSessio
 // RelayPlayPause TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayPause(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -548,7 +580,7 @@ func (relay *RelaySession) RelayPlayPause(ctx context.Context, call *CallSession
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -562,7 +594,7 @@ func (relay *RelaySession) RelayPlayPause(ctx context.Context, call *CallSession
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -574,7 +606,7 @@ func (relay *RelaySession) RelayPlayPause(ctx context.Context, call *CallSession
 // RelayPlayStop TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayStop(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -589,7 +621,7 @@ func (relay *RelaySession) RelayPlayStop(ctx context.Context, call *CallSession,
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -603,7 +635,7 @@ func (relay *RelaySession) RelayPlayStop(ctx context.Context, call *CallSession,
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -615,7 +647,7 @@ func (relay *RelaySession) RelayPlayStop(ctx context.Context, call *CallSession,
 // RelayRecordAudio TODO DESCRIPTION
 func (relay *RelaySession) RelayRecordAudio(ctx context.Context, call *CallSession, controlID string, rec *RecordParams, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -645,7 +677,7 @@ func (relay *RelaySession) RelayRecordAudio(ctx context.Context, call *CallSessi
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	call.Lock()
 
@@ -659,9 +691,9 @@ func (relay *RelaySession) RelayRecordAudio(ctx context.Context, call *CallSessi
 	select {
 	case call.CallRecordControlIDs <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
@@ -676,7 +708,7 @@ func (relay *RelaySession) RelayRecordAudio(ctx context.Context, call *CallSessi
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -688,7 +720,7 @@ func (relay *RelaySession) RelayRecordAudio(ctx context.Context, call *CallSessi
 // RelayRecordAudioStop TODO DESCRIPTION
 func (relay *RelaySession) RelayRecordAudioStop(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -703,7 +735,7 @@ func (relay *RelaySession) RelayRecordAudioStop(ctx context.Context, call *CallS
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -717,7 +749,7 @@ func (relay *RelaySession) RelayRecordAudioStop(ctx context.Context, call *CallS
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -729,7 +761,7 @@ func (relay *RelaySession) RelayRecordAudioStop(ctx context.Context, call *CallS
 // RelayDetectDigit TODO DESCRIPTION
 func (relay *RelaySession) RelayDetectDigit(ctx context.Context, call *CallSession, controlID string, digits string, timeout float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -751,9 +783,9 @@ func (relay *RelaySession) RelayDetectDigit(ctx context.Context, call *CallSessi
 	select {
 	case call.CallDetectDigitControlID <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	return relay.RelayDetect(ctx, call, controlID, detect, timeout, payload)
@@ -762,7 +794,7 @@ func (relay *RelaySession) RelayDetectDigit(ctx context.Context, call *CallSessi
 // RelayDetectFax TODO DESCRIPTION
 func (relay *RelaySession) RelayDetectFax(ctx context.Context, call *CallSession, controlID string, faxtone string, timeout float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -784,9 +816,9 @@ func (relay *RelaySession) RelayDetectFax(ctx context.Context, call *CallSession
 	select {
 	case call.CallDetectFaxControlID <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	return relay.RelayDetect(ctx, call, controlID, detect, timeout, payload)
@@ -795,7 +827,7 @@ func (relay *RelaySession) RelayDetectFax(ctx context.Context, call *CallSession
 // RelayDetectMachine TODO DESCRIPTION
 func (relay *RelaySession) RelayDetectMachine(ctx context.Context, call *CallSession, controlID string, det *DetectMachineParamsInternal, timeout float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -820,9 +852,9 @@ func (relay *RelaySession) RelayDetectMachine(ctx context.Context, call *CallSes
 	select {
 	case call.CallDetectMachineControlID <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	return relay.RelayDetect(ctx, call, controlID, detect, timeout, payload)
@@ -831,7 +863,7 @@ func (relay *RelaySession) RelayDetectMachine(ctx context.Context, call *CallSes
 // RelayDetect TODO DESCRIPTION
 func (relay *RelaySession) RelayDetect(ctx context.Context, call *CallSession, controlID string, detect DetectStruct, timeout float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -863,7 +895,7 @@ func (relay *RelaySession) RelayDetect(ctx context.Context, call *CallSession, c
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -877,7 +909,7 @@ func (relay *RelaySession) RelayDetect(ctx context.Context, call *CallSession, c
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -889,7 +921,7 @@ func (relay *RelaySession) RelayDetect(ctx context.Context, call *CallSession, c
 // RelayDetectStop TODO DESCRIPTION
 func (relay *RelaySession) RelayDetectStop(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -904,7 +936,7 @@ func (relay *RelaySession) RelayDetectStop(ctx context.Context, call *CallSessio
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -918,7 +950,7 @@ func (relay *RelaySession) RelayDetectStop(ctx context.Context, call *CallSessio
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -930,7 +962,7 @@ func (relay *RelaySession) RelayDetectStop(ctx context.Context, call *CallSessio
 // RelaySendFax TODO DESCRIPTION
 func (relay *RelaySession) RelaySendFax(ctx context.Context, call *CallSession, ctrlID *string, fax *FaxParamsInternal, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -948,14 +980,14 @@ func (relay *RelaySession) RelaySendFax(ctx context.Context, call *CallSession,
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	select {
 	case call.CallFaxControlID <- *ctrlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
@@ -970,7 +1002,7 @@ func (relay *RelaySession) RelaySendFax(ctx context.Context, call *CallSession,
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -982,7 +1014,7 @@ func (relay *RelaySession) RelaySendFax(ctx context.Context, call *CallSession,
 // RelayReceiveFax TODO DESCRIPTION
 func (relay *RelaySession) RelayReceiveFax(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -997,14 +1029,14 @@ func (relay *RelaySession) RelayReceiveFax(ctx context.Context, call *CallSessio
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	select {
 	case call.CallFaxControlID <- *ctrlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
@@ -1019,7 +1051,7 @@ func (relay *RelaySession) RelayReceiveFax(ctx context.Context, call *CallSessio
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -1031,7 +1063,7 @@ func (relay *RelaySession) RelayReceiveFax(ctx context.Context, call *CallSessio
 // RelaySendFaxStop TODO DESCRIPTION
 func (relay *RelaySession) RelaySendFaxStop(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1046,7 +1078,7 @@ func (relay *RelaySession) RelaySendFaxStop(ctx context.Context, call *CallSessi
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -1060,7 +1092,7 @@ func (relay *RelaySession) RelaySendFaxStop(ctx context.Context, call *CallSessi
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -1072,7 +1104,7 @@ func (relay *RelaySession) RelaySendFaxStop(ctx context.Context, call *CallSessi
 // RelayReceiveFaxStop TODO DESCRIPTION
 func (relay *RelaySession) RelayReceiveFaxStop(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1087,7 +1119,7 @@ func (relay *RelaySession) RelayReceiveFaxStop(ctx context.Context, call *CallSe
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -1101,7 +1133,7 @@ func (relay *RelaySession) RelayReceiveFaxStop(ctx context.Context, call *CallSe
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -1130,7 +1162,7 @@ func (relay *RelaySession) RelayTap(ctx context.Context, call *CallSession, cont
 	var srcDevice TapDevice
 
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return srcDevice, fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1147,7 +1179,7 @@ func (relay *RelaySession) RelayTap(ctx context.Context, call *CallSession, cont
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	call.Lock()
 
@@ -1161,9 +1193,9 @@ func (relay *RelaySession) RelayTap(ctx context.Context, call *CallSession, cont
 	select {
 	case call.CallTapControlIDs <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	var ReplyBladeExecuteDecode ReplyBladeExecuteTap
@@ -1178,7 +1210,7 @@ func (relay *RelaySession) RelayTap(ctx context.Context, call *CallSession, cont
 		return srcDevice, errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return srcDevice, errors.New(r.Result.Message)
@@ -1190,7 +1222,7 @@ func (relay *RelaySession) RelayTap(ctx context.Context, call *CallSession, cont
 // RelayTapStop TODO DESCRIPTION
 func (relay *RelaySession) RelayTapStop(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1205,7 +1237,7 @@ func (relay *RelaySession) RelayTapStop(ctx context.Context, call *CallSession,
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -1219,7 +1251,7 @@ func (relay *RelaySession) RelayTapStop(ctx context.Context, call *CallSession,
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -1231,7 +1263,7 @@ func (relay *RelaySession) RelayTapStop(ctx context.Context, call *CallSession,
 // RelaySendDigits TODO DESCRIPTION
 func (relay *RelaySession) RelaySendDigits(ctx context.Context, call *CallSession, controlID, digits string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1247,7 +1279,7 @@ func (relay *RelaySession) RelaySendDigits(ctx context.Context, call *CallSessio
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	call.Lock()
 
@@ -1260,9 +1292,9 @@ func (relay *RelaySession) RelaySendDigits(ctx context.Context, call *CallSessio
 	select {
 	case call.CallSendDigitsControlIDs <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
@@ -1277,7 +1309,7 @@ func (relay *RelaySession) RelaySendDigits(ctx context.Context, call *CallSessio
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -1289,7 +1321,7 @@ func (relay *RelaySession) RelaySendDigits(ctx context.Context, call *CallSessio
 // RelayPlayAndCollect TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayAndCollect(ctx context.Context, call *CallSession, controlID string, playlist *[]PlayStruct, collect *CollectStruct, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1306,7 +1338,7 @@ func (relay *RelaySession) RelayPlayAndCollect(ctx context.Context, call *CallSe
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	call.Lock()
 
@@ -1325,9 +1357,9 @@ func (relay *RelaySession) RelayPlayAndCollect(ctx context.Context, call *CallSe
 	select {
 	case call.CallPlayAndCollectControlID <- controlID:
 		// send the ctrlID to go routine that fires Consumer callbacks
-		Log.Debug("sent controlID to go routine\n")
+		relay.logger().Debug("sent controlID to go routine\n")
 	default:
-		Log.Debug("controlID was not sent to go routine\n")
+		relay.logger().Debug("controlID was not sent to go routine\n")
 	}
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
@@ -1342,7 +1374,7 @@ func (relay *RelaySession) RelayPlayAndCollect(ctx context.Context, call *CallSe
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return errors.New(r.Result.Message)
@@ -1354,7 +1386,7 @@ func (relay *RelaySession) RelayPlayAndCollect(ctx context.Context, call *CallSe
 // RelayPlayAndCollectVolume TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayAndCollectVolume(ctx context.Context, call *CallSession, ctrlID *string, vol float64, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1370,7 +1402,7 @@ func (relay *RelaySession) RelayPlayAndCollectVolume(ctx context.Context, call *
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -1384,7 +1416,7 @@ func (relay *RelaySession) RelayPlayAndCollectVolume(ctx context.Context, call *
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != "200" {
 		return errors.New(r.Result.Message)
@@ -1396,7 +1428,7 @@ func (relay *RelaySession) RelayPlayAndCollectVolume(ctx context.Context, call *
 // RelayPlayAndCollectStop TODO DESCRIPTION
 func (relay *RelaySession) RelayPlayAndCollectStop(ctx context.Context, call *CallSession, ctrlID *string, payload **json.RawMessage) error {
 	if len(call.CallID) == 0 {
-		Log.Error("no CallID\n")
+		relay.logger().Error("no CallID\n")
 
 		return fmt.Errorf("no CallID for call [%p]", call)
 	}
@@ -1411,7 +1443,7 @@ func (relay *RelaySession) RelayPlayAndCollectStop(ctx context.Context, call *Ca
 		},
 	}
 
-	savePayload(payload, v)
+	relay.savePayload(payload, v)
 
 	var ReplyBladeExecuteDecode ReplyBladeExecute
 
@@ -1425,7 +1457,7 @@ func (relay *RelaySession) RelayPlayAndCollectStop(ctx context.Context, call *Ca
 		return errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != "200" {
 		return errors.New(r.Result.Message)
@@ -1438,18 +1470,18 @@ type placeHolder struct {
 	Params json.RawMessage
 }
 
-func savePayload(payload **json.RawMessage, v interface{}) {
+func (relay *RelaySession) savePayload(payload **json.RawMessage, v interface{}) {
 	if payload != nil {
 		placeholder := new(placeHolder)
 
 		b, err := json.Marshal(v)
 		if err != nil {
-			Log.Error("payload: cannot marshal")
+			relay.logger().Error("payload: cannot marshal")
 		}
 
 		err = json.Unmarshal(b, placeholder)
 		if err != nil {
-			Log.Error("payload: cannot unmarshal to RawMessage")
+			relay.logger().Error("payload: cannot unmarshal to RawMessage")
 		}
 
 		*payload = &placeholder.Params
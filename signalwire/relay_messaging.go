@@ -43,7 +43,7 @@ func (relay *RelaySession) RelaySendMessage(ctx context.Context, msg *MsgSession
 		return "", errors.New("type assertion failed")
 	}
 
-	Log.Debug("reply ReplyBladeExecuteDecode: %v\n", r)
+	relay.logger().Debug("reply ReplyBladeExecuteDecode: %v\n", r)
 
 	if r.Result.Code != okCode {
 		return "", errors.New(r.Result.Message)
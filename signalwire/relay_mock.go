@@ -48,6 +48,20 @@ func (mr *MockIRelayMockRecorder) RelayPhoneDial(ctx, call, fromNumber, toNumber
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayPhoneDial", reflect.TypeOf((*MockIRelay)(nil).RelayPhoneDial), ctx, call, fromNumber, toNumber, timeout, payload)
 }
 
+// RelaySIPDial mocks base method
+func (m *MockIRelay) RelaySIPDial(ctx context.Context, call *CallSession, params *DeviceSIPParams, payload **json.RawMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelaySIPDial", ctx, call, params, payload)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RelaySIPDial indicates an expected call of RelaySIPDial
+func (mr *MockIRelayMockRecorder) RelaySIPDial(ctx, call, params, payload interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelaySIPDial", reflect.TypeOf((*MockIRelay)(nil).RelaySIPDial), ctx, call, params, payload)
+}
+
 // RelayPhoneConnect mocks base method
 func (m *MockIRelay) RelayPhoneConnect(ctx context.Context, call *CallSession, fromNumber, toNumber string, payload **json.RawMessage) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,633 @@
+// Package relaytest provides an in-process Blade/Relay server speaking the
+// JSON-RPC dialect used by the signalwire package, so that a whole Consumer
+// or ClientSession lifecycle can be exercised without network access.
+package relaytest
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	jsonrpc2 "github.com/sourcegraph/jsonrpc2"
+	ws "github.com/sourcegraph/jsonrpc2/websocket"
+)
+
+// Defaults used by a new Server
+const (
+	DefaultPingInterval = 5 * time.Second
+	DefaultProject      = "relaytest-project"
+	okCode              = "200"
+	eventsCalling       = "queuing.relay.events"
+	eventsMessaging     = "queuing.relay.messaging"
+	notificationsChan   = "notifications"
+)
+
+// HandlerFunc scripts the reply to a request. For "blade.execute" requests
+// the handler is looked up by the inner Relay method (eg: "calling.play") and
+// the returned value becomes the "result" member of the execute reply.
+// For the other Blade methods (eg: "blade.connect") the returned value is the
+// whole JSON-RPC result.
+type HandlerFunc func(params json.RawMessage) (interface{}, *jsonrpc2.Error)
+
+// Request is a request received by the Server
+type Request struct {
+	Method string
+	Params json.RawMessage
+	Time   time.Time
+}
+
+// Server is a local WebSocket (TLS) server that behaves like the Relay
+// platform for the Blade handshake and lets tests script the rest.
+type Server struct {
+	Protocol     string
+	NodeID       string
+	Project      string
+	Token        string
+	PingInterval time.Duration
+	// RestoreSessions makes blade.connect restore a session the server
+	// issued before when the client presents its session ID again.
+	RestoreSessions bool
+	// JWTLifetime is the expiry announced to the sessions authenticated
+	// with a JWT, on connect and on reauthenticate (0 means no expiry).
+	JWTLifetime time.Duration
+
+	srv      *httptest.Server
+	upgrader websocket.Upgrader
+	handlers map[string]HandlerFunc
+	conns    map[*jsonrpc2.Conn]*websocket.Conn
+	received []Request
+	sessions map[string]bool
+	header   http.Header
+	reject   bool
+	// connectFails blade.connect are answered with connectErr
+	connectFails int
+	connectErr   *jsonrpc2.Error
+	notify       chan struct{}
+	done         chan struct{}
+	closeOnce    sync.Once
+	sync.Mutex
+}
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down.
+func NewServer() *Server {
+	s := NewUnstartedServer()
+	s.Start()
+
+	return s
+}
+
+// NewUnstartedServer returns a new Server but doesn't start it, so that the
+// caller can script handlers and change settings before clients connect.
+func NewUnstartedServer() *Server {
+	s := &Server{
+		Protocol:     "signalwire_relaytest_" + newID(),
+		NodeID:       newID(),
+		Project:      DefaultProject,
+		PingInterval: DefaultPingInterval,
+		handlers:     make(map[string]HandlerFunc),
+		conns:        make(map[*jsonrpc2.Conn]*websocket.Conn),
+		sessions:     make(map[string]bool),
+		notify:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	s.srv = httptest.NewUnstartedServer(http.HandlerFunc(s.serveWS))
+
+	return s
+}
+
+// Start starts the TLS listener
+func (s *Server) Start() {
+	s.srv.StartTLS()
+}
+
+// Close disconnects all clients and shuts down the server, it may be called more than once
+func (s *Server) Close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.DropConnections()
+		s.srv.Close()
+	})
+}
+
+// Host returns the "host:port" address clients should connect to
+func (s *Server) Host() string {
+	return strings.TrimPrefix(s.srv.URL, "https://")
+}
+
+// URL returns the wss:// URL of the server
+func (s *Server) URL() string {
+	return "wss://" + s.Host() + "/"
+}
+
+// Certificate returns the self-signed certificate used by the server
+func (s *Server) Certificate() *x509.Certificate {
+	return s.srv.Certificate()
+}
+
+// CertPool returns a pool holding the server certificate, to be used as
+// RootCAs by clients.
+func (s *Server) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(s.Certificate())
+
+	return pool
+}
+
+// Handle registers the handler for the given method, replacing the default
+func (s *Server) Handle(method string, h HandlerFunc) {
+	s.Lock()
+	s.handlers[method] = h
+	s.Unlock()
+}
+
+// Reply scripts a fixed Relay code/message reply for a "blade.execute" method
+func (s *Server) Reply(method, code, message string) {
+	s.Handle(method, func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+		return map[string]string{"code": code, "message": message}, nil
+	})
+}
+
+// FailConnect makes the next n "blade.connect" fail with the given error
+// (eg: -32000 "Timeout"), the ones after are handled as usual
+func (s *Server) FailConnect(n int, code int64, message string) {
+	s.Lock()
+	s.connectFails = n
+	s.connectErr = &jsonrpc2.Error{Code: code, Message: message}
+	s.Unlock()
+}
+
+// Received returns a copy of all requests received so far
+func (s *Server) Received() []Request {
+	s.Lock()
+	r := make([]Request, len(s.received))
+	copy(r, s.received)
+	s.Unlock()
+
+	return r
+}
+
+// WaitFor blocks until a request for method has been received (or had
+// already been received) and returns the latest one.
+func (s *Server) WaitFor(method string, timeout time.Duration) (Request, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for {
+		s.Lock()
+
+		for i := len(s.received) - 1; i >= 0; i-- {
+			if s.received[i].Method == method {
+				r := s.received[i]
+				s.Unlock()
+
+				return r, nil
+			}
+		}
+
+		notify := s.notify
+
+		s.Unlock()
+
+		select {
+		case <-notify:
+		case <-timer.C:
+			return Request{}, fmt.Errorf("timeout waiting for %s", method)
+		}
+	}
+}
+
+// Connections returns the number of connected clients
+func (s *Server) Connections() int {
+	s.Lock()
+	n := len(s.conns)
+	s.Unlock()
+
+	return n
+}
+
+// UpgradeHeader returns the HTTP headers of the last WebSocket upgrade request
+func (s *Server) UpgradeHeader() http.Header {
+	s.Lock()
+	h := s.header
+	s.Unlock()
+
+	return h
+}
+
+// WaitConnections blocks until n clients are connected
+func (s *Server) WaitConnections(n int, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	for s.Connections() != n {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timeout waiting for %d connections", n)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	return nil
+}
+
+// Broadcast sends a raw "blade.broadcast" to every connected client
+func (s *Server) Broadcast(event string, params interface{}) error {
+	return s.notifyAll("blade.broadcast", map[string]interface{}{
+		"broadcaster_nodeid": s.NodeID,
+		"protocol":           s.Protocol,
+		"channel":            notificationsChan,
+		"event":              event,
+		"params":             params,
+	})
+}
+
+// SendEvent broadcasts a Relay calling or messaging event (eg:
+// "calling.call.state") wrapping params the same way the platform does.
+// Tasking events have no envelope: use Broadcast("queuing.relay.tasks", ...).
+func (s *Server) SendEvent(eventType string, params interface{}) error {
+	event := eventsCalling
+
+	if strings.HasPrefix(eventType, "messaging.") {
+		event = eventsMessaging
+	}
+
+	return s.Broadcast(event, map[string]interface{}{
+		"event_type":    eventType,
+		"event_channel": s.Protocol,
+		"timestamp":     float64(time.Now().UnixNano()) / 1e9,
+		"project_id":    s.Project,
+		"space_id":      s.NodeID,
+		"params":        params,
+	})
+}
+
+// Netcast sends a "blade.netcast" command to every connected client
+func (s *Server) Netcast(command, protocol string) error {
+	return s.notifyAll("blade.netcast", map[string]interface{}{
+		"netcaster_nodeid": s.NodeID,
+		"command":          command,
+		"params": map[string]string{
+			"protocol": protocol,
+		},
+	})
+}
+
+// Disconnect sends a server initiated "blade.disconnect" to every client
+func (s *Server) Disconnect() error {
+	return s.notifyAll("blade.disconnect", struct{}{})
+}
+
+// DropConnections closes all WebSocket connections without any Blade
+// goodbye, as a network failure would.
+func (s *Server) DropConnections() {
+	s.Lock()
+
+	for c, wsc := range s.conns {
+		wsc.Close()
+		delete(s.conns, c)
+	}
+
+	s.Unlock()
+}
+
+// RejectConnections drops the connected clients and refuses new ones while
+// reject is true, as an unreachable platform would.
+func (s *Server) RejectConnections(reject bool) {
+	s.Lock()
+	s.reject = reject
+	s.Unlock()
+
+	if reject {
+		s.DropConnections()
+	}
+}
+
+func (s *Server) notifyAll(method string, params interface{}) error {
+	s.Lock()
+
+	conns := make([]*jsonrpc2.Conn, 0, len(s.conns))
+	for c := range s.conns {
+		conns = append(conns, c)
+	}
+
+	s.Unlock()
+
+	if len(conns) == 0 {
+		return errors.New("no clients connected")
+	}
+
+	for _, c := range conns {
+		if err := c.Notify(context.Background(), method, params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	s.Lock()
+	s.header = r.Header.Clone()
+	reject := s.reject
+	s.Unlock()
+
+	if reject {
+		http.Error(w, "relaytest: connections rejected", http.StatusServiceUnavailable)
+
+		return
+	}
+
+	wsc, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	conn := jsonrpc2.NewConn(context.Background(), ws.NewObjectStream(wsc), jsonrpc2.HandlerWithError(s.handle))
+
+	s.Lock()
+	s.conns[conn] = wsc
+	s.Unlock()
+
+	go s.ping(conn, wsc)
+
+	<-conn.DisconnectNotify()
+
+	s.Lock()
+	delete(s.conns, conn)
+	s.Unlock()
+}
+
+func (s *Server) ping(conn *jsonrpc2.Conn, wsc *websocket.Conn) {
+	if s.PingInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := wsc.WriteControl(websocket.PingMessage, nil, time.Now().Add(time.Second)); err != nil {
+				return
+			}
+		case <-conn.DisconnectNotify():
+			return
+		case <-s.done:
+			return
+		}
+	}
+}
+
+func (s *Server) record(method string, params *json.RawMessage) json.RawMessage {
+	var raw json.RawMessage
+
+	if params != nil {
+		raw = append(raw, *params...)
+	}
+
+	s.Lock()
+
+	s.received = append(s.received, Request{Method: method, Params: raw, Time: time.Now()})
+	close(s.notify)
+	s.notify = make(chan struct{})
+
+	s.Unlock()
+
+	return raw
+}
+
+func (s *Server) handler(method string) HandlerFunc {
+	s.Lock()
+	h := s.handlers[method]
+	s.Unlock()
+
+	return h
+}
+
+type executeParams struct {
+	Protocol string          `json:"protocol"`
+	Method   string          `json:"method"`
+	Params   json.RawMessage `json:"params"`
+}
+
+func (s *Server) handle(_ context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (interface{}, error) {
+	if req.Method != "blade.execute" {
+		params := s.record(req.Method, req.Params)
+
+		if h := s.handler(req.Method); h != nil {
+			return reply(h(params))
+		}
+
+		switch req.Method {
+		case "blade.connect":
+			return s.connect(params)
+		case "blade.subscription":
+			return s.subscription(params)
+		case "blade.reauthenticate":
+			return s.reauthenticate(params)
+		case "blade.disconnect":
+			return struct{}{}, nil
+		}
+
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeMethodNotFound, Message: "unknown method " + req.Method}
+	}
+
+	var exec executeParams
+
+	if req.Params == nil || json.Unmarshal(*req.Params, &exec) != nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: "invalid blade.execute params"}
+	}
+
+	s.record(exec.Method, &exec.Params)
+
+	var (
+		result interface{}
+		jerr   *jsonrpc2.Error
+	)
+
+	if h := s.handler(exec.Method); h != nil {
+		result, jerr = h(exec.Params)
+	} else {
+		switch exec.Method {
+		case "setup":
+			result = map[string]string{"protocol": s.Protocol}
+
+			go func() {
+				// the platform announces the protocol asynchronously
+				_ = conn.Notify(context.Background(), "blade.netcast", map[string]interface{}{
+					"netcaster_nodeid": s.NodeID,
+					"command":          "protocol.add",
+					"params":           map[string]string{"protocol": s.Protocol},
+				})
+			}()
+		case "messaging.send":
+			result = map[string]string{"code": okCode, "message": "Message accepted", "message_id": newID()}
+		default:
+			result = map[string]string{"code": okCode, "message": "OK"}
+		}
+	}
+
+	if jerr != nil {
+		return nil, jerr
+	}
+
+	return map[string]interface{}{
+		"requester_nodeid": s.NodeID,
+		"responder_nodeid": s.NodeID,
+		"result":           result,
+	}, nil
+}
+
+type authentication struct {
+	Project  string `json:"project"`
+	Token    string `json:"token"`
+	JWTToken string `json:"jwt_token"`
+}
+
+func (s *Server) connect(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		SessionID      string         `json:"session_id"`
+		Authentication authentication `json:"authentication"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+	}
+
+	s.Lock()
+
+	if s.connectFails > 0 {
+		s.connectFails--
+		err := s.connectErr
+		s.Unlock()
+
+		return nil, err
+	}
+
+	s.Unlock()
+
+	auth := p.Authentication
+
+	if !s.authenticated(auth) {
+		return nil, &jsonrpc2.Error{Code: -32002, Message: "Authentication failed"}
+	}
+
+	sessionID := newID()
+
+	s.Lock()
+
+	restored := s.RestoreSessions && s.sessions[p.SessionID]
+	if restored {
+		sessionID = p.SessionID
+	}
+
+	s.sessions[sessionID] = true
+
+	s.Unlock()
+
+	return map[string]interface{}{
+		"session_restored":      restored,
+		"sessionid":             sessionID,
+		"node_id":               s.NodeID,
+		"master_nodeid":         s.NodeID,
+		"authorization":         s.authorization(auth),
+		"protocols_uncertified": []string{"signalwire"},
+	}, nil
+}
+
+func (s *Server) reauthenticate(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		SessionID      string         `json:"sessionid"`
+		Authentication authentication `json:"authentication"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+	}
+
+	if len(p.Authentication.JWTToken) == 0 || !s.authenticated(p.Authentication) {
+		return nil, &jsonrpc2.Error{Code: -32002, Message: "Authentication failed"}
+	}
+
+	return map[string]interface{}{
+		"authorization": s.authorization(p.Authentication),
+	}, nil
+}
+
+// authenticated accepts any JWT, and the project token if Token is set
+func (s *Server) authenticated(auth authentication) bool {
+	if len(auth.Project) == 0 {
+		return false
+	}
+
+	if len(auth.JWTToken) > 0 {
+		return true
+	}
+
+	return len(auth.Token) > 0 && (len(s.Token) == 0 || (auth.Project == s.Project && auth.Token == s.Token))
+}
+
+func (s *Server) authorization(auth authentication) map[string]interface{} {
+	var expiresAt interface{}
+
+	if len(auth.JWTToken) > 0 && s.JWTLifetime > 0 {
+		expiresAt = float64(time.Now().Add(s.JWTLifetime).UnixNano()) / float64(time.Second)
+	}
+
+	return map[string]interface{}{
+		"project":    auth.Project,
+		"expires_at": expiresAt,
+		"scopes":     []string{"calling", "messaging", "tasking"},
+		"signature":  newID(),
+	}
+}
+
+func (s *Server) subscription(params json.RawMessage) (interface{}, error) {
+	var p struct {
+		Command  string   `json:"command"`
+		Protocol string   `json:"protocol"`
+		Channels []string `json:"channels"`
+	}
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams, Message: err.Error()}
+	}
+
+	return map[string]interface{}{
+		"protocol":           p.Protocol,
+		"command":            p.Command,
+		"subscribe_channels": p.Channels,
+	}, nil
+}
+
+func reply(result interface{}, jerr *jsonrpc2.Error) (interface{}, error) {
+	if jerr != nil {
+		return nil, jerr
+	}
+
+	return result, nil
+}
+
+func newID() string {
+	u := make([]byte, 16)
+
+	if _, err := rand.Read(u); err != nil {
+		panic(err)
+	}
+
+	u[6] = (u[6] | 0x40) & 0x4F
+	u[8] = (u[8] | 0x80) & 0xBF
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:])
+}
@@ -0,0 +1,2325 @@
+package relaytest_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/signalwire/signalwire-golang/signalwire"
+	"github.com/signalwire/signalwire-golang/signalwire/relaytest"
+	jsonrpc2 "github.com/sourcegraph/jsonrpc2"
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestServer(t *testing.T) {
+	t.Run(
+		"BladeHandshake",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			blade := signalwire.NewBladeSession()
+			blade.I = blade
+			blade.Transport = &signalwire.TransportConfig{RootCAs: srv.CertPool()}
+
+			err := blade.BladeInit(ctx, srv.Host())
+			if err != nil {
+				t.Fatalf("BladeInit: %v", err)
+			}
+
+			err = blade.BladeConnect(ctx, &signalwire.BladeAuth{ProjectID: "project", TokenID: "token"})
+			assert.Nil(t, err, "should not be an error from BladeConnect")
+			assert.Equal(t, signalwire.BladeConnected, blade.SessionState)
+
+			netcast := make(chan string, 1)
+
+			go func() {
+				netcast <- <-blade.Netcast
+			}()
+
+			err = blade.BladeSetup(ctx)
+			assert.Nil(t, err, "should not be an error from BladeSetup")
+			assert.Equal(t, srv.Protocol, blade.Protocol, "protocol must come from setup")
+
+			select {
+			case p := <-netcast:
+				assert.Equal(t, srv.Protocol, p, "netcast must announce the protocol")
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no protocol.add netcast")
+			}
+
+			err = blade.BladeAddSubscription(ctx, []string{"notifications"})
+			assert.Nil(t, err, "should not be an error from BladeAddSubscription")
+
+			err = blade.BladeSignalwireReceive(ctx, []string{"test"})
+			assert.Nil(t, err, "should not be an error from BladeSignalwireReceive")
+
+			req, err := srv.WaitFor("signalwire.receive", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"contexts":["test"]}`, string(req.Params))
+
+			blade.BladeSetupInbound(ctx)
+
+			err = srv.SendEvent("calling.call.receive", map[string]interface{}{
+				"call_state": "created",
+				"context":    "test",
+				"device": map[string]interface{}{
+					"type":   "phone",
+					"params": map[string]string{"from_number": "+15551230001", "to_number": "+15551230002"},
+				},
+				"direction": "inbound",
+				"call_id":   "d42a9c36-9a8f-4a4e-93c5-5b0f3b0b6c1f",
+				"node_id":   "e0a61dc4-9ab5-4cb6-9b42-4c3c2a7e6d3b",
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			call, err := blade.BladeWaitInboundCall(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, "+15551230001", call.GetFrom())
+
+			srv.Handle("calling.answer", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+				return map[string]string{"code": "404", "message": "call gone"}, nil
+			})
+
+			relay := signalwire.RelaySession{Blade: blade}
+			err = relay.RelayCallAnswer(ctx, call, nil)
+			assert.EqualError(t, err, "call gone", "scripted reply must surface")
+
+			err = blade.BladeDisconnect(ctx)
+			assert.Nil(t, err, "should not be an error from BladeDisconnect")
+			assert.Equal(t, signalwire.BladeShutdown, blade.SessionState)
+		},
+	)
+	t.Run(
+		"CloseTwice",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.Close()
+		},
+	)
+	t.Run(
+		"ConsumerRun",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.Token = "token"
+
+			consumer := newConsumer(srv)
+			consumer.Transport.HandshakeTimeout = time.Second
+			consumer.Transport.Header = http.Header{"User-Agent": []string{"relaytest"}}
+
+			done := runConsumer(t, consumer)
+
+			req, err := srv.WaitFor("signalwire.receive", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"contexts":["test"]}`, string(req.Params))
+			assert.Equal(t, "relaytest", srv.UpgradeHeader().Get("User-Agent"), "custom headers must be sent on upgrade")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"SessionRestored",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.RestoreSessions = true
+
+			consumer := newConsumer(srv)
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			srv.DropConnections()
+			waitCount(t, srv, "blade.connect", 2)
+
+			assert.Nil(t, srv.WaitConnections(1, 2*time.Second))
+			assert.Equal(t, 1, count(srv, "setup"), "restored session must not be set up again")
+			assert.NotEqual(t, signalwire.Ended, call.GetState(), "call must survive the reconnect")
+			assert.Nil(t, call.GetError())
+
+			err := srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+			assert.True(t, call.WaitForEnded(2), "events must reach the restored call")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"SessionNotRestored",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			srv.DropConnections()
+
+			assert.True(t, call.WaitForEnded(5), "call must be ended when the session is lost")
+			assert.Equal(t, signalwire.ErrSessionLost, call.GetError())
+			assert.False(t, call.Active())
+
+			waitCount(t, srv, "signalwire.receive", 2)
+			assert.Equal(t, 2, count(srv, "setup"), "new session must be set up")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"ReconnectGiveUp",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+
+			consumer := newConsumer(srv)
+			consumer.OnIncomingCall = func(*signalwire.Consumer, *signalwire.CallObj) {}
+
+			var tries int
+
+			gaveUp := make(chan int, 1)
+			consumer.ReconnectPolicy = &signalwire.ReconnectPolicy{
+				InitialDelay: 10 * time.Millisecond,
+				Multiplier:   2,
+				MaxAttempts:  3,
+				OnAttempt: func(int, time.Duration, error) {
+					tries++
+				},
+				OnGiveUp: func(attempts int, _ error) {
+					gaveUp <- attempts
+				},
+			}
+
+			done := runConsumer(t, consumer)
+
+			srv.Close()
+
+			select {
+			case n := <-gaveUp:
+				assert.Equal(t, 3, n)
+				assert.Equal(t, 3, tries)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("policy did not give up")
+			}
+
+			select {
+			case err := <-done:
+				assert.Equal(t, signalwire.ErrReconnectGaveUp, err)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("Run did not return after giving up")
+			}
+		},
+	)
+	t.Run(
+		"ConnectTimeoutRetry",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.FailConnect(2, -32000, "Timeout")
+
+			consumer := newConsumer(srv)
+			consumer.OnIncomingCall = func(*signalwire.Consumer, *signalwire.CallObj) {}
+
+			var tries int32
+
+			consumer.ReconnectPolicy = &signalwire.ReconnectPolicy{
+				InitialDelay: 10 * time.Millisecond,
+				MaxAttempts:  3,
+				OnAttempt: func(int, time.Duration, error) {
+					atomic.AddInt32(&tries, 1)
+				},
+			}
+
+			done := runConsumer(t, consumer)
+
+			assert.Equal(t, 3, count(srv, "blade.connect"), "two failed connects then a good one")
+			assert.Equal(t, int32(2), atomic.LoadInt32(&tries))
+			assert.Equal(t, 1, count(srv, "setup"))
+			assert.Nil(t, srv.WaitConnections(1, 2*time.Second), "the links of the failed attempts must be closed")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"ConnectDeadline",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.FailConnect(1000, -32000, "Timeout")
+
+			consumer := newConsumer(srv)
+			consumer.OnIncomingCall = func(*signalwire.Consumer, *signalwire.CallObj) {}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			done := make(chan error, 1)
+
+			go func() {
+				done <- consumer.RunContext(ctx)
+			}()
+
+			select {
+			case err := <-done:
+				assert.Equal(t, context.DeadlineExceeded, err)
+			case <-time.After(5 * time.Second):
+				t.Fatalf("RunContext did not return on its deadline")
+			}
+
+			assert.Nil(t, srv.WaitConnections(0, 2*time.Second), "the Blade link must be closed")
+
+			n := count(srv, "blade.connect")
+
+			time.Sleep(100 * time.Millisecond)
+			assert.Equal(t, n, count(srv, "blade.connect"), "no connect once RunContext returned")
+		},
+	)
+	t.Run(
+		"OutboundQueue",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.RestoreSessions = true
+
+			consumer := newConsumer(srv)
+			consumer.OutboundQueue = &signalwire.OutboundQueue{Size: 3, MaxAge: 5 * time.Second}
+
+			done := runConsumer(t, consumer)
+			relay := consumer.Client.Messaging.Relay
+
+			srv.RejectConnections(true)
+			waitLinkDown(t, consumer)
+
+			errs := make(chan error, 4)
+
+			for _, body := range []string{"1", "2", "3", "4"} {
+				go func(body string) {
+					_, err := relay.RelaySendMessage(consumer.Client.Ctx, new(signalwire.MsgSession), "+15551230001", "+15551230002", "test", body)
+					errs <- err
+				}(body)
+
+				// issue them in order
+				time.Sleep(20 * time.Millisecond)
+			}
+
+			select {
+			case err := <-errs:
+				assert.Equal(t, signalwire.ErrOutboundQueueFull, err, "4th command must not fit")
+			case <-time.After(time.Second):
+				t.Fatalf("4th command not rejected")
+			}
+
+			srv.RejectConnections(false)
+
+			for i := 0; i < 3; i++ {
+				select {
+				case err := <-errs:
+					assert.Nil(t, err, "queued command must be replayed")
+				case <-time.After(5 * time.Second):
+					t.Fatalf("queued command not replayed")
+				}
+			}
+
+			var bodies []string
+
+			for _, r := range srv.Received() {
+				if r.Method == "messaging.send" {
+					var p struct {
+						Body string `json:"body"`
+					}
+
+					assert.Nil(t, json.Unmarshal(r.Params, &p))
+					bodies = append(bodies, p.Body)
+				}
+			}
+
+			assert.Equal(t, []string{"1", "2", "3"}, bodies, "commands must be replayed in order")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"OutboundQueueTimeout",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			consumer.OutboundQueue = &signalwire.OutboundQueue{MaxAge: 50 * time.Millisecond}
+
+			done := runConsumer(t, consumer)
+			relay := consumer.Client.Messaging.Relay
+
+			srv.RejectConnections(true)
+			waitLinkDown(t, consumer)
+
+			_, err := relay.RelaySendMessage(consumer.Client.Ctx, new(signalwire.MsgSession), "+15551230001", "+15551230002", "test", "late")
+			assert.Equal(t, signalwire.ErrOutboundQueueTimeout, err)
+
+			srv.RejectConnections(false)
+			waitCount(t, srv, "signalwire.receive", 2)
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"TwoConsumers",
+		func(t *testing.T) {
+			srvA := relaytest.NewServer()
+			defer srvA.Close()
+
+			srvB := relaytest.NewServer()
+			defer srvB.Close()
+
+			logA := new(captureLogger)
+			logB := new(captureLogger)
+
+			callsA := make(chan *signalwire.CallObj, 1)
+			callsB := make(chan *signalwire.CallObj, 1)
+
+			consumerA := newConsumer(srvA)
+			consumerA.Log = logA
+			consumerA.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				callsA <- call
+			}
+
+			consumerB := newConsumer(srvB)
+			consumerB.Log = logB
+			consumerB.ConnectTimeout = 3 * time.Second
+			consumerB.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				callsB <- call
+			}
+
+			doneA := runConsumer(t, consumerA)
+			doneB := runConsumer(t, consumerB)
+
+			receiveCall(t, srvA, callsA)
+
+			select {
+			case <-callsB:
+				t.Fatalf("call for A reached B")
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			stopConsumer(t, consumerA, doneA)
+
+			receiveCall(t, srvB, callsB)
+
+			assert.True(t, logA.contains(srvA.Host()), "A must log to its own logger")
+			assert.False(t, logA.contains(srvB.Host()), "B must not log to A's logger")
+			assert.True(t, logB.contains(srvB.Host()), "B must log to its own logger")
+			assert.False(t, logB.contains(srvA.Host()), "A must not log to B's logger")
+
+			stopConsumer(t, consumerB, doneB)
+		},
+	)
+	t.Run(
+		"ConnectionState",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.RestoreSessions = true
+			srv.PingInterval = 20 * time.Millisecond
+
+			var (
+				mu      sync.Mutex
+				changes int
+			)
+
+			consumer := newConsumer(srv)
+			consumer.ConnectionStates = make(chan signalwire.ConnectionStateChange, 32)
+			consumer.OnConnectionStateChange = func(_ *signalwire.Consumer, change signalwire.ConnectionStateChange) {
+				mu.Lock()
+				changes++
+				mu.Unlock()
+			}
+
+			done := runConsumer(t, consumer)
+
+			assert.Equal(t, []signalwire.SessionState{
+				signalwire.BladeConnecting,
+				signalwire.BladeConnected,
+				signalwire.BladeSetup,
+				signalwire.BladeSubscribed,
+				signalwire.BladeRunning,
+			}, waitStates(t, consumer.ConnectionStates, signalwire.BladeRunning))
+
+			health := consumer.LinkHealth()
+			assert.Equal(t, signalwire.BladeRunning, health.State)
+			assert.True(t, health.ExecuteLatency > 0, "blade.execute round trip must be measured")
+			assert.Equal(t, 0, health.Reconnects)
+
+			srv.DropConnections()
+
+			assert.Equal(t, []signalwire.SessionState{
+				signalwire.BladeConnecting,
+				signalwire.BladeConnected,
+				signalwire.BladeRunning,
+			}, waitStates(t, consumer.ConnectionStates, signalwire.BladeRunning))
+
+			time.Sleep(100 * time.Millisecond)
+
+			health = consumer.LinkHealth()
+			assert.Equal(t, 1, health.Reconnects)
+			assert.False(t, health.LastPing.IsZero(), "server pings must be recorded")
+			assert.True(t, time.Since(health.LastPing) < time.Second)
+
+			stopConsumer(t, consumer, done)
+
+			assert.Equal(t, signalwire.BladeShutdown, consumer.LinkHealth().State)
+
+			mu.Lock()
+			assert.Equal(t, 8+len(consumer.ConnectionStates), changes, "callback and channel must see the same transitions")
+			mu.Unlock()
+		},
+	)
+	t.Run(
+		"CredentialsRefresh",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.RestoreSessions = true
+			srv.JWTLifetime = 300 * time.Millisecond
+
+			var (
+				mu     sync.Mutex
+				issued int
+			)
+
+			consumer := newConsumer(srv)
+			consumer.Credentials = signalwire.CredentialsFunc(func(context.Context) (signalwire.Credentials, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				issued++
+
+				return signalwire.Credentials{Project: srv.Project, JWT: fmt.Sprintf("jwt-%d", issued)}, nil
+			})
+
+			done := runConsumer(t, consumer)
+
+			req, err := srv.WaitFor("blade.connect", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"project":"`+srv.Project+`","jwt_token":"jwt-1"}`, authentication(t, req))
+
+			waitCount(t, srv, "blade.reauthenticate", 2)
+
+			req, err = srv.WaitFor("blade.reauthenticate", time.Second)
+			assert.Nil(t, err)
+			assert.NotContains(t, authentication(t, req), `"jwt-1"`, "the running session must get a fresh JWT")
+
+			expiresAt := consumer.Client.Relay.Blade.Authorization().ExpiresAt
+			assert.True(t, expiresAt.After(time.Now()), "expiry must be tracked")
+
+			reauths := count(srv, "blade.reauthenticate")
+
+			srv.DropConnections()
+			waitCount(t, srv, "blade.connect", 2)
+
+			req, err = srv.WaitFor("blade.connect", time.Second)
+			assert.Nil(t, err)
+			assert.NotContains(t, authentication(t, req), `"jwt-1"`, "reconnect must get a fresh JWT")
+
+			waitCount(t, srv, "blade.reauthenticate", reauths+1)
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"ScopeError",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.Handle("signalwire.receive", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+				return map[string]string{"code": "403", "message": "context not authorized"}, nil
+			})
+
+			consumer := newConsumer(srv)
+			consumer.Credentials = signalwire.CredentialsFunc(func(context.Context) (signalwire.Credentials, error) {
+				return signalwire.Credentials{Project: srv.Project, JWT: "jwt"}, nil
+			})
+
+			err := consumer.Run()
+
+			scopeErr, ok := err.(*signalwire.ScopeError)
+			if assert.True(t, ok, "Run must return a ScopeError, got %v", err) {
+				assert.Equal(t, []string{"test"}, scopeErr.Contexts)
+				assert.Equal(t, "403", scopeErr.Code)
+			}
+		},
+	)
+	t.Run(
+		"RuntimeContexts",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			done := runConsumer(t, consumer)
+
+			assert.Nil(t, consumer.AddContexts([]string{"tenant1", "test", "tenant2", "tenant1"}))
+
+			req, err := srv.WaitFor("signalwire.receive", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"contexts":["tenant1","tenant2"]}`, string(req.Params), "only the new contexts are received")
+
+			assert.Nil(t, consumer.RemoveContexts([]string{"test", "unknown"}))
+
+			req, err = srv.WaitFor("signalwire.unreceive", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"contexts":["test"]}`, string(req.Params))
+			assert.Equal(t, []string{"tenant1", "tenant2"}, consumer.Contexts)
+
+			srv.Handle("signalwire.receive", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+				return map[string]string{"code": "403", "message": "context not authorized"}, nil
+			})
+
+			_, ok := consumer.AddContexts([]string{"tenant3"}).(*signalwire.ScopeError)
+			assert.True(t, ok, "should be a ScopeError")
+			assert.Equal(t, []string{"tenant1", "tenant2"}, consumer.Contexts, "unauthorized context must not be kept")
+
+			srv.Handle("signalwire.receive", nil)
+
+			receives := count(srv, "signalwire.receive")
+
+			srv.DropConnections()
+			waitCount(t, srv, "signalwire.receive", receives+1)
+
+			req, err = srv.WaitFor("signalwire.receive", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"contexts":["tenant1","tenant2"]}`, string(req.Params), "the current contexts are received again after reconnect")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"WireCaptureReplay",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			var capture bytes.Buffer
+
+			consumer := newConsumer(srv)
+			consumer.WireRecorder = signalwire.NewWireRecorder(&capture)
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			err := srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+			assert.True(t, call.WaitForEnded(2))
+
+			stopConsumer(t, consumer, done)
+			assert.Nil(t, consumer.WireRecorder.Err())
+
+			lines := strings.Split(strings.TrimSpace(capture.String()), "\n")
+			assert.True(t, len(lines) > 8, "every frame must be captured")
+
+			var frame signalwire.WireFrame
+
+			assert.Nil(t, json.Unmarshal([]byte(lines[0]), &frame))
+			assert.Equal(t, signalwire.WireOut, frame.Direction)
+			assert.Contains(t, string(frame.Frame), `"blade.connect"`)
+			assert.Contains(t, string(frame.Frame), `"token":"[REDACTED]"`, "credentials must be redacted")
+			assert.Contains(t, capture.String(), `"signature":"[REDACTED]"`)
+
+			replayer, err := signalwire.NewWireReplayer()
+			assert.Nil(t, err)
+
+			replayer.Blade.BladeSetupInbound(context.Background())
+
+			// up to the inbound call, then the rest of the capture
+			split := strings.Index(capture.String(), "calling.call.receive")
+			split += strings.Index(capture.String()[split:], "\n") + 1
+
+			assert.Nil(t, replayer.Replay(context.Background(), strings.NewReader(capture.String()[:split])))
+
+			replayed, err := replayer.Blade.BladeWaitInboundCall(context.Background())
+			if assert.Nil(t, err) && assert.NotNil(t, replayed) {
+				assert.Equal(t, testCallID, replayed.CallID)
+				assert.Equal(t, signalwire.Created, replayed.GetState())
+			}
+
+			assert.Nil(t, replayer.Replay(context.Background(), strings.NewReader(capture.String()[split:])))
+			assert.Equal(t, signalwire.Ended, replayed.GetState(), "replayed events must reach the call")
+		},
+	)
+	t.Run(
+		"RawExecuteSubscribe",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.Handle("calling.new_feature", func(params json.RawMessage) (interface{}, *jsonrpc2.Error) {
+				if strings.Contains(string(params), "fail") {
+					return map[string]string{"code": "400", "message": "bad feature"}, nil
+				}
+
+				return map[string]string{"code": "200", "message": "OK", "feature_id": "f1"}, nil
+			})
+
+			consumer := newConsumer(srv)
+
+			events := make(chan *signalwire.RawEvent, 2)
+			unsubscribe := consumer.Subscribe("calling.call.new_feature", func(ev *signalwire.RawEvent) {
+				events <- ev
+			})
+
+			all := make(chan string, 10)
+			consumer.Subscribe(signalwire.RawEventAll, func(ev *signalwire.RawEvent) {
+				all <- ev.EventType
+			})
+
+			done := runConsumer(t, consumer)
+
+			res, err := consumer.Client.ExecuteRaw(context.Background(), "calling.new_feature", map[string]string{"call_id": testCallID})
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"code":"200","message":"OK","feature_id":"f1"}`, string(res))
+
+			req, err := srv.WaitFor("calling.new_feature", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"call_id":"`+testCallID+`"}`, string(req.Params))
+
+			_, err = consumer.Client.ExecuteRaw(context.Background(), "calling.new_feature", map[string]string{"mode": "fail"})
+			assert.NotNil(t, err)
+			assert.Equal(t, "bad feature", err.Error())
+
+			err = srv.SendEvent("calling.call.new_feature", map[string]string{"call_id": testCallID, "state": "started"})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			select {
+			case ev := <-events:
+				assert.Equal(t, "queuing.relay.events", ev.Event)
+				assert.Equal(t, "calling.call.new_feature", ev.EventType)
+				assert.JSONEq(t, `{"call_id":"`+testCallID+`","state":"started"}`, string(ev.Params))
+				assert.Contains(t, string(ev.Broadcast), `"event_type":"calling.call.new_feature"`)
+			case <-time.After(2 * time.Second):
+				t.Fatalf("unknown event not delivered")
+			}
+
+			assert.Equal(t, "calling.call.new_feature", <-all)
+
+			unsubscribe()
+
+			err = srv.SendEvent("calling.call.new_feature", map[string]string{"call_id": testCallID, "state": "finished"})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+			assert.Equal(t, "calling.call.new_feature", <-all)
+
+			select {
+			case <-events:
+				t.Errorf("event delivered after unsubscribe")
+			default:
+			}
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"GracefulDrain",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			consumer.DrainTimeout = 5 * time.Second
+			consumer.HangupOnDrain = true
+
+			calls := make(chan *signalwire.CallObj, 2)
+			release := make(chan struct{})
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+				<-release
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := runConsumerContext(t, ctx, consumer)
+
+			receiveCall(t, srv, calls)
+			cancel()
+
+			req, err := srv.WaitFor("signalwire.unreceive", time.Second)
+			assert.Nil(t, err, "new calls must go to the other consumers")
+			assert.JSONEq(t, `{"contexts":["test"]}`, string(req.Params))
+
+			err = srv.SendEvent("calling.call.receive", map[string]interface{}{
+				"call_state": "created",
+				"context":    "test",
+				"direction":  "inbound",
+				"call_id":    "b8a4d0f6-1c55-4b9e-9d0e-6a4c59d4e2a7",
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			select {
+			case <-calls:
+				t.Errorf("handler run while draining")
+			case err = <-done:
+				t.Fatalf("RunContext returned before the handler finished: %v", err)
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			close(release)
+
+			select {
+			case err = <-done:
+				assert.Nil(t, err, "clean drain")
+			case <-time.After(5 * time.Second):
+				t.Fatalf("RunContext did not return after the drain")
+			}
+
+			assert.Equal(t, 2, count(srv, "calling.end"), "the calls left, handled or not, must be hung up")
+			assert.Equal(t, 1, count(srv, "blade.disconnect"))
+		},
+	)
+	t.Run(
+		"DrainTimeout",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			consumer.DrainTimeout = 50 * time.Millisecond
+
+			calls := make(chan *signalwire.CallObj, 1)
+			release := make(chan struct{})
+			defer close(release)
+
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+				<-release
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			done := runConsumerContext(t, ctx, consumer)
+
+			receiveCall(t, srv, calls)
+			cancel()
+
+			select {
+			case err := <-done:
+				drainErr, ok := err.(*signalwire.DrainError)
+				if assert.True(t, ok, "should be a DrainError, got %v", err) {
+					assert.Equal(t, 1, drainErr.Running)
+					assert.Equal(t, 0, drainErr.HungUp)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("RunContext did not return after the drain timeout")
+			}
+
+			assert.Equal(t, 0, count(srv, "calling.end"))
+		},
+	)
+	t.Run(
+		"ContextCancel",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			calls := make(chan *signalwire.CallObj, 1)
+			results := make(chan error, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+
+				ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+				defer cancel()
+
+				// the play never finishes: the server does not send calling.call.play
+				_, err := call.PlayTTSContext(ctx, "Welcome", "en-US", "female")
+				results <- err
+			}
+
+			done := runConsumer(t, consumer)
+
+			receiveCall(t, srv, calls)
+
+			select {
+			case err := <-results:
+				assert.Equal(t, context.DeadlineExceeded, err)
+			case <-time.After(2 * time.Second):
+				t.Fatalf("PlayTTSContext did not return when ctx expired")
+			}
+
+			req, err := srv.WaitFor("calling.play.stop", time.Second)
+			assert.Nil(t, err, "cancelling ctx must stop the play")
+			assert.Contains(t, string(req.Params), testCallID)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			// the message is accepted but no state is sent
+			res := consumer.Client.Messaging.SendContext(ctx, "+15551230001", "+15551230002", "test", "Hello")
+			assert.Equal(t, context.DeadlineExceeded, res.GetError())
+			assert.False(t, res.GetCompleted())
+			assert.Equal(t, 1, count(srv, "messaging.send"))
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"HostFailover",
+		func(t *testing.T) {
+			preferred := relaytest.NewServer()
+			defer preferred.Close()
+
+			fallback := relaytest.NewServer()
+			defer fallback.Close()
+
+			preferred.RejectConnections(true)
+
+			roots := preferred.CertPool()
+			roots.AddCert(fallback.Certificate())
+
+			consumer := newConsumer(fallback)
+			consumer.Host = ""
+			consumer.Hosts = []signalwire.RelayHost{
+				{Host: fallback.Host(), Weight: 1},
+				{Host: preferred.Host(), Weight: 10},
+			}
+			consumer.Failover = &signalwire.FailoverPolicy{FailbackInterval: 50 * time.Millisecond}
+			consumer.Transport = &signalwire.TransportConfig{RootCAs: roots}
+			consumer.ConnectionStates = make(chan signalwire.ConnectionStateChange, 100)
+
+			done := runConsumer(t, consumer)
+
+			assert.Equal(t, fallback.Host(), consumer.ActiveHost(), "the preferred host is down")
+			assert.Equal(t, 1, count(fallback, "blade.connect"))
+			assert.Equal(t, 0, count(preferred, "blade.connect"))
+
+			waitStates(t, consumer.ConnectionStates, signalwire.BladeRunning)
+
+			preferred.RejectConnections(false)
+
+			_, err := preferred.WaitFor("blade.connect", 5*time.Second)
+			assert.Nil(t, err, "should fail back to the preferred host")
+
+			var change signalwire.ConnectionStateChange
+
+			for change.State != signalwire.BladeRunning || change.Host != preferred.Host() {
+				select {
+				case change = <-consumer.ConnectionStates:
+				case <-time.After(5 * time.Second):
+					t.Fatalf("not running on the preferred host, last change %+v", change)
+				}
+			}
+
+			assert.Equal(t, preferred.Host(), consumer.ActiveHost())
+			assert.Equal(t, 1, consumer.LinkHealth().Reconnects)
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"SessionMigration",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.RestoreSessions = true
+
+			consumer := newConsumer(srv)
+			consumer.OnOrphanedCalls = func(_ *signalwire.Consumer, calls []*signalwire.CallObj) {
+				t.Errorf("%d calls orphaned", len(calls))
+			}
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			err := srv.Netcast("protocol.remove", srv.Protocol)
+			assert.Nil(t, err, "should not be an error from Netcast")
+
+			waitCount(t, srv, "signalwire.receive", 2)
+			assert.Equal(t, 2, count(srv, "setup"), "the protocol must be set up again")
+			assert.Equal(t, 2, count(srv, "blade.subscription"), "notifications must be subscribed again")
+			assert.Equal(t, 1, count(srv, "blade.connect"), "no reconnect to migrate")
+
+			err = srv.Disconnect()
+			assert.Nil(t, err, "should not be an error from Disconnect")
+
+			waitCount(t, srv, "blade.connect", 2)
+			assert.Nil(t, srv.WaitConnections(1, 2*time.Second))
+			assert.Nil(t, call.GetError())
+
+			err = srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+			assert.True(t, call.WaitForEnded(2), "events must reach the migrated call")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"MigrationNoNetcast",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			consumer.ConnectTimeout = 300 * time.Millisecond
+			consumer.OnIncomingCall = func(*signalwire.Consumer, *signalwire.CallObj) {}
+
+			done := runConsumer(t, consumer)
+
+			// the new node never announces the protocol
+			srv.Handle("setup", func(json.RawMessage) (interface{}, *jsonrpc2.Error) {
+				return map[string]string{"protocol": srv.Protocol}, nil
+			})
+
+			err := srv.Netcast("protocol.remove", srv.Protocol)
+			assert.Nil(t, err, "should not be an error from Netcast")
+
+			waitCount(t, srv, "setup", 2)
+			srv.Handle("setup", nil)
+
+			waitCount(t, srv, "blade.connect", 2)
+			waitCount(t, srv, "signalwire.receive", 2)
+			assert.Equal(t, 3, count(srv, "setup"), "a new session must be set up")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"OrphanedCalls",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			orphaned := make(chan []*signalwire.CallObj, 1)
+			consumer.OnOrphanedCalls = func(_ *signalwire.Consumer, calls []*signalwire.CallObj) {
+				orphaned <- calls
+			}
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			receiveCall(t, srv, calls)
+
+			err := srv.Disconnect()
+			assert.Nil(t, err, "should not be an error from Disconnect")
+
+			select {
+			case lost := <-orphaned:
+				if assert.Len(t, lost, 1) {
+					assert.Equal(t, testCallID, lost[0].GetID())
+					assert.Equal(t, signalwire.ErrSessionLost, lost[0].GetError())
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("calls not reported as orphaned")
+			}
+
+			waitCount(t, srv, "signalwire.receive", 2)
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"EventBackpressure",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			consumer.EventBus = &signalwire.EventBusConfig{QueueSize: 2, Overflow: signalwire.EventOverflowError}
+
+			calls := make(chan *signalwire.CallObj, 2)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			noisy := receiveCall(t, srv, calls)
+
+			const quietCallID = "5b3f1e0e-8d6f-4f0e-a3c2-2f7c9b8a1d44"
+
+			err := srv.SendEvent("calling.call.receive", map[string]interface{}{
+				"call_state": "created",
+				"context":    "test",
+				"direction":  "inbound",
+				"call_id":    quietCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			var quiet *signalwire.CallObj
+
+			select {
+			case quiet = <-calls:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no second inbound call")
+			}
+
+			// nobody waits for this recording: its delivery is stuck
+			err = srv.SendEvent("calling.call.record", map[string]string{
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+				"control_id": "not-started",
+				"state":      "recording",
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			time.Sleep(100 * time.Millisecond)
+
+			for i := 0; i < 4; i++ {
+				err = srv.SendEvent("calling.call.play", map[string]string{
+					"call_id":    testCallID,
+					"node_id":    testNodeID,
+					"control_id": "not-started",
+					"state":      "playing",
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			err = srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    quietCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+			assert.True(t, quiet.WaitForEnded(2), "a stuck call must not hold up the others")
+
+			assert.Equal(t, uint64(2), noisy.EventStats().Dropped, "the queue holds 2 events")
+			assert.Equal(t, uint64(2), consumer.EventStats().Dropped)
+			assert.Equal(t, uint64(0), quiet.EventStats().Dropped)
+			assert.True(t, quiet.EventStats().Delivered >= 2)
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"ManyCalls",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			const n = 150
+
+			consumer := newConsumer(srv)
+			consumer.CallRegistry = &signalwire.CallRegistryConfig{SoftLimit: 120}
+
+			done := runConsumer(t, consumer)
+
+			callID := func(i int) string {
+				return fmt.Sprintf("00000000-0000-4000-8000-%012d", i)
+			}
+
+			for i := 0; i < n; i++ {
+				err := srv.SendEvent("calling.call.receive", map[string]interface{}{
+					"call_state": "created",
+					"context":    "test",
+					"direction":  "inbound",
+					"call_id":    callID(i),
+					"node_id":    testNodeID,
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for consumer.CallStats().Active < n && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			stats := consumer.CallStats()
+			assert.Equal(t, n, stats.Active, "no ceiling on the concurrent calls")
+			assert.Equal(t, uint64(n-120), stats.AboveSoftLimit)
+
+			for i := 0; i < n; i++ {
+				err := srv.SendEvent("calling.call.state", map[string]interface{}{
+					"call_state": "ended",
+					"end_reason": "hangup",
+					"direction":  "inbound",
+					"call_id":    callID(i),
+					"node_id":    testNodeID,
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			deadline = time.Now().Add(5 * time.Second)
+			for consumer.CallStats().Active > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			stats = consumer.CallStats()
+			assert.Equal(t, 0, stats.Active, "ended calls must leave the registry")
+			assert.Equal(t, n, stats.Peak)
+			assert.Equal(t, uint64(n), stats.Removed)
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"EventListeners",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			var (
+				mu     sync.Mutex
+				got    []string
+				events []signalwire.CallEvent
+			)
+
+			seen := func(name string) signalwire.CallEventListener {
+				return func(args *signalwire.CallEventArgs) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					assert.Equal(t, call, args.Call)
+					assert.NotNil(t, args.Play)
+
+					got = append(got, name)
+				}
+			}
+
+			finished := make(chan struct{})
+			call.OnPlayFinished = func(*signalwire.PlayAction) {
+				mu.Lock()
+				got = append(got, "field")
+				mu.Unlock()
+			}
+			call.On(signalwire.EventPlayFinished, seen("metrics"))
+			call.On(signalwire.EventPlayFinished, seen("ivr"))
+			unsubscribe := call.On(signalwire.EventPlayFinished, seen("gone"))
+			unsubscribe()
+			unsubscribe()
+			call.OnAny(func(args *signalwire.CallEventArgs) {
+				mu.Lock()
+				events = append(events, args.Event)
+				mu.Unlock()
+
+				if args.Event == signalwire.EventPlayFinished {
+					close(finished)
+				}
+			})
+
+			_, err := call.PlayAudioAsync("https://cdn.signalwire.com/default-music/welcome.mp3")
+			assert.Nil(t, err, "should not be an error from PlayAudioAsync")
+
+			req, err := srv.WaitFor("calling.play", time.Second)
+			assert.Nil(t, err, "the play must be sent")
+
+			var params struct {
+				ControlID string `json:"control_id"`
+			}
+
+			assert.Nil(t, json.Unmarshal(req.Params, &params))
+
+			for _, state := range []string{"playing", "finished"} {
+				err = srv.SendEvent("calling.call.play", map[string]string{
+					"call_id":    testCallID,
+					"node_id":    testNodeID,
+					"control_id": params.ControlID,
+					"state":      state,
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			select {
+			case <-finished:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no PlayFinished event")
+			}
+
+			mu.Lock()
+			assert.Equal(t, []string{"field", "metrics", "ivr"}, got, "the field runs first, then the listeners in order")
+			assert.Equal(t, []signalwire.CallEvent{signalwire.EventPlayPlaying, signalwire.EventPlayFinished}, events[:2], "the wildcard listener gets every event")
+			mu.Unlock()
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"CallbackOrder",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			var (
+				mu  sync.Mutex
+				got []string
+			)
+
+			seen := func(name string) {
+				mu.Lock()
+				got = append(got, name)
+				mu.Unlock()
+			}
+
+			ended := make(chan struct{})
+			actions := make(chan *signalwire.PlayAction, 2)
+			playEvents := make(chan (<-chan *signalwire.CallEventArgs), 1)
+			call.OnAnswered = func(*signalwire.CallObj) { seen("answered") }
+			call.OnPlayPlaying = func(a *signalwire.PlayAction) {
+				actions <- a
+				playEvents <- a.Events()
+				seen("playing")
+			}
+			call.OnPlayFinished = func(a *signalwire.PlayAction) {
+				actions <- a
+				seen("finished")
+			}
+			call.On(signalwire.EventPlayPlaying, func(args *signalwire.CallEventArgs) {
+				assert.Equal(t, signalwire.PlayPlaying, args.ActionState.State, "the listener gets the state of its event")
+			})
+			call.On(signalwire.EventPlayFinished, func(args *signalwire.CallEventArgs) {
+				assert.Equal(t, signalwire.PlayFinished, args.ActionState.State)
+				assert.True(t, args.ActionState.Completed)
+			})
+			call.OnEnded = func(*signalwire.CallObj) {
+				seen("ended")
+				close(ended)
+			}
+
+			answered := make(chan struct{})
+
+			go func() {
+				defer close(answered)
+
+				_, err := call.Answer()
+				assert.Nil(t, err, "should not be an error from Answer")
+			}()
+
+			_, err := srv.WaitFor("calling.answer", time.Second)
+			assert.Nil(t, err, "the answer must be sent")
+
+			err = srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "answered",
+				"direction":  "inbound",
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			<-answered
+
+			play, err := call.PlayAudioAsync("https://cdn.signalwire.com/default-music/welcome.mp3")
+			assert.Nil(t, err, "should not be an error from PlayAudioAsync")
+
+			req, err := srv.WaitFor("calling.play", time.Second)
+			assert.Nil(t, err, "the play must be sent")
+
+			var params struct {
+				ControlID string `json:"control_id"`
+			}
+
+			assert.Nil(t, json.Unmarshal(req.Params, &params))
+
+			for _, state := range []string{"playing", "finished"} {
+				err = srv.SendEvent("calling.call.play", map[string]string{
+					"call_id":    testCallID,
+					"node_id":    testNodeID,
+					"control_id": params.ControlID,
+					"state":      state,
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			for i := 0; i < 2; i++ {
+				select {
+				case a := <-actions:
+					assert.True(t, a == play, "the callbacks get the action itself")
+				case <-time.After(2 * time.Second):
+					t.Fatalf("no play callback")
+				}
+			}
+
+			select {
+			case events := <-playEvents:
+				var got []signalwire.CallEvent
+
+				for args := range events {
+					got = append(got, args.Event)
+				}
+
+				assert.Contains(t, got, signalwire.EventPlayFinished, "Events from a callback gets the next events")
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no play events")
+			}
+
+			err = srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			select {
+			case <-ended:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no Ended callback")
+			}
+
+			call.WaitForCallbacks()
+
+			mu.Lock()
+			assert.Equal(t, []string{"answered", "playing", "finished", "ended"}, got, "the callbacks must run in arrival order")
+			mu.Unlock()
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"EventChannels",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+			callEvents := call.Events()
+
+			// controlID returns the control_id of the last method sent
+			controlID := func(method string) string {
+				req, err := srv.WaitFor(method, time.Second)
+				assert.Nil(t, err, "%s must be sent", method)
+
+				var params struct {
+					ControlID string `json:"control_id"`
+				}
+
+				assert.Nil(t, json.Unmarshal(req.Params, &params))
+
+				return params.ControlID
+			}
+
+			// drain reads the events until the channel is closed
+			drain := func(events <-chan *signalwire.CallEventArgs) []*signalwire.CallEventArgs {
+				var got []*signalwire.CallEventArgs
+
+				for {
+					select {
+					case args, ok := <-events:
+						if !ok {
+							return got
+						}
+
+						got = append(got, args)
+					case <-time.After(2 * time.Second):
+						t.Fatalf("events channel not closed")
+					}
+				}
+			}
+
+			play, err := call.PlayAudioAsync("https://cdn.signalwire.com/default-music/welcome.mp3")
+			assert.Nil(t, err, "should not be an error from PlayAudioAsync")
+
+			playEvents := play.Events()
+			ctrlID := controlID("calling.play")
+
+			for _, state := range []string{"playing", "finished"} {
+				err = srv.SendEvent("calling.call.play", map[string]string{
+					"call_id":    testCallID,
+					"node_id":    testNodeID,
+					"control_id": ctrlID,
+					"state":      state,
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			got := drain(playEvents)
+			if assert.True(t, len(got) >= 2) {
+				assert.Equal(t, signalwire.EventPlayPlaying, got[0].Event)
+				assert.Equal(t, signalwire.EventPlayFinished, got[1].Event)
+				assert.Equal(t, play.GetControlID(), got[1].Play.ControlID)
+				assert.True(t, got[1].Play == play, "the events carry the action itself")
+				assert.Equal(t, signalwire.PlayPlaying, got[0].ActionState.State, "and its state when they arrived")
+				assert.Equal(t, signalwire.PlayFinished, got[1].ActionState.State)
+			}
+
+			assert.Equal(t, playEvents, play.Events(), "Events must return the same channel")
+
+			prompt, err := call.PromptAsync(
+				&[]signalwire.PlayStruct{{Type: "tts", Params: signalwire.PlayTTSParams{Text: "Your PIN?"}}},
+				&signalwire.CollectStruct{Digits: &signalwire.CollectDigits{Max: 4}, PartialResults: true},
+			)
+			assert.Nil(t, err, "should not be an error from PromptAsync")
+
+			promptEvents := prompt.Events()
+			ctrlID = controlID("calling.play_and_collect")
+
+			err = srv.SendEvent("calling.call.collect", map[string]interface{}{
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+				"control_id": ctrlID,
+				"final":      false,
+				"result":     map[string]interface{}{"type": "digit", "params": map[string]string{"digits": "12"}},
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			select {
+			case args := <-promptEvents:
+				assert.Equal(t, signalwire.EventPromptUpdate, args.Event)
+				result, _ := args.ActionState.Result.(signalwire.CollectResult)
+				assert.Equal(t, "12", result.Result)
+				assert.Equal(t, signalwire.CollectPartial, result.Continue)
+				assert.False(t, prompt.GetCompleted(), "a partial result must not end the prompt")
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no partial result")
+			}
+
+			err = srv.SendEvent("calling.call.collect", map[string]interface{}{
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+				"control_id": ctrlID,
+				"final":      true,
+				"result":     map[string]interface{}{"type": "digit", "params": map[string]string{"digits": "1234", "terminator": "#"}},
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			got = drain(promptEvents)
+			if assert.Len(t, got, 1) {
+				assert.Equal(t, signalwire.EventPrompt, got[0].Event)
+			}
+
+			err = srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			got = drain(callEvents)
+			assert.True(t, len(got) >= 4, "the call gets the events of its actions")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"EventsOfFailedActions",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			// closed tells if the events channel is closed without events
+			closed := func(events <-chan *signalwire.CallEventArgs) bool {
+				select {
+				case args, ok := <-events:
+					return !ok && args == nil
+				case <-time.After(2 * time.Second):
+					return false
+				}
+			}
+
+			var detached signalwire.CallObj
+
+			play, err := detached.PlayAudioAsync("https://cdn.signalwire.com/default-music/welcome.mp3")
+			assert.NotNil(t, err, "no Calling object")
+			assert.True(t, closed(play.Events()), "an action without a call has no events")
+
+			connect, err := detached.ConnectAsync("+15551234567", "+15557654321")
+			assert.NotNil(t, err, "no Calling object")
+			assert.True(t, closed(connect.Events()), "an action without a call has no events")
+
+			srv.Reply("calling.play", "404", "call gone")
+			srv.Reply("calling.connect", "404", "call gone")
+
+			play, err = call.PlayAudioAsync("https://cdn.signalwire.com/default-music/welcome.mp3")
+			assert.NotNil(t, err, "the play must fail")
+			assert.True(t, closed(play.Events()), "a failed action has no events")
+
+			connect, err = call.ConnectAsync("+15551234567", "+15557654321")
+			assert.NotNil(t, err, "the connect must fail")
+			assert.True(t, closed(connect.Events()), "a failed action has no events")
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"Interceptors",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			srv.Handle("calling.new_feature", func(params json.RawMessage) (interface{}, *jsonrpc2.Error) {
+				return map[string]string{"code": "200", "message": "OK"}, nil
+			})
+
+			type audit struct {
+				method  string
+				latency time.Duration
+				err     error
+			}
+
+			var (
+				mu       sync.Mutex
+				audited  []audit
+				incoming []string
+			)
+
+			errInjected := fmt.Errorf("injected")
+
+			consumer := newConsumer(srv)
+			consumer.Interceptors = &signalwire.Interceptors{
+				Outgoing: []signalwire.OutgoingInterceptor{
+					func(ctx context.Context, cmd *signalwire.OutgoingCommand, res interface{}, next signalwire.ExecuteHandler) (interface{}, error) {
+						r, err := next(ctx, cmd, res)
+
+						mu.Lock()
+						audited = append(audited, audit{cmd.Method, cmd.Latency, err})
+						mu.Unlock()
+
+						return r, err
+					},
+					func(ctx context.Context, cmd *signalwire.OutgoingCommand, res interface{}, next signalwire.ExecuteHandler) (interface{}, error) {
+						if strings.Contains(string(cmd.Params), "inject") {
+							return nil, errInjected
+						}
+
+						cmd.Params = json.RawMessage(strings.Replace(string(cmd.Params), "4111111111111111", "[REDACTED]", -1))
+
+						return next(ctx, cmd, res)
+					},
+				},
+				Incoming: []signalwire.IncomingInterceptor{
+					func(ctx context.Context, broadcast *signalwire.NotifParamsBladeBroadcast, raw *json.RawMessage, next signalwire.BroadcastHandler) error {
+						mu.Lock()
+						incoming = append(incoming, broadcast.Params.EventType)
+						mu.Unlock()
+
+						if broadcast.Params.EventType == "calling.call.secret" {
+							return nil
+						}
+
+						return next(ctx, broadcast, raw)
+					},
+				},
+			}
+
+			all := make(chan string, 10)
+			consumer.Subscribe(signalwire.RawEventAll, func(ev *signalwire.RawEvent) {
+				all <- ev.EventType
+			})
+
+			done := runConsumer(t, consumer)
+
+			_, err := consumer.Client.ExecuteRaw(context.Background(), "calling.new_feature", map[string]string{"card": "4111111111111111"})
+			assert.Nil(t, err)
+
+			req, err := srv.WaitFor("calling.new_feature", time.Second)
+			assert.Nil(t, err)
+			assert.JSONEq(t, `{"card":"[REDACTED]"}`, string(req.Params), "the interceptor must be able to change the params")
+
+			_, err = consumer.Client.ExecuteRaw(context.Background(), "calling.new_feature", map[string]string{"mode": "inject"})
+			assert.Equal(t, errInjected, err)
+			assert.Equal(t, 1, count(srv, "calling.new_feature"), "an injected fault must not reach the server")
+
+			mu.Lock()
+			n := len(audited)
+			if assert.True(t, n >= 2) {
+				assert.Equal(t, audit{"calling.new_feature", audited[n-1].latency, errInjected}, audited[n-1])
+				assert.Equal(t, "calling.new_feature", audited[n-2].method)
+				assert.Nil(t, audited[n-2].err)
+				assert.True(t, audited[n-2].latency > 0)
+			}
+			mu.Unlock()
+
+			for _, eventType := range []string{"calling.call.secret", "calling.call.public"} {
+				err = srv.SendEvent(eventType, map[string]string{"call_id": testCallID})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			select {
+			case eventType := <-all:
+				assert.Equal(t, "calling.call.public", eventType, "the dropped event must not be dispatched")
+			case <-time.After(2 * time.Second):
+				t.Fatalf("event not dispatched")
+			}
+
+			mu.Lock()
+			assert.Equal(t, []string{"calling.call.secret", "calling.call.public"}, incoming)
+			mu.Unlock()
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"ActionReclaim",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+			call := receiveCall(t, srv, calls)
+
+			play, err := call.PlayAudioAsync("https://cdn.signalwire.com/default-music/welcome.mp3")
+			assert.Nil(t, err, "should not be an error from PlayAudioAsync")
+
+			record, err := call.RecordAudioAsync(&signalwire.RecordParams{})
+			assert.Nil(t, err, "should not be an error from RecordAudioAsync")
+
+			playEvents := play.Events()
+
+			_, err = srv.WaitFor("calling.record", time.Second)
+			assert.Nil(t, err, "calling.record must be sent")
+
+			assert.Equal(t, uint64(2), consumer.ActionStats().Active)
+
+			// the call ends without a terminal event for its actions
+			err = srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    testCallID,
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			select {
+			case _, ok := <-playEvents:
+				assert.False(t, ok, "the events channel must be closed")
+			case <-time.After(2 * time.Second):
+				t.Fatalf("events channel not closed")
+			}
+
+			assert.True(t, play.GetCompleted())
+			assert.Equal(t, signalwire.ErrCallEnded, play.GetError())
+
+			deadline := time.Now().Add(2 * time.Second)
+			for consumer.ActionStats().Reclaimed < 2 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			assert.True(t, record.GetCompleted())
+			assert.Equal(t, signalwire.ErrCallEnded, record.GetError())
+			assert.Equal(t, signalwire.ActionStats{Started: 2, Reclaimed: 2}, consumer.ActionStats())
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"Admission",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			consumer.Admission = &signalwire.AdmissionConfig{
+				MaxConcurrent: 1,
+				Overflow:      signalwire.AdmissionQueue,
+				QueueSize:     1,
+				QueueTimeout:  2 * time.Second,
+			}
+
+			release := make(chan struct{})
+			handled := make(chan string, 3)
+
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				handled <- call.GetID()
+
+				if call.GetID() == "call-a" {
+					<-release
+				}
+			}
+
+			done := runConsumer(t, consumer)
+
+			receive := func(callID string) {
+				err := srv.SendEvent("calling.call.receive", map[string]interface{}{
+					"call_state": "created",
+					"context":    "test",
+					"device": map[string]interface{}{
+						"type":   "phone",
+						"params": map[string]string{"from_number": "+15551230001", "to_number": "+15551230002"},
+					},
+					"direction": "inbound",
+					"call_id":   callID,
+					"node_id":   testNodeID,
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			receive("call-a")
+
+			select {
+			case id := <-handled:
+				assert.Equal(t, "call-a", id)
+			case <-time.After(2 * time.Second):
+				t.Fatalf("call-a not handled")
+			}
+
+			receive("call-b")
+
+			deadline := time.Now().Add(2 * time.Second)
+			for consumer.AdmissionStats().Waiting < 1 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			// the queue is full
+			receive("call-c")
+
+			req, err := srv.WaitFor("calling.end", 2*time.Second)
+			if assert.Nil(t, err, "the call over the queue must be rejected") {
+				assert.JSONEq(t, fmt.Sprintf(`{"call_id":"call-c","node_id":%q,"reason":"busy"}`, testNodeID), string(req.Params))
+			}
+
+			select {
+			case id := <-handled:
+				t.Fatalf("%s handled while the handler is busy", id)
+			default:
+			}
+
+			close(release)
+
+			select {
+			case id := <-handled:
+				assert.Equal(t, "call-b", id, "the queued call must be admitted once a handler is free")
+			case <-time.After(2 * time.Second):
+				t.Fatalf("call-b not handled")
+			}
+
+			deadline = time.Now().Add(2 * time.Second)
+			for consumer.AdmissionStats().Running > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			assert.Equal(t, signalwire.AdmissionStats{Admitted: 2, Rejected: 1, Queued: 1}, consumer.AdmissionStats())
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"AdmissionAbandoned",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+			consumer.Admission = &signalwire.AdmissionConfig{
+				MaxConcurrent: 1,
+				Overflow:      signalwire.AdmissionQueue,
+				QueueTimeout:  5 * time.Second,
+			}
+
+			release := make(chan struct{})
+			handled := make(chan string, 2)
+
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				handled <- call.GetID()
+				<-release
+			}
+
+			done := runConsumer(t, consumer)
+
+			for _, callID := range []string{"call-a", "call-b"} {
+				err := srv.SendEvent("calling.call.receive", map[string]interface{}{
+					"call_state": "created",
+					"context":    "test",
+					"device": map[string]interface{}{
+						"type":   "phone",
+						"params": map[string]string{"from_number": "+15551230001", "to_number": "+15551230002"},
+					},
+					"direction": "inbound",
+					"call_id":   callID,
+					"node_id":   testNodeID,
+				})
+				assert.Nil(t, err, "should not be an error from SendEvent")
+			}
+
+			deadline := time.Now().Add(2 * time.Second)
+			for consumer.AdmissionStats().Waiting < 1 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			err := srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "ended",
+				"end_reason": "hangup",
+				"direction":  "inbound",
+				"call_id":    "call-b",
+				"node_id":    testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			deadline = time.Now().Add(2 * time.Second)
+			for consumer.AdmissionStats().Waiting > 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			assert.Equal(t, "call-a", <-handled)
+			assert.Equal(t, signalwire.AdmissionStats{Admitted: 1, Queued: 1, Abandoned: 1, Running: 1}, consumer.AdmissionStats())
+			assert.Equal(t, 0, count(srv, "calling.end"), "a call hung up by the caller is not rejected")
+
+			close(release)
+
+			select {
+			case id := <-handled:
+				t.Fatalf("%s handled after its caller hung up", id)
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+	t.Run(
+		"SIP",
+		func(t *testing.T) {
+			srv := relaytest.NewServer()
+			defer srv.Close()
+
+			consumer := newConsumer(srv)
+
+			calls := make(chan *signalwire.CallObj, 1)
+			consumer.OnIncomingCall = func(_ *signalwire.Consumer, call *signalwire.CallObj) {
+				calls <- call
+			}
+
+			done := runConsumer(t, consumer)
+
+			dialed := make(chan signalwire.ResultDial, 1)
+
+			go func() {
+				dialed <- consumer.Client.Calling.DialSIP(signalwire.DeviceSIPParams{
+					From:     "sip:agent@example.com",
+					To:       "sip:bob@example.com",
+					Headers:  []signalwire.SIPHeader{{Name: "X-Account", Value: "42"}},
+					Codecs:   []string{"OPUS", "PCMU"},
+					Username: "agent",
+					Password: "secret",
+				})
+			}()
+
+			req, err := srv.WaitFor("calling.begin", time.Second)
+			assert.Nil(t, err, "calling.begin must be sent")
+
+			var begin struct {
+				Device json.RawMessage `json:"device"`
+				Tag    string          `json:"tag"`
+			}
+
+			assert.Nil(t, json.Unmarshal(req.Params, &begin))
+
+			// the tag is cached once the reply is received
+			time.Sleep(100 * time.Millisecond)
+
+			assert.JSONEq(t, `{"type":"sip","params":{
+				"from":"sip:agent@example.com","to":"sip:bob@example.com","timeout":30,
+				"headers":[{"name":"X-Account","value":"42"}],"codecs":["OPUS","PCMU"],
+				"username":"agent","password":"secret"}}`, string(begin.Device))
+
+			err = srv.SendEvent("calling.call.state", map[string]interface{}{
+				"call_state": "answered",
+				"direction":  "outbound",
+				"device": map[string]interface{}{
+					"type":   "sip",
+					"params": map[string]interface{}{"from": "sip:agent@example.com", "to": "sip:bob@example.com"},
+				},
+				"call_id": "call-sip",
+				"node_id": testNodeID,
+				"tag":     begin.Tag,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			var res signalwire.ResultDial
+
+			select {
+			case res = <-dialed:
+			case <-time.After(2 * time.Second):
+				t.Fatalf("DialSIP did not return")
+			}
+
+			if !assert.True(t, res.Successful, "the call must be answered") {
+				return
+			}
+
+			call := res.Call
+			assert.Equal(t, "sip", call.GetType())
+			assert.Equal(t, "sip:bob@example.com", call.GetTo())
+
+			if sip := call.GetSIP(); assert.NotNil(t, sip) {
+				assert.Equal(t, "sip:agent@example.com", sip.From)
+				assert.Empty(t, sip.Password, "the password must not be kept")
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			connected := make(chan struct{})
+
+			go func() {
+				defer close(connected)
+
+				_, _ = call.ConnectContext(ctx, nil, &[][]signalwire.DeviceStruct{{
+					{Type: signalwire.DeviceSIP, SIP: &signalwire.DeviceSIPParams{From: "sip:agent@example.com", To: "sip:carol@example.com"}},
+					{Type: signalwire.DevicePhone, Params: signalwire.DevicePhoneParams{FromNumber: "+15551230001", ToNumber: "+15551230003"}},
+				}})
+			}()
+
+			req, err = srv.WaitFor("calling.connect", time.Second)
+			assert.Nil(t, err, "calling.connect must be sent")
+
+			var connect struct {
+				Devices json.RawMessage `json:"devices"`
+			}
+
+			assert.Nil(t, json.Unmarshal(req.Params, &connect))
+			assert.JSONEq(t, `[[
+				{"type":"sip","params":{"from":"sip:agent@example.com","to":"sip:carol@example.com"}},
+				{"type":"phone","params":{"from_number":"+15551230001","to_number":"+15551230003","timeout":0}}
+			]]`, string(connect.Devices))
+
+			cancel()
+			<-connected
+
+			action, err := call.ConnectDevicesAsync(
+				&[]signalwire.RingbackStruct{{Type: "ringtone", Params: map[string]string{"name": "us"}}},
+				&[][]signalwire.DeviceStruct{{
+					{Type: signalwire.DeviceSIP, SIP: &signalwire.DeviceSIPParams{From: "sip:agent@example.com", To: "sip:dave@example.com"}},
+				}},
+			)
+			assert.Nil(t, err, "should not be an error from ConnectDevicesAsync")
+
+			req, err = srv.WaitFor("calling.connect", time.Second)
+			assert.Nil(t, err, "calling.connect must be sent")
+
+			var connectAsync struct {
+				Ringback json.RawMessage `json:"ringback"`
+				Devices  json.RawMessage `json:"devices"`
+			}
+
+			assert.Nil(t, json.Unmarshal(req.Params, &connectAsync))
+			assert.JSONEq(t, `[{"type":"ringtone","params":{"name":"us"}}]`, string(connectAsync.Ringback))
+			assert.JSONEq(t, `[[{"type":"sip","params":{"from":"sip:agent@example.com","to":"sip:dave@example.com"}}]]`, string(connectAsync.Devices))
+
+			err = srv.SendEvent("calling.call.connect", map[string]interface{}{
+				"connect_state": "failed",
+				"call_id":       "call-sip",
+				"node_id":       testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			deadline := time.Now().Add(2 * time.Second)
+			for !action.GetCompleted() && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			assert.True(t, action.GetCompleted(), "the connect must complete")
+
+			err = srv.SendEvent("calling.call.receive", map[string]interface{}{
+				"call_state": "created",
+				"context":    "test",
+				"device": map[string]interface{}{
+					"type": "sip",
+					"params": map[string]interface{}{
+						"from":    "sip:alice@example.com",
+						"to":      "sip:support@example.com",
+						"headers": []map[string]string{{"name": "X-Ticket", "value": "1234"}},
+					},
+				},
+				"direction": "inbound",
+				"call_id":   testCallID,
+				"node_id":   testNodeID,
+			})
+			assert.Nil(t, err, "should not be an error from SendEvent")
+
+			select {
+			case inbound := <-calls:
+				assert.Equal(t, "sip", inbound.GetType())
+				assert.Equal(t, "sip:alice@example.com", inbound.GetFrom())
+
+				if sip := inbound.GetSIP(); assert.NotNil(t, sip) {
+					assert.Equal(t, []signalwire.SIPHeader{{Name: "X-Ticket", Value: "1234"}}, sip.Headers)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("no inbound call")
+			}
+
+			stopConsumer(t, consumer, done)
+		},
+	)
+}
+
+// authentication returns the authentication params of a blade.connect or blade.reauthenticate
+func authentication(t *testing.T, req relaytest.Request) string {
+	var p struct {
+		Authentication json.RawMessage `json:"authentication"`
+	}
+
+	if err := json.Unmarshal(req.Params, &p); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+
+	return string(p.Authentication)
+}
+
+// waitStates collects the state transitions up to state
+func waitStates(t *testing.T, ch chan signalwire.ConnectionStateChange, state signalwire.SessionState) []signalwire.SessionState {
+	var states []signalwire.SessionState
+
+	for {
+		select {
+		case change := <-ch:
+			states = append(states, change.State)
+
+			if change.State == state {
+				return states
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no %v state, got %v", state, states)
+
+			return nil
+		}
+	}
+}
+
+const (
+	testCallID = "d42a9c36-9a8f-4a4e-93c5-5b0f3b0b6c1f"
+	testNodeID = "e0a61dc4-9ab5-4cb6-9b42-4c3c2a7e6d3b"
+)
+
+func newConsumer(srv *relaytest.Server) *signalwire.Consumer {
+	consumer := new(signalwire.Consumer)
+	consumer.Setup(srv.Project, "token", []string{"test"})
+	consumer.Host = srv.Host()
+	consumer.Transport = &signalwire.TransportConfig{RootCAs: srv.CertPool()}
+	consumer.ReconnectPolicy = &signalwire.ReconnectPolicy{InitialDelay: 10 * time.Millisecond}
+
+	return consumer
+}
+
+// runConsumer starts the consumer and waits for it to be ready
+func runConsumer(t *testing.T, consumer *signalwire.Consumer) chan error {
+	return runConsumerContext(t, context.Background(), consumer)
+}
+
+// runConsumerContext starts the consumer with RunContext and waits for it to be ready
+func runConsumerContext(t *testing.T, ctx context.Context, consumer *signalwire.Consumer) chan error {
+	ready := make(chan struct{})
+	consumer.Ready = func(*signalwire.Consumer) {
+		close(ready)
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- consumer.RunContext(ctx)
+	}()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		t.Fatalf("Run returned before Ready: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("consumer not ready")
+	}
+
+	return done
+}
+
+func stopConsumer(t *testing.T, consumer *signalwire.Consumer, done chan error) {
+	err := consumer.Stop()
+	assert.Nil(t, err, "should not be an error from Stop")
+
+	select {
+	case err = <-done:
+		assert.Nil(t, err, "should not be an error from Run")
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Run did not return after Stop")
+	}
+}
+
+func receiveCall(t *testing.T, srv *relaytest.Server, calls chan *signalwire.CallObj) *signalwire.CallObj {
+	err := srv.SendEvent("calling.call.receive", map[string]interface{}{
+		"call_state": "created",
+		"context":    "test",
+		"device": map[string]interface{}{
+			"type":   "phone",
+			"params": map[string]string{"from_number": "+15551230001", "to_number": "+15551230002"},
+		},
+		"direction": "inbound",
+		"call_id":   testCallID,
+		"node_id":   testNodeID,
+	})
+	if err != nil {
+		t.Fatalf("SendEvent: %v", err)
+	}
+
+	select {
+	case call := <-calls:
+		return call
+	case <-time.After(2 * time.Second):
+		t.Fatalf("no inbound call")
+	}
+
+	return nil
+}
+
+func count(srv *relaytest.Server, method string) int {
+	n := 0
+
+	for _, r := range srv.Received() {
+		if r.Method == method {
+			n++
+		}
+	}
+
+	return n
+}
+
+func waitCount(t *testing.T, srv *relaytest.Server, method string, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for count(srv, method) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %d %s", n, method)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitLinkDown waits for the consumer to notice the link is gone
+func waitLinkDown(t *testing.T, consumer *signalwire.Consumer) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for consumer.Client.Relay.Blade.GetState() == signalwire.BladeRunning {
+		if time.Now().After(deadline) {
+			t.Fatalf("link still running")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// captureLogger keeps every line logged
+type captureLogger struct {
+	sync.Mutex
+	lines []string
+}
+
+func (l *captureLogger) log(format string, args ...interface{}) {
+	l.Lock()
+	l.lines = append(l.lines, fmt.Sprintf(format, args...))
+	l.Unlock()
+}
+
+func (l *captureLogger) contains(s string) bool {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, line := range l.lines {
+		if strings.Contains(line, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (l *captureLogger) Trace(format string, args ...interface{}) { l.log(format, args...) }
+func (l *captureLogger) Debug(format string, args ...interface{}) { l.log(format, args...) }
+func (l *captureLogger) Info(format string, args ...interface{})  { l.log(format, args...) }
+func (l *captureLogger) Warn(format string, args ...interface{})  { l.log(format, args...) }
+func (l *captureLogger) Error(format string, args ...interface{}) { l.log(format, args...) }
+func (l *captureLogger) Fatal(format string, args ...interface{}) { l.log(format, args...) }
+func (l *captureLogger) Panic(format string, args ...interface{}) { l.log(format, args...) }
+func (l *captureLogger) SetLevel(int)                             {}
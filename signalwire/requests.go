@@ -1,7 +1,10 @@
 package signalwire
 
 import (
+	"bytes"
 	"encoding/json"
+	"strconv"
+	"time"
 )
 
 // DevicePhoneParams TODO DESCRIPTION
@@ -19,13 +22,86 @@ type DeviceAgoraParams struct {
 	Channel string `json:"channel"`
 }
 
-// DeviceStruct TODO DESCRIPTION
+// SIPHeader is a custom header of a SIP device
+type SIPHeader struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// DeviceSIPParams are the params of a sip device: To and From are SIP URIs
+// (sip:alice@example.com)
+type DeviceSIPParams struct {
+	To      string      `json:"to"`
+	From    string      `json:"from"`
+	Timeout uint        `json:"timeout,omitempty"`
+	Headers []SIPHeader `json:"headers,omitempty"`
+	// Codecs in order of preference (PCMU, PCMA, OPUS, G729, G722, VP8, H264)
+	Codecs      []string `json:"codecs,omitempty"`
+	WebRTCMedia bool     `json:"webrtc_media,omitempty"`
+	// Username and Password authenticate the call with the SIP endpoint
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// DeviceStruct is a device to dial: Params for a phone device, SIP for a
+// sip device (Type "sip")
 type DeviceStruct struct {
-	Type string `json:"type"`
-	// todo: make Params interface{}
-	Params DevicePhoneParams `json:"params"`
+	Type   string
+	Params DevicePhoneParams
+	SIP    *DeviceSIPParams
+}
+
+// deviceJSON is DeviceStruct on the wire, the params depend on the type
+type deviceJSON struct {
+	Type   string          `json:"type"`
+	Params json.RawMessage `json:"params"`
+}
+
+// MarshalJSON sends the params of the type of the device
+func (d DeviceStruct) MarshalJSON() ([]byte, error) {
+	var params interface{} = d.Params
+
+	if d.Type == DeviceSIP {
+		params = d.SIP
+	}
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(deviceJSON{Type: d.Type, Params: b})
+}
+
+// UnmarshalJSON decodes the params of the type of the device
+func (d *DeviceStruct) UnmarshalJSON(b []byte) error {
+	var dev deviceJSON
+
+	if err := json.Unmarshal(b, &dev); err != nil {
+		return err
+	}
+
+	*d = DeviceStruct{Type: dev.Type}
+
+	if len(dev.Params) == 0 || string(dev.Params) == "null" {
+		return nil
+	}
+
+	if dev.Type == DeviceSIP {
+		d.SIP = new(DeviceSIPParams)
+
+		return json.Unmarshal(dev.Params, d.SIP)
+	}
+
+	return json.Unmarshal(dev.Params, &d.Params)
 }
 
+// Device types
+const (
+	DevicePhone = "phone"
+	DeviceSIP   = "sip"
+)
+
 // ParamsCallingBeginStruct TODO DESCRIPTION
 type ParamsCallingBeginStruct struct {
 	Device DeviceStruct `json:"device"`
@@ -110,8 +186,15 @@ type ParamsConnectStruct struct {
 
 // AuthStruct TODO DESCRIPTION
 type AuthStruct struct {
-	Project string `json:"project"`
-	Token   string `json:"token"`
+	Project  string `json:"project"`
+	Token    string `json:"token,omitempty"`
+	JWTToken string `json:"jwt_token,omitempty"`
+}
+
+// ParamsReauthenticateStruct TODO DESCRIPTION
+type ParamsReauthenticateStruct struct {
+	SessionID      string     `json:"sessionid"`
+	Authentication AuthStruct `json:"authentication"`
 }
 
 // ReqBladeConnect TODO DESCRIPTION
@@ -143,10 +226,52 @@ type ReqBladeSetup struct {
 
 // ReplyAuthStruct TODO DESCRIPTION
 type ReplyAuthStruct struct {
-	Project   string   `json:"project"`
-	ExpiresAt string   `json:"expires_at"`
-	Scopes    []string `json:"scopes"`
-	Signature string   `json:"signature"`
+	Project   string     `json:"project"`
+	ExpiresAt AuthExpiry `json:"expires_at"`
+	Scopes    []string   `json:"scopes"`
+	Signature string     `json:"signature"`
+}
+
+// AuthExpiry is the expiry of an authorization, zero if it does not expire.
+// Blade sends it as a unix timestamp, as a number or a string.
+type AuthExpiry struct {
+	time.Time
+}
+
+// UnmarshalJSON TODO DESCRIPTION
+func (e *AuthExpiry) UnmarshalJSON(b []byte) error {
+	e.Time = time.Time{}
+
+	b = bytes.Trim(b, `"`)
+	if len(b) == 0 || string(b) == "null" {
+		return nil
+	}
+
+	if secs, err := strconv.ParseFloat(string(b), 64); err == nil {
+		if secs > 0 {
+			e.Time = time.Unix(0, int64(secs*float64(time.Second)))
+		}
+
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, string(b))
+	if err != nil {
+		return err
+	}
+
+	e.Time = t
+
+	return nil
+}
+
+// MarshalJSON TODO DESCRIPTION
+func (e AuthExpiry) MarshalJSON() ([]byte, error) {
+	if e.IsZero() {
+		return []byte("null"), nil
+	}
+
+	return []byte(strconv.FormatInt(e.Unix(), 10)), nil
 }
 
 // ReplyResultConnect TODO DESCRIPTION
@@ -165,6 +290,11 @@ type ReplyResultConnect struct {
 	ProtocolsUncertified []string        `json:"protocols_uncertified"`
 }
 
+// ReplyResultReauthenticate TODO DESCRIPTION
+type ReplyResultReauthenticate struct {
+	Authorization ReplyAuthStruct `json:"authorization"`
+}
+
 // JError TODO DESCRIPTION
 type JError struct {
 	Code    int64
@@ -660,6 +790,7 @@ type ParamsEventCallingCallPlayAndCollect struct {
 	ControlID string        `json:"control_id"`
 	Final     bool          `json:"final,omitempty"`
 	Result    ResultCollect `json:"result"`
+	partial   bool          // "final": false, a partial result
 }
 
 // CollectDigits TODO DESCRIPTION
@@ -0,0 +1,498 @@
+package signalwire
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	bladecache "github.com/dossy/go-cache"
+)
+
+// Store keeps the calls, messages and tasking of the session (see BCache).
+// Keys are prefixed by kind: "call:", "msg:" and "tasking:".
+type Store interface {
+	// Get returns the value of key, false if none or expired
+	Get(key string) (interface{}, bool)
+	// Set sets key to value for ttl, 0 means no expiry
+	Set(key string, value interface{}, ttl time.Duration) error
+	// Delete removes key
+	Delete(key string) error
+	// List returns the keys starting with prefix
+	List(prefix string) []string
+}
+
+// Store key prefixes
+const (
+	StoreCallPrefix    = "call:"
+	StoreMsgPrefix     = "msg:"
+	StoreTaskingPrefix = "tasking:"
+)
+
+// CallRecord is the metadata of a call, as kept by the stores once the call
+// has ended and as persisted by FileStore
+type CallRecord struct {
+	CallID           string `json:"call_id"`
+	TagID            string `json:"tag,omitempty"`
+	NodeID           string `json:"node_id,omitempty"`
+	Context          string `json:"context,omitempty"`
+	Direction        string `json:"direction"`
+	From             string `json:"from,omitempty"`
+	To               string `json:"to,omitempty"`
+	State            string `json:"state"`
+	DisconnectReason string `json:"end_reason,omitempty"`
+}
+
+// storeRecorder is implemented by the live objects, a persistent store saves
+// their record. A nil record keeps the object in memory only.
+type storeRecorder interface {
+	storeRecord() interface{}
+}
+
+// storeRecord is nil: a live call changes with every event, its CallRecord is
+// stored once it has ended (BCache.RemoveCallCache)
+func (c *CallSession) storeRecord() interface{} {
+	return nil
+}
+
+func (c *CallSession) record() CallRecord {
+	c.RLock()
+	defer c.RUnlock()
+
+	return CallRecord{
+		CallID:           c.CallID,
+		TagID:            c.TagID,
+		NodeID:           c.NodeID,
+		Context:          c.Context,
+		Direction:        c.Direction.String(),
+		From:             c.From,
+		To:               c.To,
+		State:            c.CallState.String(),
+		DisconnectReason: c.CallDisconnectReason.String(),
+	}
+}
+
+func (m *MsgSession) storeRecord() interface{} {
+	m.RLock()
+	defer m.RUnlock()
+
+	return m.MsgParams
+}
+
+// MemoryStore is the default Store, in memory
+type MemoryStore struct {
+	p *bladecache.Cache
+}
+
+// NewMemoryStore returns an empty store, expired entries are removed every clean
+func NewMemoryStore(clean time.Duration) *MemoryStore {
+	return &MemoryStore{p: bladecache.New(bladecache.NoExpiration, clean)}
+}
+
+// Get implements Store
+func (s *MemoryStore) Get(key string) (interface{}, bool) {
+	return s.p.Get(key)
+}
+
+// Set implements Store
+func (s *MemoryStore) Set(key string, value interface{}, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = bladecache.NoExpiration
+	}
+
+	s.p.Set(key, value, ttl)
+
+	return nil
+}
+
+// Delete implements Store
+func (s *MemoryStore) Delete(key string) error {
+	s.p.Delete(key)
+
+	return nil
+}
+
+// List implements Store
+func (s *MemoryStore) List(prefix string) []string {
+	keys := make([]string, 0)
+
+	for key := range s.p.Items() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+// FileStore compaction: the file is rewritten with the entries alive once it
+// has FileStoreCompactLines lines or more, and FileStoreCompactRatio times
+// the entries alive or more. The expired entries are swept every CacheCleaning.
+const (
+	FileStoreCompactLines = 1000
+	FileStoreCompactRatio = 2
+)
+
+// FileStore is a Store persisted to a file, so that the call and message
+// metadata outlive a restart. The live objects are kept in memory, their
+// records (MsgParams, the CallRecord of the ended calls) are written: once
+// reloaded they come back as json.RawMessage. The live calls, and values that
+// cannot be encoded, are kept in memory only.
+type FileStore struct {
+	sync.Mutex
+	mem  *MemoryStore
+	path string
+	file *os.File
+	w    *bufio.Writer
+	// entries is the last entry of every key of the file, lines the lines of the file
+	entries map[string]fileStoreEntry
+	lines   int
+	stop    chan struct{}
+}
+
+// fileStoreEntry is a line of the file, Deleted lines remove the key
+type fileStoreEntry struct {
+	Key     string          `json:"key"`
+	Value   json.RawMessage `json:"value,omitempty"`
+	Expires int64           `json:"expires,omitempty"` // unix nano, 0 means no expiry
+	Deleted bool            `json:"deleted,omitempty"`
+}
+
+// NewFileStore opens the store at path, created if needed. The entries not
+// expired are loaded and the file compacted.
+func NewFileStore(path string) (*FileStore, error) {
+	s := &FileStore{
+		mem:     NewMemoryStore(CacheCleaning * time.Second),
+		path:    path,
+		entries: make(map[string]fileStoreEntry),
+		stop:    make(chan struct{}),
+	}
+
+	entries, err := loadFileStore(path)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UnixNano()
+
+	for _, e := range entries {
+		var ttl time.Duration
+
+		if e.Expires > 0 {
+			if ttl = time.Duration(e.Expires - now); ttl <= 0 {
+				continue
+			}
+		}
+
+		_ = s.mem.Set(e.Key, e.Value, ttl)
+		s.entries[e.Key] = e
+	}
+
+	if err := s.compact(); err != nil {
+		return nil, err
+	}
+
+	go s.sweepLoop(CacheCleaning * time.Second)
+
+	return s, nil
+}
+
+// loadFileStore returns the last entry of every key of the file
+func loadFileStore(path string) ([]fileStoreEntry, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	var (
+		order   []string
+		entries = make(map[string]fileStoreEntry)
+	)
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), wireMaxFrame)
+
+	for scanner.Scan() {
+		var e fileStoreEntry
+
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			// a torn last line, after a crash
+			continue
+		}
+
+		if _, found := entries[e.Key]; !found {
+			order = append(order, e.Key)
+		}
+
+		entries[e.Key] = e
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	list := make([]fileStoreEntry, 0, len(entries))
+
+	for _, key := range order {
+		if e := entries[key]; !e.Deleted {
+			list = append(list, e)
+		}
+	}
+
+	return list, nil
+}
+
+func writeFileStoreEntry(w *bufio.Writer, e fileStoreEntry) error {
+	b, err := json.Marshal(&e)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(append(b, '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Get implements Store
+func (s *FileStore) Get(key string) (interface{}, bool) {
+	return s.mem.Get(key)
+}
+
+// Set implements Store
+func (s *FileStore) Set(key string, value interface{}, ttl time.Duration) error {
+	_ = s.mem.Set(key, value, ttl)
+
+	record := value
+	if r, ok := value.(storeRecorder); ok {
+		if record = r.storeRecord(); record == nil {
+			return nil
+		}
+	}
+
+	b, err := json.Marshal(record)
+	if err != nil {
+		// not a record: memory only
+		return nil
+	}
+
+	e := fileStoreEntry{Key: key, Value: b}
+
+	if ttl > 0 {
+		e.Expires = time.Now().Add(ttl).UnixNano()
+	}
+
+	return s.append(e)
+}
+
+// Delete implements Store
+func (s *FileStore) Delete(key string) error {
+	_ = s.mem.Delete(key)
+
+	return s.append(fileStoreEntry{Key: key, Deleted: true})
+}
+
+// List implements Store
+func (s *FileStore) List(prefix string) []string {
+	return s.mem.List(prefix)
+}
+
+func (s *FileStore) append(e fileStoreEntry) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == nil {
+		return errors.New("file store closed")
+	}
+
+	if _, found := s.entries[e.Key]; e.Deleted && !found {
+		// not in the file
+		return nil
+	}
+
+	if err := writeFileStoreEntry(s.w, e); err != nil {
+		return err
+	}
+
+	if err := s.w.Flush(); err != nil {
+		return err
+	}
+
+	s.lines++
+
+	if e.Deleted {
+		delete(s.entries, e.Key)
+	} else {
+		s.entries[e.Key] = e
+	}
+
+	if !s.compactable() {
+		return nil
+	}
+
+	return s.compact()
+}
+
+// compactable tells if the file has enough dead lines to be compacted, the lock is held
+func (s *FileStore) compactable() bool {
+	return s.lines >= FileStoreCompactLines && s.lines >= FileStoreCompactRatio*len(s.entries)
+}
+
+// compact rewrites the file with the entries not expired, the lock is held
+func (s *FileStore) compact() error {
+	tmp := s.path + ".tmp"
+
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	now := time.Now().UnixNano()
+	lines := 0
+
+	for key, e := range s.entries {
+		if e.Expires > 0 && e.Expires <= now {
+			delete(s.entries, key)
+
+			continue
+		}
+
+		if err := writeFileStoreEntry(w, e); err != nil {
+			f.Close()
+
+			return err
+		}
+
+		lines++
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if err := os.Rename(tmp, s.path); err != nil {
+		f.Close()
+
+		return err
+	}
+
+	if s.file != nil {
+		s.file.Close()
+	}
+
+	s.file = f
+	s.w = w
+	s.lines = lines
+
+	return nil
+}
+
+// sweep forgets the expired entries, and compacts the file if it is worth it
+func (s *FileStore) sweep() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	now := time.Now().UnixNano()
+
+	for key, e := range s.entries {
+		if e.Expires > 0 && e.Expires <= now {
+			delete(s.entries, key)
+		}
+	}
+
+	if !s.compactable() {
+		return nil
+	}
+
+	return s.compact()
+}
+
+func (s *FileStore) sweepLoop(every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			// a failure shows on the next Set
+			_ = s.sweep()
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// Close closes the file of the store
+func (s *FileStore) Close() error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+
+	close(s.stop)
+
+	err := s.w.Flush()
+
+	if cerr := s.file.Close(); err == nil {
+		err = cerr
+	}
+
+	s.file = nil
+
+	return err
+}
+
+// CallRecords returns the records of the calls of the store, live and ended
+func (client *ClientSession) CallRecords() ([]CallRecord, error) {
+	if client.Relay.Blade == nil {
+		return nil, errors.New("empty blade session object")
+	}
+
+	return client.Relay.Blade.EventCalling.Cache.CallRecords()
+}
+
+// MsgRecords returns the parameters of the messages of the store
+func (client *ClientSession) MsgRecords() ([]MsgParams, error) {
+	if client.Relay.Blade == nil {
+		return nil, errors.New("empty blade session object")
+	}
+
+	return client.Relay.Blade.EventMessaging.Cache.MsgRecords()
+}
+
+// CallRecords returns the records of the calls of the store, live and ended
+func (consumer *Consumer) CallRecords() ([]CallRecord, error) {
+	if consumer.Client == nil {
+		return nil, errors.New("empty client session object")
+	}
+
+	return consumer.Client.CallRecords()
+}
+
+// MsgRecords returns the parameters of the messages of the store
+func (consumer *Consumer) MsgRecords() ([]MsgParams, error) {
+	if consumer.Client == nil {
+		return nil, errors.New("empty client session object")
+	}
+
+	return consumer.Client.MsgRecords()
+}
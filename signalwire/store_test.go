@@ -0,0 +1,206 @@
+package signalwire
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/assert"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	t.Run(
+		"MemoryStore",
+		func(t *testing.T) {
+			s := NewMemoryStore(time.Second)
+
+			assert.Nil(t, s.Set("msg:1", 1, 0))
+			assert.Nil(t, s.Set("msg:2", 2, 10*time.Millisecond))
+			assert.Nil(t, s.Set("call:1", 3, 0))
+			assert.ElementsMatch(t, []string{"msg:1", "msg:2"}, s.List("msg:"))
+
+			time.Sleep(20 * time.Millisecond)
+
+			_, found := s.Get("msg:2")
+			assert.False(t, found, "the entry must expire")
+
+			assert.Nil(t, s.Delete("msg:1"))
+
+			_, found = s.Get("msg:1")
+			assert.False(t, found)
+		},
+	)
+	t.Run(
+		"FileStore",
+		func(t *testing.T) {
+			path := filepath.Join(dir, "state.jsonl")
+
+			s, err := NewFileStore(path)
+			assert.Nil(t, err)
+
+			var cache BCache
+
+			cache.store = s
+			assert.Nil(t, cache.InitCache(time.Hour, time.Second))
+
+			call := &CallSession{TagID: "tag-1", CallID: "call-1", From: "+15551230001", CallState: Answered}
+			assert.Nil(t, cache.SetCallCache(call.TagID, call))
+			assert.Nil(t, cache.SetCallCache(call.CallID, call))
+
+			live, err := cache.GetCallCache("tag-1")
+			assert.Nil(t, err)
+			assert.Equal(t, call, live, "live calls are kept in memory")
+			assert.Equal(t, 0, fileLines(t, path), "live calls are not written")
+
+			records, err := cache.CallRecords()
+			assert.Nil(t, err)
+			assert.Len(t, records, 1, "one record per call")
+
+			call.CallState = Ended
+			assert.Nil(t, cache.RemoveCallCache(call))
+
+			msg := new(MsgSession)
+			msg.MsgParams = MsgParams{MsgID: "msg-1", Body: "Hello", MsgState: MsgDelivered}
+			assert.Nil(t, cache.SetMsgCache("msg-1", msg))
+			assert.Nil(t, cache.SetTasking("tasking", new(Tasking)))
+			assert.Nil(t, s.Set("msg:expired", MsgParams{MsgID: "expired"}, time.Millisecond))
+			assert.Nil(t, s.Close())
+
+			time.Sleep(5 * time.Millisecond)
+
+			// restart
+			s, err = NewFileStore(path)
+			assert.Nil(t, err)
+
+			defer s.Close()
+
+			cache = BCache{store: s}
+			assert.Nil(t, cache.InitCache(time.Hour, time.Second))
+
+			records, err = cache.CallRecords()
+			assert.Nil(t, err)
+			assert.Equal(t, []CallRecord{{
+				CallID:           "call-1",
+				TagID:            "tag-1",
+				Direction:        call.Direction.String(),
+				From:             "+15551230001",
+				State:            Ended.String(),
+				DisconnectReason: call.CallDisconnectReason.String(),
+			}}, records)
+
+			live, err = cache.GetCallCache("call-1")
+			assert.Nil(t, err)
+			assert.Nil(t, live, "a persisted call is not live")
+
+			msgs, err := cache.MsgRecords()
+			assert.Nil(t, err)
+			assert.Equal(t, []MsgParams{msg.MsgParams}, msgs, "the expired message must not be loaded")
+
+			m, err := cache.GetMsgCache("msg-1")
+			assert.Nil(t, err)
+			assert.Nil(t, m)
+
+			tasking, err := cache.GetTasking("tasking")
+			assert.Nil(t, err)
+			assert.Nil(t, tasking, "the tasking is kept in memory only")
+
+			v, found := s.Get("call:call-1")
+			assert.True(t, found)
+			assert.IsType(t, json.RawMessage{}, v)
+		},
+	)
+	t.Run(
+		"CallLookup",
+		func(t *testing.T) {
+			var cache BCache
+
+			assert.Nil(t, cache.InitCache(10*time.Millisecond, time.Millisecond))
+
+			call := &CallSession{CallID: "call-1"}
+			assert.Nil(t, cache.SetCallCache(call.CallID, call))
+			assert.Nil(t, cache.SetTasking("tasking", new(Tasking)))
+
+			time.Sleep(20 * time.Millisecond)
+
+			live, err := cache.GetCallCache("call-1")
+			assert.Nil(t, err)
+			assert.Equal(t, call, live, "a live call does not expire")
+
+			tasking, err := cache.GetTasking("tasking")
+			assert.Nil(t, err)
+			assert.NotNil(t, tasking, "the tasking does not expire")
+
+			assert.Nil(t, cache.store.Delete(StoreCallPrefix+"call-1"))
+
+			live, err = cache.GetCallCache("call-1")
+			assert.Nil(t, err)
+			assert.Equal(t, call, live, "the CallRegistry has the calls the store misses")
+
+			assert.Nil(t, cache.RemoveCallCache(call))
+
+			live, err = cache.GetCallCache("call-1")
+			assert.Nil(t, err)
+			assert.Nil(t, live, "an ended call is a record")
+
+			time.Sleep(20 * time.Millisecond)
+
+			records, err := cache.CallRecords()
+			assert.Nil(t, err)
+			assert.Empty(t, records, "the record expires")
+		},
+	)
+	t.Run(
+		"FileStoreCompaction",
+		func(t *testing.T) {
+			path := filepath.Join(dir, "compact.jsonl")
+
+			s, err := NewFileStore(path)
+			assert.Nil(t, err)
+
+			defer s.Close()
+
+			for i := 0; i < 3*FileStoreCompactLines; i++ {
+				assert.Nil(t, s.Set("msg:1", MsgParams{MsgID: "1"}, 0))
+			}
+
+			assert.True(t, fileLines(t, path) < FileStoreCompactLines, "the file must be compacted")
+
+			assert.Nil(t, s.Delete("tasking:memory-only"))
+			assert.True(t, fileLines(t, path) < FileStoreCompactLines)
+
+			for i := 0; i < FileStoreCompactLines; i++ {
+				assert.Nil(t, s.Set(fmt.Sprintf("msg:expiring-%d", i), MsgParams{}, time.Millisecond))
+			}
+
+			time.Sleep(5 * time.Millisecond)
+
+			assert.Nil(t, s.sweep())
+			assert.Equal(t, 1, fileLines(t, path), "the expired entries must be swept")
+
+			v, found := s.Get("msg:1")
+			assert.True(t, found)
+			assert.Equal(t, MsgParams{MsgID: "1"}, v)
+		},
+	)
+}
+
+func fileLines(t *testing.T, path string) int {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return bytes.Count(b, []byte("\n"))
+}
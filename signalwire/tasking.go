@@ -44,7 +44,7 @@ func (tasking *Tasking) Deliver(signalwireContext string, b []byte) bool {
 	}
 
 	if err := tasking.Relay.RelayTaskDeliver(tasking.Ctx, TaskingEndpoint, tasking.Consumer.Project, tasking.Consumer.Token, signalwireContext, b); err != nil {
-		Log.Error("RelayTaskDeliver: %v", err)
+		tasking.logger().Error("RelayTaskDeliver: %v", err)
 		return false
 	}
 
@@ -0,0 +1,86 @@
+package signalwire
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TransportConfig holds the settings used to open the Blade WebSocket,
+// on the first connection and on every reconnect.
+type TransportConfig struct {
+	// ProxyURL of an HTTP CONNECT ("http://", "https://") or SOCKS5
+	// ("socks5://") proxy. If nil, the proxy is taken from the environment
+	// (HTTPS_PROXY, NO_PROXY...).
+	ProxyURL *url.URL
+	// RootCAs used to verify the server certificate (private CA bundle).
+	// If nil, the host's root CA set is used.
+	RootCAs *x509.CertPool
+	// Certificates are the client certificates presented for mTLS
+	Certificates []tls.Certificate
+	// HandshakeTimeout for the WebSocket handshake. Zero means WSTimeOut, below
+	// DefaultConnectTimeout so that a hanging handshake fails the host in time.
+	HandshakeTimeout time.Duration
+	// Header holds custom HTTP headers sent with the WebSocket upgrade
+	Header http.Header
+	// TLSConfig if set is cloned and used as the base TLS configuration,
+	// RootCAs and Certificates above are applied on top of it.
+	TLSConfig *tls.Config
+	// Dialer if set is used as is and all the above is ignored, except Header.
+	Dialer *websocket.Dialer
+}
+
+// dialer builds the websocket.Dialer for this configuration.
+// A nil configuration gives the SDK default dialer.
+func (t *TransportConfig) dialer() *websocket.Dialer {
+	if t != nil && t.Dialer != nil {
+		return t.Dialer
+	}
+
+	d := &websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: WSTimeOut * time.Second,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: false,
+		},
+	}
+
+	if t == nil {
+		return d
+	}
+
+	if t.TLSConfig != nil {
+		d.TLSClientConfig = t.TLSConfig.Clone()
+	}
+
+	if t.RootCAs != nil {
+		d.TLSClientConfig.RootCAs = t.RootCAs
+	}
+
+	if len(t.Certificates) > 0 {
+		d.TLSClientConfig.Certificates = t.Certificates
+	}
+
+	if t.ProxyURL != nil {
+		d.Proxy = http.ProxyURL(t.ProxyURL)
+	}
+
+	if t.HandshakeTimeout > 0 {
+		d.HandshakeTimeout = t.HandshakeTimeout
+	}
+
+	return d
+}
+
+// header returns the custom headers for the WebSocket upgrade request
+func (t *TransportConfig) header() http.Header {
+	if t == nil {
+		return nil
+	}
+
+	return t.Header
+}
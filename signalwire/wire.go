@@ -0,0 +1,299 @@
+package signalwire
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	jsonrpc2 "github.com/sourcegraph/jsonrpc2"
+	ws "github.com/sourcegraph/jsonrpc2/websocket"
+)
+
+// WireDirection tells if a frame was received or sent
+type WireDirection string
+
+// Wire directions
+const (
+	WireIn  WireDirection = "in"
+	WireOut WireDirection = "out"
+)
+
+// wireMaxFrame is the max size of a line of a capture
+const wireMaxFrame = 16 * 1024 * 1024
+
+// wireRedacted replaces the values of the credentials in a capture
+const wireRedacted = "[REDACTED]"
+
+// wireSecrets are the keys whose values are redacted
+var wireSecrets = map[string]bool{
+	"token":     true,
+	"jwt_token": true,
+	"signature": true,
+	"password":  true,
+}
+
+// WireFrame is one line of a capture
+type WireFrame struct {
+	Time      time.Time       `json:"time"`
+	Direction WireDirection   `json:"direction"`
+	Frame     json.RawMessage `json:"frame"`
+}
+
+// WireRecorder writes every JSON-RPC frame of the Blade link to a JSONL
+// capture, with the credentials redacted.
+type WireRecorder struct {
+	sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	err    error
+}
+
+// NewWireRecorder returns a recorder writing to w
+func NewWireRecorder(w io.Writer) *WireRecorder {
+	return &WireRecorder{w: w}
+}
+
+// CreateWireRecorder returns a recorder writing to a new file
+func CreateWireRecorder(path string) (*WireRecorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &WireRecorder{w: f, closer: f}, nil
+}
+
+// Err returns the first error writing the capture
+func (r *WireRecorder) Err() error {
+	r.Lock()
+	defer r.Unlock()
+
+	return r.err
+}
+
+// Close closes the file of the capture, if the recorder created it
+func (r *WireRecorder) Close() error {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.closer == nil {
+		return nil
+	}
+
+	err := r.closer.Close()
+	r.closer = nil
+
+	return err
+}
+
+func (r *WireRecorder) record(dir WireDirection, data []byte) {
+	if r == nil {
+		return
+	}
+
+	line, err := json.Marshal(WireFrame{
+		Time:      time.Now(),
+		Direction: dir,
+		Frame:     redactFrame(data),
+	})
+
+	r.Lock()
+	defer r.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	if err == nil {
+		_, err = r.w.Write(append(line, '\n'))
+	}
+
+	r.err = err
+}
+
+// redactFrame returns the frame without the values of the credentials
+func redactFrame(data []byte) json.RawMessage {
+	var v interface{}
+
+	if err := json.Unmarshal(data, &v); err != nil {
+		b, _ := json.Marshal(string(data))
+
+		return b
+	}
+
+	b, err := json.Marshal(redact(v))
+	if err != nil {
+		return json.RawMessage("null")
+	}
+
+	return b
+}
+
+func redact(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if s, ok := val.(string); ok && wireSecrets[k] && len(s) > 0 {
+				t[k] = wireRedacted
+			} else {
+				t[k] = redact(val)
+			}
+		}
+	case []interface{}:
+		for i := range t {
+			t[i] = redact(t[i])
+		}
+	}
+
+	return v
+}
+
+// wireStream is a jsonrpc2.ObjectStream over a WebSocket that records the frames
+type wireStream struct {
+	conn *websocket.Conn
+	rec  *WireRecorder
+}
+
+// objectStream returns the stream of the Blade link, recorded if there is a recorder
+func (blade *BladeSession) objectStream(c *websocket.Conn) jsonrpc2.ObjectStream {
+	if blade.WireRecorder == nil {
+		return ws.NewObjectStream(c)
+	}
+
+	return wireStream{conn: c, rec: blade.WireRecorder}
+}
+
+// WriteObject implements jsonrpc2.ObjectStream
+func (s wireStream) WriteObject(obj interface{}) error {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	if err := s.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return err
+	}
+
+	s.rec.record(WireOut, data)
+
+	return nil
+}
+
+// ReadObject implements jsonrpc2.ObjectStream
+func (s wireStream) ReadObject(v interface{}) error {
+	_, data, err := s.conn.ReadMessage()
+	if err != nil {
+		if e, ok := err.(*websocket.CloseError); ok &&
+			e.Code == websocket.CloseAbnormalClosure && e.Text == io.ErrUnexpectedEOF.Error() {
+			return io.ErrUnexpectedEOF
+		}
+
+		return err
+	}
+
+	s.rec.record(WireIn, data)
+
+	return json.Unmarshal(data, v)
+}
+
+// Close implements jsonrpc2.ObjectStream
+func (s wireStream) Close() error {
+	return s.conn.Close()
+}
+
+// WireReplayer feeds the requests received in a capture to a Blade session,
+// through the same handlers as the live link, one at a time and in order.
+type WireReplayer struct {
+	// Blade gets the events, NewWireReplayer sets up one not connected to Relay
+	Blade *BladeSession
+	// Speed 0 replays as fast as possible, 1 at the pace of the capture
+	Speed float64
+}
+
+// NewWireReplayer returns a replayer with a new Blade session
+func NewWireReplayer() (*WireReplayer, error) {
+	var I IBlade = BladeNew()
+
+	blade := &BladeSession{I: I}
+	blade.I = blade
+
+	if err := blade.initEvents(); err != nil {
+		return nil, err
+	}
+
+	blade.Netcast = make(chan string, 1)
+	blade.setState(BladeRunning)
+
+	return &WireReplayer{Blade: blade}, nil
+}
+
+// ReplayFile replays the capture in the file at path
+func (r *WireReplayer) ReplayFile(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	return r.Replay(ctx, f)
+}
+
+// Replay replays the capture read from rd, until its end or until ctx is done
+func (r *WireReplayer) Replay(ctx context.Context, rd io.Reader) error {
+	if r.Blade == nil {
+		return errors.New("empty blade session object")
+	}
+
+	scanner := bufio.NewScanner(rd)
+	scanner.Buffer(make([]byte, 64*1024), wireMaxFrame)
+
+	var last time.Time
+
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var frame WireFrame
+
+		if err := json.Unmarshal(scanner.Bytes(), &frame); err != nil {
+			return err
+		}
+
+		if frame.Direction != WireIn {
+			continue
+		}
+
+		var req jsonrpc2.Request
+
+		if err := json.Unmarshal(frame.Frame, &req); err != nil || len(req.Method) == 0 {
+			// a reply to a command, nothing to feed
+			continue
+		}
+
+		if r.Speed > 0 && !last.IsZero() && frame.Time.After(last) {
+			select {
+			case <-time.After(time.Duration(float64(frame.Time.Sub(last)) / r.Speed)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		last = frame.Time
+
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		r.Blade.handleRequest(ctx, &req)
+	}
+
+	return scanner.Err()
+}